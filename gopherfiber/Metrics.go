@@ -0,0 +1,169 @@
+package gopherfiber
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/gofiber/fiber/v2/utils"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// DefaultMetricsPath is the path the metrics are exposed on when ServerConfig.MetricsPath is empty.
+const DefaultMetricsPath = "/metrics"
+
+// unmatchedRoute is the route label of requests that matched no route, so scanners probing random
+// paths cannot blow up the number of series.
+const unmatchedRoute = "unmatched"
+
+// methodUse is the method Fiber gives the routes of middleware registered with Use.
+const methodUse = "USE"
+
+// Metrics records Prometheus metrics about the requests an app serves:
+//
+//   - http_requests_total: Requests by method, route and status.
+//   - http_request_duration_seconds: Request latency by method, route and status.
+//   - http_requests_in_flight: Requests currently being served.
+//
+// Routes are labelled with their pattern, e.g. "/users/:id", rather than the requested path.
+type Metrics struct {
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+	inFlight prometheus.Gauge
+	gatherer prometheus.Gatherer
+}
+
+// NewMetrics creates the request metrics and registers them with registry.
+//
+// Every server should get its own registry so that several servers in one process do not collide.
+// When the metrics are already registered with registry, the registered ones are reused, so servers
+// sharing a registry report into the same series.
+//
+// Parameters:
+// - registry: The registry to register with and expose; nil creates a new one.
+//
+// Returns:
+// - *Metrics: The metrics, with the middleware and the handler exposing them.
+// - error: An error if a different metric with the same name is already registered.
+//
+// Example usage:
+//
+//	metrics, err := gopherfiber.NewMetrics(prometheus.NewRegistry())
+//	if err != nil {
+//	    log.Fatalf("Failed to set up metrics: %v", err)
+//	}
+//	app.Use(metrics.Middleware())
+//	app.Get("/metrics", metrics.Handler())
+func NewMetrics(registry *prometheus.Registry) (*Metrics, error) {
+	if registry == nil {
+		registry = prometheus.NewRegistry()
+	}
+
+	requests := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "Total number of HTTP requests by method, route and status.",
+	}, []string{"method", "route", "status"})
+	duration := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "Latency of HTTP requests in seconds by method, route and status.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+	inFlight := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "http_requests_in_flight",
+		Help: "Number of HTTP requests currently being served.",
+	})
+
+	var err error
+	if requests, err = register(registry, requests); err != nil {
+		return nil, err
+	}
+	if duration, err = register(registry, duration); err != nil {
+		return nil, err
+	}
+	if inFlight, err = register(registry, inFlight); err != nil {
+		return nil, err
+	}
+
+	return &Metrics{
+		requests: requests,
+		duration: duration,
+		inFlight: inFlight,
+		gatherer: registry,
+	}, nil
+}
+
+// register registers collector, returning the collector already registered in its place if there is one.
+func register[C prometheus.Collector](registry *prometheus.Registry, collector C) (C, error) {
+	if err := registry.Register(collector); err != nil {
+		var alreadyRegistered prometheus.AlreadyRegisteredError
+		if errors.As(err, &alreadyRegistered) {
+			if existing, ok := alreadyRegistered.ExistingCollector.(C); ok {
+				return existing, nil
+			}
+		}
+		return collector, fmt.Errorf("failed to register metrics: %w", err)
+	}
+	return collector, nil
+}
+
+// Middleware returns a middleware recording every request that passes through it. Register it
+// with app.Use before other middleware so that rejected requests are counted too.
+//
+// Returns:
+// - fiber.Handler: The metrics middleware.
+func (m *Metrics) Middleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		m.inFlight.Inc()
+		defer m.inFlight.Dec()
+
+		err := c.Next()
+
+		// The method is copied, as Fiber reuses the buffer it points into for the next request
+		method := utils.CopyString(c.Method())
+		route := c.Route().Path
+		if unmatched(c.Route(), method, err) {
+			route = unmatchedRoute
+		}
+
+		// Errors are turned into responses by the error handler only after the middleware returns
+		status := c.Response().StatusCode()
+		if err != nil {
+			status = fiber.StatusInternalServerError
+			var fiberErr *fiber.Error
+			if errors.As(err, &fiberErr) {
+				status = fiberErr.Code
+			}
+		}
+
+		labels := []string{method, route, strconv.Itoa(status)}
+		m.requests.WithLabelValues(labels...).Inc()
+		m.duration.WithLabelValues(labels...).Observe(time.Since(start).Seconds())
+		return err
+	}
+}
+
+// unmatched reports whether no handler matched the request: the last route that ran is a middleware
+// registered with Use, such as this one or CORS, or the request fell through the routes, e.g. past a
+// Static route that found no file, into the router's own 404.
+func unmatched(route *fiber.Route, method string, err error) bool {
+	if route.Method == methodUse {
+		return true
+	}
+	var fiberErr *fiber.Error
+	return errors.As(err, &fiberErr) && fiberErr.Code == fiber.StatusNotFound &&
+		strings.HasPrefix(fiberErr.Message, "Cannot "+method+" ")
+}
+
+// Handler returns a handler exposing the metrics of the registry in the Prometheus text format.
+//
+// Returns:
+// - fiber.Handler: The handler, e.g. for GET /metrics.
+func (m *Metrics) Handler() fiber.Handler {
+	return adaptor.HTTPHandler(promhttp.HandlerFor(m.gatherer, promhttp.HandlerOpts{}))
+}
@@ -0,0 +1,72 @@
+package gopherfiber
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/cors"
+)
+
+// scrape returns the metrics exposed by app.
+func scrape(t *testing.T, app *fiber.App) string {
+	t.Helper()
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, DefaultMetricsPath, nil))
+	if err != nil {
+		t.Fatalf("scrape failed: %v", err)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("reading scrape failed: %v", err)
+	}
+	return string(body)
+}
+
+func TestMetricsLabelsMixedMethods(t *testing.T) {
+	app := (&ServerSetupImpl{}).SetUpRouter(ServerConfig{
+		EnableMetrics: true,
+		StaticPath:    t.TempDir(),
+	})
+	(&ServerSetupImpl{}).SetUpCORS(app, ServerConfig{UseCORS: true, CORSConfig: cors.Config{AllowOrigins: "*"}})
+	app.All("/items/:id", func(c *fiber.Ctx) error { return c.SendString("ok") })
+
+	requests := []struct {
+		method string
+		count  int
+	}{
+		{fiber.MethodGet, 1},
+		{fiber.MethodPost, 50},
+		{fiber.MethodDelete, 50},
+	}
+	for _, r := range requests {
+		for i := 0; i < r.count; i++ {
+			if _, err := app.Test(httptest.NewRequest(r.method, "/items/42", nil)); err != nil {
+				t.Fatalf("%s request failed: %v", r.method, err)
+			}
+		}
+	}
+	if _, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/no/such/path", nil)); err != nil {
+		t.Fatalf("unmatched request failed: %v", err)
+	}
+	if _, err := app.Test(httptest.NewRequest(fiber.MethodPut, "/no/such/path", nil)); err != nil {
+		t.Fatalf("unmatched request failed: %v", err)
+	}
+
+	metrics := scrape(t, app)
+	for _, want := range []string{
+		`http_requests_total{method="GET",route="/items/:id",status="200"} 1`,
+		`http_requests_total{method="POST",route="/items/:id",status="200"} 50`,
+		`http_requests_total{method="DELETE",route="/items/:id",status="200"} 50`,
+		`http_requests_total{method="GET",route="unmatched",status="404"} 1`,
+		`http_requests_total{method="PUT",route="unmatched",status="404"} 1`,
+	} {
+		if !strings.Contains(metrics, want) {
+			t.Errorf("metrics missing %s\n%s", want, metrics)
+		}
+	}
+	if strings.Contains(metrics, `method="GETE`) {
+		t.Errorf("method label corrupted by a reused buffer:\n%s", metrics)
+	}
+}
@@ -1,14 +1,19 @@
 package gopherfiber
 
 import (
+	"context"
 	"crypto/tls"
 	"fmt"
 	"log"
 	"os"
 	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
+	"github.com/gofiber/template/html/v2"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 // ServerConfig holds the configuration options for the server.
@@ -19,32 +24,60 @@ import (
 // - TLSCertFile: Path to the TLS certificate file (required if UseTLS is true).
 // - TLSKeyFile: Path to the TLS key file (required if UseTLS is true).
 // - UseCORS: Set to true to enable Cross-Origin Resource Sharing (CORS).
-// - CORSConfig: CORS configuration to allow specific origins and methods.
+// - CORSConfig: CORS configuration (origins, methods, headers, credentials, exposed headers and max age).
+// - StaticPath: Directory of static files to serve; empty disables static file serving.
+// - StaticPrefix: URL prefix the static files are served under (defaults to "/").
+// - TemplatePath: Directory of HTML templates (*.html) available to c.Render; empty disables templates.
+// - EnableMetrics: Record Prometheus request metrics and expose them on MetricsPath if true.
+// - MetricsPath: Path of the metrics endpoint; empty uses DefaultMetricsPath.
+// - MetricsRegistry: Registry the metrics are registered with and exposed from; nil creates one per server.
+// - Prefork: Spawn one child process per CPU core, all listening on the same port with SO_REUSEPORT, if true.
+// Each child runs main() again and handles its own connections; see GracefulShutdown for stopping them.
+// - BodyLimit: Maximum request body size in bytes, e.g. raised for file uploads; larger requests get 413.
+// - Concurrency: Maximum number of concurrent connections.
+// - ReadTimeout, WriteTimeout: Timeouts for reading a whole request and writing its response.
+// Zero uses Fiber's defaults: a 4 MB body limit, 256 * 1024 connections and no timeouts.
 type ServerConfig struct {
-	Port        int
-	UseTLS      bool
-	TLSCertFile string
-	TLSKeyFile  string
-	UseCORS     bool
-	CORSConfig  cors.Config
+	Port         int
+	UseTLS       bool
+	TLSCertFile  string
+	TLSKeyFile   string
+	UseCORS      bool
+	CORSConfig   cors.Config
+	StaticPath   string
+	StaticPrefix string
+	TemplatePath string
+
+	EnableMetrics   bool
+	MetricsPath     string
+	MetricsRegistry *prometheus.Registry
+
+	Prefork      bool
+	BodyLimit    int
+	Concurrency  int
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
 }
 
+// DefaultShutdownTimeout is the drain window GracefulShutdown uses when it is given a zero timeout.
+const DefaultShutdownTimeout = 5 * time.Second
+
 // Server interface defines the behavior of a Fiber server.
 //
 // Methods:
 // - Start: Starts the server (optionally with TLS).
-// - GracefulShutdown: Gracefully shuts down the server on interrupt.
+// - GracefulShutdown: Gracefully shuts down the server, draining open connections up to a timeout.
 // - GetRouter: Returns the underlying fiber.App instance for adding routes.
 type Server interface {
 	Start() error
-	GracefulShutdown()
+	GracefulShutdown(ctx context.Context, timeout time.Duration) error
 	GetRouter() *fiber.App
 }
 
 // ServerSetup interface defines the setup methods for configuring a Fiber server.
 //
 // Methods:
-// - SetUpRouter: Sets up the Fiber app without static files or templates.
+// - SetUpRouter: Sets up the Fiber app, with static files and templates when configured.
 // - SetUpTLS: Configures TLS settings for HTTPS if enabled.
 // - SetUpCORS: Applies CORS middleware to the app if enabled.
 type ServerSetup interface {
@@ -56,7 +89,11 @@ type ServerSetup interface {
 // ServerSetupImpl is the concrete implementation of the ServerSetup interface.
 type ServerSetupImpl struct{}
 
-// SetUpRouter sets up a Fiber app without static file serving or template rendering.
+// SetUpRouter sets up a Fiber app, serving static files and rendering HTML templates when configured.
+// Prefork, BodyLimit, Concurrency and the timeouts from config are passed on to fiber.Config.
+//
+// The metrics middleware is applied first when enabled, so every request is counted. Metrics are
+// left out, with a log message, if they cannot be registered with config.MetricsRegistry.
 //
 // Parameters:
 // - config: The ServerConfig structure.
@@ -64,8 +101,45 @@ type ServerSetupImpl struct{}
 // Returns:
 // - *fiber.App: The Fiber app instance.
 func (s *ServerSetupImpl) SetUpRouter(config ServerConfig) *fiber.App {
+	fiberConfig := fiber.Config{
+		Prefork:      config.Prefork,
+		BodyLimit:    config.BodyLimit,
+		Concurrency:  config.Concurrency,
+		ReadTimeout:  config.ReadTimeout,
+		WriteTimeout: config.WriteTimeout,
+	}
+
+	// Load HTML templates so handlers can call c.Render("index", data)
+	if config.TemplatePath != "" {
+		fiberConfig.Views = html.New(config.TemplatePath, ".html")
+	}
+
 	// Create a new Fiber app
-	app := fiber.New()
+	app := fiber.New(fiberConfig)
+
+	// Record request metrics and expose them
+	if config.EnableMetrics {
+		metrics, err := NewMetrics(config.MetricsRegistry)
+		if err != nil {
+			log.Printf("Metrics disabled: %v", err)
+		} else {
+			path := config.MetricsPath
+			if path == "" {
+				path = DefaultMetricsPath
+			}
+			app.Use(metrics.Middleware())
+			app.Get(path, metrics.Handler())
+		}
+	}
+
+	// Serve static files
+	if config.StaticPath != "" {
+		prefix := config.StaticPrefix
+		if prefix == "" {
+			prefix = "/"
+		}
+		app.Static(prefix, config.StaticPath)
+	}
 
 	return app
 }
@@ -104,11 +178,9 @@ func (s *ServerSetupImpl) SetUpTLS(config ServerConfig) (*tls.Config, error) {
 // - config: The ServerConfig containing CORS configuration options.
 func (s *ServerSetupImpl) SetUpCORS(app *fiber.App, config ServerConfig) {
 	if config.UseCORS {
-		// Apply CORS middleware with the provided configuration
-		app.Use(cors.New(cors.Config{
-			AllowOrigins: config.CORSConfig.AllowOrigins,
-			AllowMethods: config.CORSConfig.AllowMethods,
-		}))
+		// Apply CORS middleware with the provided configuration, passed through whole so that
+		// AllowHeaders, AllowCredentials, ExposeHeaders and MaxAge are honoured as well
+		app.Use(cors.New(config.CORSConfig))
 		log.Printf("CORS configured with settings: %+v", config.CORSConfig)
 	}
 }
@@ -135,8 +207,9 @@ type FiberServer struct {
 //
 // Returns:
 // - Server: A configured Fiber server instance ready to start.
-func NewFiberServer(setup ServerSetup, config ServerConfig) Server {
-	// Initialize the Fiber app without static file serving or templates
+// - error: An error if TLS cannot be set up, e.g. when the certificate files cannot be loaded.
+func NewFiberServer(setup ServerSetup, config ServerConfig) (Server, error) {
+	// Initialize the Fiber app with static file serving and templates if configured
 	app := setup.SetUpRouter(config)
 	// Configure CORS if enabled
 	setup.SetUpCORS(app, config)
@@ -144,7 +217,7 @@ func NewFiberServer(setup ServerSetup, config ServerConfig) Server {
 	// Set up TLS if enabled
 	tlsConfig, err := setup.SetUpTLS(config)
 	if err != nil {
-		log.Fatalf("Error setting up TLS: %v", err)
+		return nil, fmt.Errorf("error setting up TLS: %w", err)
 	}
 
 	return &FiberServer{
@@ -152,7 +225,7 @@ func NewFiberServer(setup ServerSetup, config ServerConfig) Server {
 		tlsConfig:   tlsConfig,
 		serverSetup: setup,
 		config:      config,
-	}
+	}, nil
 }
 
 // Start starts the Fiber server with or without TLS, depending on the configuration.
@@ -190,23 +263,62 @@ func (fs *FiberServer) GetRouter() *fiber.App {
 	return fs.app
 }
 
-// GracefulShutdown shuts down the server gracefully on receiving an interrupt signal.
+// GracefulShutdown shuts down the server gracefully.
 //
-// This method ensures ongoing requests are completed before shutting down.
-func (fs *FiberServer) GracefulShutdown() {
-	// Create a channel to listen for OS interrupt signals
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, os.Interrupt)
-	<-quit
+// The server stops accepting new connections and gives ongoing requests until the drain timeout
+// elapses or ctx is cancelled, whichever comes first, before the shutdown gives up on them.
+// Signal handling is left to the caller; see WaitForSignal for the common case.
+//
+// With Prefork, every child process runs its own server and must shut it down itself. Deliver the
+// signal to the whole process group, as Ctrl+C and most container runtimes do, so each child drains
+// its connections; a child whose parent exits without it is stopped abruptly. fiber.IsChild tells
+// the processes apart, e.g. to run one-off startup work in the parent only.
+//
+// Parameters:
+// - ctx: Context that, when cancelled, ends the drain window early.
+// - timeout: The drain window for ongoing requests; zero or less uses DefaultShutdownTimeout.
+//
+// Returns:
+// - error: An error if the server could not shut down cleanly, e.g. ctx.Err() when the drain window ran out.
+func (fs *FiberServer) GracefulShutdown(ctx context.Context, timeout time.Duration) error {
+	if timeout <= 0 {
+		timeout = DefaultShutdownTimeout
+	}
 
 	log.Println("Shutting down server...")
 
+	ctxShutDown, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
 	// Shutdown the server gracefully
-	if err := fs.app.Shutdown(); err != nil {
+	if err := fs.app.ShutdownWithContext(ctxShutDown); err != nil {
 		log.Printf("Server forced to shutdown: %v", err)
+		return fmt.Errorf("failed to shut down server gracefully: %w", err)
 	}
 
 	log.Println("Server shutdown successfully")
+	return nil
+}
+
+// WaitForSignal blocks until the process receives one of the given signals, or os.Interrupt and
+// SIGTERM when none are given, and returns the signal received.
+//
+// Example usage:
+//
+//	server.Start()
+//	gopherfiber.WaitForSignal()
+//	server.GracefulShutdown(context.Background(), 10*time.Second)
+func WaitForSignal(signals ...os.Signal) os.Signal {
+	if len(signals) == 0 {
+		signals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+
+	// Create a channel to listen for OS signals
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, signals...)
+	defer signal.Stop(quit)
+
+	return <-quit
 }
 
 // Example usage:
@@ -224,14 +336,20 @@ func (fs *FiberServer) GracefulShutdown() {
 //	        },
 //	    }
 //
-//	    server := gopherfiber.NewFiberServer(&gopherfiber.ServerSetupImpl{}, config)
+//	    server, err := gopherfiber.NewFiberServer(&gopherfiber.ServerSetupImpl{}, config)
+//	    if err != nil {
+//	        log.Fatalf("Failed to set up server: %v", err)
+//	    }
 //
 //	    // Start the server
-//	    err := server.Start()
+//	    err = server.Start()
 //	    if err != nil {
 //	        log.Fatalf("Failed to start server: %v", err)
 //	    }
 //
 //	    // Gracefully shut down the server on interrupt
-//	    server.GracefulShutdown()
+//	    gopherfiber.WaitForSignal()
+//	    if err := server.GracefulShutdown(context.Background(), 5*time.Second); err != nil {
+//	        log.Printf("Shutdown error: %v", err)
+//	    }
 //	}
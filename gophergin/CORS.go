@@ -0,0 +1,67 @@
+package gophergin
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gin-contrib/cors"
+)
+
+// DefaultCORSMaxAge is how long browsers may cache a preflight response under DefaultCORSConfig.
+const DefaultCORSMaxAge = 12 * time.Hour
+
+// ErrCORSCredentialsWithWildcard is returned by SetUpCORS when credentials are allowed for every
+// origin, which browsers reject: a credentialed response must name the origin it is meant for.
+var ErrCORSCredentialsWithWildcard = errors.New("CORS AllowCredentials cannot be combined with a wildcard origin")
+
+// DefaultCORSConfig returns a CORS configuration for the given origins that allows the common methods
+// and headers, including Authorization, and lets browsers cache preflight responses for
+// DefaultCORSMaxAge. Without origins every origin is allowed.
+//
+// Credentials (cookies, HTTP authentication) are not allowed by default. Set AllowCredentials on the
+// result to pass them through, which requires naming the origins.
+//
+// Params:
+// - origins: The allowed origins, e.g. "https://app.example.com".
+//
+// Returns:
+// - cors.Config: The configuration, ready for ServerConfig.CORSConfig.
+//
+// Example usage:
+//
+//	corsConfig := gophergin.DefaultCORSConfig("https://app.example.com")
+//	corsConfig.AllowCredentials = true
+//	corsConfig.MaxAge = time.Hour
+//
+//	config := gophergin.ServerConfig{Port: 8080, UseCORS: true, CORSConfig: corsConfig}
+func DefaultCORSConfig(origins ...string) cors.Config {
+	return cors.Config{
+		AllowAllOrigins: len(origins) == 0,
+		AllowOrigins:    origins,
+		AllowMethods:    []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS"},
+		AllowHeaders:    []string{"Origin", "Content-Length", "Content-Type", "Authorization", RequestIDHeader},
+		ExposeHeaders:   []string{RequestIDHeader},
+		MaxAge:          DefaultCORSMaxAge,
+	}
+}
+
+// validateCORSConfig reports the settings cors.New would panic on, and credentials allowed for a
+// wildcard origin, which cors.New accepts but browsers refuse.
+func validateCORSConfig(config cors.Config) error {
+	if err := config.Validate(); err != nil {
+		return fmt.Errorf("invalid CORS configuration: %w", err)
+	}
+	if !config.AllowCredentials {
+		return nil
+	}
+	if config.AllowAllOrigins {
+		return ErrCORSCredentialsWithWildcard
+	}
+	for _, origin := range config.AllowOrigins {
+		if origin == "*" {
+			return ErrCORSCredentialsWithWildcard
+		}
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package gophergin
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+)
+
+func TestValidateCORSConfigCredentials(t *testing.T) {
+	// credentialed returns DefaultCORSConfig for origins with credentials allowed
+	credentialed := func(origins ...string) cors.Config {
+		config := DefaultCORSConfig(origins...)
+		config.AllowCredentials = true
+		return config
+	}
+
+	tests := []struct {
+		name    string
+		config  cors.Config
+		wantErr error
+	}{
+		{"named origins", credentialed("https://app.example.com", "https://admin.example.com"), nil},
+		{"all origins without credentials", DefaultCORSConfig(), nil},
+		{"all origins", credentialed(), ErrCORSCredentialsWithWildcard},
+		{"wildcard among origins", credentialed("https://app.example.com", "*"), ErrCORSCredentialsWithWildcard},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateCORSConfig(tt.config); !errors.Is(err, tt.wantErr) {
+				t.Errorf("validateCORSConfig returned %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if err := validateCORSConfig(cors.Config{AllowMethods: []string{"GET"}}); err == nil {
+		t.Error("validateCORSConfig accepted a configuration without origins")
+	}
+}
+
+func TestSetUpCORSAllowsCredentialsForNamedOrigin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	setup := &ServerSetupImpl{}
+
+	wildcard := DefaultCORSConfig()
+	wildcard.AllowCredentials = true
+	err := setup.SetUpCORS(gin.New(), ServerConfig{UseCORS: true, CORSConfig: wildcard})
+	if !errors.Is(err, ErrCORSCredentialsWithWildcard) {
+		t.Fatalf("SetUpCORS with credentials for every origin returned %v, want %v", err, ErrCORSCredentialsWithWildcard)
+	}
+
+	config := DefaultCORSConfig("https://app.example.com")
+	config.AllowCredentials = true
+	router := gin.New()
+	if err := setup.SetUpCORS(router, ServerConfig{UseCORS: true, CORSConfig: config}); err != nil {
+		t.Fatalf("SetUpCORS failed: %v", err)
+	}
+	router.GET("/profile", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	req := httptest.NewRequest(http.MethodOptions, "/profile", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	if got := recorder.Header().Get("Access-Control-Allow-Origin"); got != "https://app.example.com" {
+		t.Errorf("Access-Control-Allow-Origin is %q, want the requesting origin", got)
+	}
+	if got := recorder.Header().Get("Access-Control-Allow-Credentials"); got != "true" {
+		t.Errorf("Access-Control-Allow-Credentials is %q, want \"true\"", got)
+	}
+}
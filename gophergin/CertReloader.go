@@ -0,0 +1,88 @@
+package gophergin
+
+import (
+	"crypto/tls"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// CertReloader serves a TLS certificate from disk and reloads it when the files change, so renewed
+// certificates (e.g. from Let's Encrypt) are picked up without restarting the server.
+type CertReloader struct {
+	certFile string
+	keyFile  string
+
+	mu      sync.RWMutex
+	cert    *tls.Certificate
+	certMod time.Time
+	keyMod  time.Time
+}
+
+// NewCertReloader loads the key pair and returns a CertReloader serving it.
+//
+// Parameters:
+// - certFile: Path to the TLS certificate file.
+// - keyFile: Path to the TLS key file.
+//
+// Returns:
+// - *CertReloader: The reloader, whose GetCertificate can be set on a tls.Config.
+// - error: An error if the key pair cannot be loaded.
+func NewCertReloader(certFile, keyFile string) (*CertReloader, error) {
+	reloader := &CertReloader{certFile: certFile, keyFile: keyFile}
+	if err := reloader.Reload(); err != nil {
+		return nil, err
+	}
+	return reloader, nil
+}
+
+// Reload loads the key pair from disk and serves it for every following handshake.
+//
+// Returns:
+// - error: An error if the key pair cannot be loaded; the previous certificate keeps being served.
+func (r *CertReloader) Reload() error {
+	certMod, keyMod := modTime(r.certFile), modTime(r.keyFile)
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.cert = &cert
+	r.certMod = certMod
+	r.keyMod = keyMod
+	return nil
+}
+
+// GetCertificate returns the current certificate, first reloading it if either file has been
+// modified since it was loaded. It is meant for tls.Config.GetCertificate.
+func (r *CertReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	changed := !modTime(r.certFile).Equal(r.certMod) || !modTime(r.keyFile).Equal(r.keyMod)
+	r.mu.RUnlock()
+
+	if changed {
+		// A renewal may be half written; keep serving the old certificate until both files match
+		if err := r.Reload(); err != nil {
+			log.Printf("Keeping current TLS certificate: %v", err)
+		}
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, nil
+}
+
+// modTime returns the modification time of the file, or the zero time if it cannot be read.
+func modTime(path string) time.Time {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
@@ -0,0 +1,73 @@
+package gophergin
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// HTTPSRedirectHandler returns a handler that permanently redirects every request to the same host,
+// path, and query over HTTPS.
+//
+// Parameters:
+// - httpsPort: The port the HTTPS server listens on; 443 (or 0) leaves the port out of the URL.
+//
+// Returns:
+// - http.Handler: The redirect handler.
+func HTTPSRedirectHandler(httpsPort int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+
+		if httpsPort != 0 && httpsPort != 443 {
+			host = net.JoinHostPort(host, strconv.Itoa(httpsPort))
+		} else if strings.Contains(host, ":") {
+			host = "[" + host + "]"
+		}
+
+		target := url.URL{
+			Scheme:   "https",
+			Host:     host,
+			Path:     r.URL.Path,
+			RawPath:  r.URL.RawPath,
+			RawQuery: r.URL.RawQuery,
+		}
+		http.Redirect(w, r, target.String(), http.StatusMovedPermanently)
+	})
+}
+
+// newRedirectServer returns the plain HTTP server that redirects to HTTPS, or nil if it is not configured.
+func newRedirectServer(config ServerConfig) *http.Server {
+	if !config.UseTLS || config.RedirectHTTPPort <= 0 {
+		return nil
+	}
+
+	return &http.Server{
+		Addr:              net.JoinHostPort(config.Host, strconv.Itoa(config.RedirectHTTPPort)),
+		Handler:           HTTPSRedirectHandler(config.Port),
+		ReadHeaderTimeout: DefaultReadHeaderTimeout,
+	}
+}
+
+// startRedirect binds the redirect server's port and serves it in the background.
+func (gs *GinServer) startRedirect() error {
+	listener, err := net.Listen("tcp", gs.redirectServer.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s for HTTPS redirects: %w", gs.redirectServer.Addr, err)
+	}
+
+	log.Printf("Redirecting HTTP on %s to HTTPS", listener.Addr())
+	go func() {
+		if err := gs.redirectServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("HTTPS redirect server error: %v", err)
+		}
+	}()
+	return nil
+}
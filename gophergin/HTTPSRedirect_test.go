@@ -0,0 +1,39 @@
+package gophergin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHTTPSRedirectHandlerPreservesPathAndQuery(t *testing.T) {
+	tests := []struct {
+		name      string
+		httpsPort int
+		host      string
+		target    string
+		want      string
+	}{
+		{"default port", 443, "example.com", "/orders/42?page=2&sort=desc", "https://example.com/orders/42?page=2&sort=desc"},
+		{"unset port", 0, "example.com:8080", "/orders/42?page=2", "https://example.com/orders/42?page=2"},
+		{"custom port", 8443, "example.com:8080", "/orders/42?page=2", "https://example.com:8443/orders/42?page=2"},
+		{"escaped path", 443, "example.com", "/files/a%2Fb?q=x%20y", "https://example.com/files/a%2Fb?q=x%20y"},
+		{"IPv6 host", 443, "[::1]:8080", "/health", "https://[::1]/health"},
+		{"IPv6 host on custom port", 8443, "[::1]:8080", "/health", "https://[::1]:8443/health"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			req.Host = tt.host
+			recorder := httptest.NewRecorder()
+			HTTPSRedirectHandler(tt.httpsPort).ServeHTTP(recorder, req)
+
+			if recorder.Code != http.StatusMovedPermanently {
+				t.Fatalf("redirect returned %d, want %d", recorder.Code, http.StatusMovedPermanently)
+			}
+			if got := recorder.Header().Get("Location"); got != tt.want {
+				t.Errorf("Location is %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package gophergin
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LivenessHandler returns a handler that always responds 200 OK, signalling that the process is up.
+//
+// Returns:
+// - gin.HandlerFunc: The liveness handler, e.g. for a Kubernetes livenessProbe on /healthz.
+func LivenessHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	}
+}
+
+// ReadinessHandler returns a handler that responds 200 OK when check succeeds and 503 Service
+// Unavailable with the error message when it fails.
+//
+// Parameters:
+// - check: The readiness check, e.g. a database ping; nil always reports ready.
+//
+// Returns:
+// - gin.HandlerFunc: The readiness handler, e.g. for a Kubernetes readinessProbe on /readyz.
+//
+// Example usage:
+//
+//	router.GET("/readyz", gophergin.ReadinessHandler(func() error {
+//	    return db.PingContext(context.Background())
+//	}))
+func ReadinessHandler(check func() error) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if check != nil {
+			if err := check(); err != nil {
+				c.JSON(http.StatusServiceUnavailable, gin.H{"status": "unavailable", "error": err.Error()})
+				return
+			}
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	}
+}
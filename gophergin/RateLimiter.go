@@ -0,0 +1,125 @@
+package gophergin
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// rateLimitSweepInterval is how often idle buckets are dropped from a rate limiter.
+const rateLimitSweepInterval = time.Minute
+
+// KeyFunc extracts the key requests are rate limited by, such as the client IP or an API key header.
+type KeyFunc func(c *gin.Context) string
+
+// tokenBucket holds the tokens left for one key and when they were last refilled.
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// rateLimiter keeps a token bucket per key.
+type rateLimiter struct {
+	mu        sync.Mutex
+	rate      float64
+	burst     float64
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+// RateLimiter returns a token-bucket rate limiting middleware.
+//
+// Every key may make burst requests at once and is then refilled at requestsPerSecond. A request
+// over the limit is aborted with 429 Too Many Requests and a Retry-After header giving the number
+// of seconds until the next request is allowed. The middleware can be used on the whole router
+// or on a single route group.
+//
+// Parameters:
+// - requestsPerSecond: The sustained rate allowed per key; it must be positive, as with time.NewTicker
+// a non-positive rate panics. Skip the middleware to disable rate limiting instead.
+// - burst: The number of requests allowed at once; values below 1 are treated as 1.
+// - keyFunc: Extracts the key requests are limited by; nil keys on c.ClientIP().
+//
+// Returns:
+// - gin.HandlerFunc: The rate limiting middleware.
+//
+// Example usage:
+//
+//	api := router.Group("/api")
+//	api.Use(gophergin.RateLimiter(5, 10, func(c *gin.Context) string {
+//	    return c.GetHeader("X-API-Key")
+//	}))
+func RateLimiter(requestsPerSecond float64, burst int, keyFunc KeyFunc) gin.HandlerFunc {
+	if !(requestsPerSecond > 0) {
+		panic(fmt.Sprintf("gophergin: non-positive rate %v for RateLimiter", requestsPerSecond))
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	if keyFunc == nil {
+		keyFunc = func(c *gin.Context) string {
+			return c.ClientIP()
+		}
+	}
+
+	limiter := &rateLimiter{
+		rate:    requestsPerSecond,
+		burst:   float64(burst),
+		buckets: make(map[string]*tokenBucket),
+	}
+
+	return func(c *gin.Context) {
+		allowed, wait := limiter.allow(keyFunc(c), time.Now())
+		if !allowed {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "rate limit exceeded"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// allow takes a token from the key's bucket, reporting how long to wait for the next one if it is empty.
+func (l *rateLimiter) allow(key string, now time.Time) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now)
+
+	bucket, ok := l.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: l.burst, last: now}
+		l.buckets[key] = bucket
+	}
+
+	// Refill for the time elapsed since the last request, up to the burst size
+	bucket.tokens = math.Min(l.burst, bucket.tokens+now.Sub(bucket.last).Seconds()*l.rate)
+	bucket.last = now
+
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		return true, 0
+	}
+	return false, time.Duration((1 - bucket.tokens) / l.rate * float64(time.Second))
+}
+
+// sweep drops the buckets that have been idle long enough to be full again, so memory does not grow
+// with every client ever seen.
+func (l *rateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < rateLimitSweepInterval {
+		return
+	}
+	l.lastSweep = now
+
+	refill := time.Duration(l.burst / l.rate * float64(time.Second))
+	for key, bucket := range l.buckets {
+		if now.Sub(bucket.last) >= refill {
+			delete(l.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package gophergin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRateLimiterRejectsRequestsPastBurst(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RateLimiter(0.5, 2, nil))
+	router.GET("/items", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	request := func(remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/items", nil)
+		req.RemoteAddr = remoteAddr
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	for i := 0; i < 2; i++ {
+		if recorder := request("192.0.2.1:1234"); recorder.Code != http.StatusOK {
+			t.Fatalf("request %d within the burst returned %d, want %d", i, recorder.Code, http.StatusOK)
+		}
+	}
+	recorder := request("192.0.2.1:1234")
+	if recorder.Code != http.StatusTooManyRequests {
+		t.Fatalf("request past the burst returned %d, want %d", recorder.Code, http.StatusTooManyRequests)
+	}
+	// One token takes two seconds to refill at half a request per second
+	if got := recorder.Header().Get("Retry-After"); got != "2" {
+		t.Errorf("Retry-After is %q, want \"2\"", got)
+	}
+
+	if recorder := request("192.0.2.2:1234"); recorder.Code != http.StatusOK {
+		t.Errorf("another client was limited with %d", recorder.Code)
+	}
+}
+
+func TestRateLimiterPanicsOnNonPositiveRate(t *testing.T) {
+	for _, rate := range []float64{0, -1} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("RateLimiter(%v, ...) did not panic", rate)
+				}
+			}()
+			RateLimiter(rate, 1, nil)
+		}()
+	}
+}
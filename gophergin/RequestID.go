@@ -0,0 +1,88 @@
+package gophergin
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestIDHeader is the header a request ID is read from and echoed in.
+const RequestIDHeader = "X-Request-ID"
+
+// RequestIDKey is the key under which RequestID stores the ID in the Gin context.
+const RequestIDKey = "request_id"
+
+// maxRequestIDLength bounds the length of an incoming request ID that is accepted as is.
+const maxRequestIDLength = 128
+
+// requestIDContextKey is the type of the key under which RequestID stores the ID in the request context.
+type requestIDContextKey struct{}
+
+// RequestID returns a middleware that gives every request an ID for log correlation and tracing.
+//
+// The ID is taken from the X-Request-ID header when the client or a proxy sent one, so it stays the
+// same across services, and generated as a random UUID otherwise. IDs longer than 128 characters or
+// containing anything but printable ASCII are replaced, so they cannot forge log lines. The ID is
+// echoed in the X-Request-ID response header and stored in both the Gin context and the request
+// context, see GetRequestID and RequestIDFromContext. RequestLogger includes it in the access log.
+//
+// Returns:
+// - gin.HandlerFunc: The request ID middleware.
+//
+// Example usage:
+//
+//	router := gin.New()
+//	router.Use(gophergin.RequestID())
+//	router.GET("/orders", func(c *gin.Context) {
+//	    ctx := gopherlogger.WithFields(c.Request.Context(), "request_id", gophergin.GetRequestID(c))
+//	    gopherlogger.LoggerFromContext(ctx).Info("listing orders")
+//	})
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+
+		c.Set(RequestIDKey, id)
+		c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), requestIDContextKey{}, id))
+		c.Header(RequestIDHeader, id)
+		c.Next()
+	}
+}
+
+// GetRequestID returns the ID RequestID assigned to the request, or "" when the middleware is not in use.
+func GetRequestID(c *gin.Context) string {
+	return c.GetString(RequestIDKey)
+}
+
+// RequestIDFromContext returns the ID RequestID assigned to the request whose context ctx is, or
+// derives from, so code that only receives a context.Context can log it. It returns "" when there is none.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDContextKey{}).(string)
+	return id
+}
+
+// validRequestID reports whether an incoming request ID is safe to reuse.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// newRequestID returns a random version 4 UUID.
+func newRequestID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
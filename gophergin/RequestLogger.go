@@ -0,0 +1,108 @@
+package gophergin
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Request log formats supported by RequestLogger.
+const (
+	RequestLogFormatText = "text"
+	RequestLogFormatJSON = "json"
+)
+
+// requestLogEntry is a single access log record.
+type requestLogEntry struct {
+	Method    string `json:"method"`
+	Path      string `json:"path"`
+	Status    int    `json:"status"`
+	LatencyMs int64  `json:"latency_ms"`
+	ClientIP  string `json:"client_ip"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// AccessLogger receives the access log entries of RequestLogger as a message with key/value pairs.
+// *gopherlogger.Logger satisfies it, as do other structured loggers with a matching Info method.
+type AccessLogger interface {
+	Info(msg string, keyvals ...any)
+}
+
+// RequestLogger returns a middleware that writes one access log entry per request with the method,
+// path, status, latency, and client IP, and the request ID when the RequestID middleware is in use.
+//
+// Entries go to logger as an Info "request" message with those fields as key/value pairs, so its own
+// level filtering and text or JSON encoding apply. Without a logger they are written as lines in the
+// given format through the standard library's default logger.
+//
+// Parameters:
+// - format: RequestLogFormatText for key=value lines or RequestLogFormatJSON for JSON objects; only used when logger is nil.
+// - logger: The logger to write to, e.g. a *gopherlogger.Logger; nil uses the standard library's default logger.
+//
+// Returns:
+// - gin.HandlerFunc: The access log middleware.
+//
+// Example usage:
+//
+//	logger := gopherlogger.NewLogger(os.Stdout, gopherlogger.LevelInfo, gopherlogger.WithFormat(gopherlogger.FormatJSON))
+//	router := gin.New()
+//	router.Use(gin.Recovery(), gophergin.RequestLogger(gophergin.RequestLogFormatText, logger))
+func RequestLogger(format string, logger AccessLogger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		entry := requestLogEntry{
+			Method:    c.Request.Method,
+			Path:      c.Request.URL.Path,
+			Status:    c.Writer.Status(),
+			LatencyMs: time.Since(start).Milliseconds(),
+			ClientIP:  c.ClientIP(),
+			RequestID: GetRequestID(c),
+		}
+
+		if logger != nil {
+			logger.Info("request", entry.keyvals()...)
+			return
+		}
+		entry.print(format)
+	}
+}
+
+// keyvals returns the entry as alternating keys and values, leaving out an empty request ID.
+func (entry requestLogEntry) keyvals() []any {
+	keyvals := []any{
+		"method", entry.Method,
+		"path", entry.Path,
+		"status", entry.Status,
+		"latency_ms", entry.LatencyMs,
+		"client_ip", entry.ClientIP,
+	}
+	if entry.RequestID != "" {
+		keyvals = append(keyvals, "request_id", entry.RequestID)
+	}
+	return keyvals
+}
+
+// print writes the entry through the standard library logger as a line in the given format.
+func (entry requestLogEntry) print(format string) {
+	if format == RequestLogFormatJSON {
+		line, err := json.Marshal(entry)
+		if err != nil {
+			log.Printf("Failed to encode request log entry: %v", err)
+			return
+		}
+		log.Println(string(line))
+		return
+	}
+
+	line := fmt.Sprintf("method=%s path=%s status=%d latency_ms=%d client_ip=%s",
+		entry.Method, entry.Path, entry.Status, entry.LatencyMs, entry.ClientIP)
+	if entry.RequestID != "" {
+		line += " request_id=" + entry.RequestID
+	}
+	log.Println(line)
+}
@@ -0,0 +1,48 @@
+package gophergin
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// recordingLogger is an AccessLogger that keeps the entries it receives.
+type recordingLogger struct {
+	msgs    []string
+	keyvals [][]any
+}
+
+func (l *recordingLogger) Info(msg string, keyvals ...any) {
+	l.msgs = append(l.msgs, msg)
+	l.keyvals = append(l.keyvals, keyvals)
+}
+
+func TestRequestLoggerWritesToAccessLogger(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := &recordingLogger{}
+	router := gin.New()
+	router.Use(RequestLogger(RequestLogFormatText, logger))
+	router.GET("/items", func(c *gin.Context) { c.Status(http.StatusTeapot) })
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/items", nil))
+
+	if len(logger.msgs) != 1 || logger.msgs[0] != "request" {
+		t.Fatalf("logged messages %v, want one \"request\"", logger.msgs)
+	}
+	fields := make(map[string]string)
+	keyvals := logger.keyvals[0]
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		fields[fmt.Sprint(keyvals[i])] = fmt.Sprint(keyvals[i+1])
+	}
+	for key, want := range map[string]string{"method": "GET", "path": "/items", "status": "418"} {
+		if fields[key] != want {
+			t.Errorf("%s = %q, want %q", key, fields[key], want)
+		}
+	}
+	if _, ok := fields["request_id"]; ok {
+		t.Error("request_id logged without the RequestID middleware")
+	}
+}
@@ -3,45 +3,137 @@ package gophergin
 import (
 	"context"
 	"crypto/tls"
+	"errors"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"sync"
+	"syscall"
 	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
 )
 
 // ServerConfig holds the configuration for setting up the server.
 //
 // Fields:
 // - Port: Port number to run the server on.
+// - Host: Host or IP to bind to, e.g. "127.0.0.1" for local-only access; empty binds all interfaces.
+// - UnixSocket: Path of a Unix domain socket to listen on instead of TCP; Host and Port are then ignored.
 // - UseTLS: Enable TLS (HTTPS) if true.
 // - TLSCertFile: Path to the TLS certificate file (required if UseTLS is true).
 // - TLSKeyFile: Path to the TLS key file (required if UseTLS is true).
 // - UseCORS: Enable CORS (Cross-Origin Resource Sharing) if true.
 // - CORSConfig: Configures allowed origins, headers, and methods for CORS.
+// - Middlewares: Middleware applied to every route in the given order, before CORS.
+// - DisableDefaultMiddleware: Leave out Gin's logger and recovery middleware if true, e.g. to supply
+// your own through Middlewares; EnableRequestLog still adds RequestLogger.
+// - EnableRequestLog: Replace Gin's default logger with the RequestLogger access log if true.
+// - EnableRequestID: Assign every request an ID with the RequestID middleware if true, echoed in X-Request-ID.
+// - RequestLogFormat: RequestLogFormatText (default) or RequestLogFormatJSON, used when RequestLogger is nil.
+// - RequestLogger: Logger the access log is written to, e.g. a *gopherlogger.Logger; nil uses the standard library logger.
+// - UseHTTP2: Negotiate HTTP/2 via ALPN on the TLS listener if true (requires UseTLS).
+// - AllowH2C: Accept cleartext HTTP/2 (h2c) on a plain listener if true, e.g. behind a load balancer.
+// - HealthCheckPath: Path of a liveness endpoint that always returns 200 (e.g. "/healthz"); empty disables it.
+// - ReadinessPath: Path of a readiness endpoint that returns 503 while ReadinessCheck fails (e.g. "/readyz"); empty disables it.
+// - ReadinessCheck: Optional check run on every readiness request, such as a database ping.
+// - ReadTimeout, ReadHeaderTimeout, WriteTimeout, IdleTimeout: Timeouts of the underlying http.Server.
+// Zero uses the defaults below, which protect against slowloris and hung connections; a negative
+// value disables the timeout. WriteTimeout has no default, so server-sent events and long downloads
+// are not cut off; set it to bound the time a handler may take to write its response.
+// - RateLimitRPS: Requests per second allowed per client by the RateLimiter middleware; zero disables rate limiting.
+// - RateLimitBurst: Requests a client may make at once before being limited.
+// - RateLimitKeyFunc: Extracts the rate limit key, e.g. a header when behind a proxy; nil uses the client IP.
+// - RedirectHTTPPort: When UseTLS is true, also listen for plain HTTP on this port and answer with a 301 redirect to HTTPS.
+// - EnableMetrics: Record Prometheus request metrics and expose them on MetricsPath if true.
+// - MetricsPath: Path of the metrics endpoint; empty uses DefaultMetricsPath.
+// - MetricsRegistry: Registry the metrics are registered with and exposed from; nil creates one per server.
+// - WebSocketHub: Hub whose WebSocket connections GracefulShutdown closes with a "going away" frame; nil closes none.
 type ServerConfig struct {
 	Port        int
+	Host        string
+	UnixSocket  string
 	UseTLS      bool
 	TLSCertFile string
 	TLSKeyFile  string
 	UseCORS     bool
 	CORSConfig  cors.Config
+	Middlewares []gin.HandlerFunc
+
+	DisableDefaultMiddleware bool
+
+	EnableRequestLog bool
+	RequestLogFormat string
+	RequestLogger    AccessLogger
+	EnableRequestID  bool
+
+	UseHTTP2 bool
+	AllowH2C bool
+
+	HealthCheckPath string
+	ReadinessPath   string
+	ReadinessCheck  func() error
+
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+
+	RateLimitRPS     float64
+	RateLimitBurst   int
+	RateLimitKeyFunc KeyFunc
+
+	RedirectHTTPPort int
+
+	EnableMetrics   bool
+	MetricsPath     string
+	MetricsRegistry *prometheus.Registry
+
+	WebSocketHub *Hub
 }
 
+// Default timeouts applied to the http.Server when the corresponding ServerConfig field is zero.
+//
+// DefaultWriteTimeout is zero, meaning no timeout, as a write deadline would cut off streaming
+// responses such as server-sent events; DefaultReadHeaderTimeout already guards against slowloris.
+const (
+	DefaultReadTimeout       = 30 * time.Second
+	DefaultReadHeaderTimeout = 10 * time.Second
+	DefaultWriteTimeout      = time.Duration(0)
+	DefaultIdleTimeout       = 120 * time.Second
+)
+
+// DefaultShutdownTimeout is the grace period GracefulShutdown uses when it is given a zero timeout.
+const DefaultShutdownTimeout = 5 * time.Second
+
 // Server interface defines the behavior of a Gin server.
 //
 // Methods:
-// - Start: Starts the server (optionally with TLS).
-// - GracefulShutdown: Gracefully shuts down the server when interrupted.
+// - Start: Runs the server (optionally with TLS), blocking until it stops.
+// - StartAsync: Starts the server in the background and reports how it stopped on the returned channel.
+// - GracefulShutdown: Gracefully shuts down the server, waiting for in-flight requests up to a timeout.
 // - GetRouter: Returns the underlying gin.Engine for additional route setup.
+// - ReloadTLS: Reloads the TLS certificate and key from disk without restarting.
+// - Ready: Returns a channel closed once the listener is bound and accepting connections.
+// - WaitReady: Blocks until the listener is bound or ctx is done.
+// - Addr: Returns the address the listener is bound to, or nil before it is.
 type Server interface {
 	Start() error
-	GracefulShutdown()
+	StartAsync() <-chan error
+	GracefulShutdown(ctx context.Context, timeout time.Duration) error
 	GetRouter() *gin.Engine
+	ReloadTLS() error
+	Ready() <-chan struct{}
+	WaitReady(ctx context.Context) error
+	Addr() net.Addr
 }
 
 // ServerSetup defines the behavior for setting up a Gin server.
@@ -49,11 +141,11 @@ type Server interface {
 // Methods:
 // - SetUpRouter: Configures and returns a new Gin engine.
 // - SetUpTLS: Configures TLS settings if required (returns a tls.Config instance).
-// - SetUpCORS: Applies CORS middleware to the Gin engine if enabled.
+// - SetUpCORS: Applies CORS middleware to the Gin engine if enabled, or returns an error if its configuration is invalid.
 type ServerSetup interface {
 	SetUpRouter(config ServerConfig) *gin.Engine
 	SetUpTLS(config ServerConfig) (*tls.Config, error)
-	SetUpCORS(router *gin.Engine, config ServerConfig)
+	SetUpCORS(router *gin.Engine, config ServerConfig) error
 }
 
 // ServerSetupImpl is the concrete implementation of ServerSetup.
@@ -61,13 +153,71 @@ type ServerSetupImpl struct{}
 
 // SetUpRouter sets up a Gin server.
 //
+// The engine comes with Gin's logger and recovery middleware, the logger being replaced by
+// RequestLogger when config.EnableRequestLog is set. With config.DisableDefaultMiddleware neither is
+// installed, so a custom logger or recovery in config.Middlewares does not run alongside them.
+//
+// The request ID middleware is applied first when enabled, so every response carries an ID, then the
+// metrics middleware when enabled, so every request is counted, then rate
+// limiting when enabled, followed by the middleware from config.Middlewares
+// in order, so both run before CORS and before any route added through GetRouter. The health check
+// and metrics endpoints are registered when configured, the health checks ahead of rate limiting and
+// config.Middlewares so that probes are not throttled or turned away by authentication. Metrics are left out, with a log message,
+// if they cannot be registered with config.MetricsRegistry.
+//
 // Parameters:
 // - config: The server configuration.
 //
 // Returns:
 // - *gin.Engine: A configured Gin engine.
 func (s *ServerSetupImpl) SetUpRouter(config ServerConfig) *gin.Engine {
-	router := gin.Default()
+	var router *gin.Engine
+	switch {
+	case config.DisableDefaultMiddleware:
+		router = gin.New()
+		if config.EnableRequestLog {
+			router.Use(RequestLogger(config.RequestLogFormat, config.RequestLogger))
+		}
+	case config.EnableRequestLog:
+		router = gin.New()
+		router.Use(gin.Recovery(), RequestLogger(config.RequestLogFormat, config.RequestLogger))
+	default:
+		router = gin.Default()
+	}
+
+	if config.EnableRequestID {
+		router.Use(RequestID())
+	}
+
+	if config.EnableMetrics {
+		metrics, err := NewMetrics(config.MetricsRegistry)
+		if err != nil {
+			log.Printf("Metrics disabled: %v", err)
+		} else {
+			path := config.MetricsPath
+			if path == "" {
+				path = DefaultMetricsPath
+			}
+			router.Use(metrics.Middleware())
+			router.GET(path, metrics.Handler())
+		}
+	}
+
+	// Health routes are registered before rate limiting and the custom middleware, which gin only
+	// applies to routes added after them, so probes are never throttled or rejected by authentication
+	if config.HealthCheckPath != "" {
+		router.GET(config.HealthCheckPath, LivenessHandler())
+	}
+	if config.ReadinessPath != "" {
+		router.GET(config.ReadinessPath, ReadinessHandler(config.ReadinessCheck))
+	}
+
+	if config.RateLimitRPS > 0 {
+		router.Use(RateLimiter(config.RateLimitRPS, config.RateLimitBurst, config.RateLimitKeyFunc))
+	}
+	if len(config.Middlewares) > 0 {
+		router.Use(config.Middlewares...)
+	}
 	return router
 }
 
@@ -98,23 +248,43 @@ func (s *ServerSetupImpl) SetUpTLS(config ServerConfig) (*tls.Config, error) {
 
 // SetUpCORS configures and applies CORS middleware if enabled.
 //
+// The configuration is validated first, so that a mistake is reported at startup rather than by
+// browsers at runtime. DefaultCORSConfig gives a sane starting point.
+//
 // Parameters:
 // - router: The Gin engine to apply the middleware to.
 // - config: The server configuration that contains CORS settings.
-func (s *ServerSetupImpl) SetUpCORS(router *gin.Engine, config ServerConfig) {
-	if config.UseCORS {
-		router.Use(cors.New(config.CORSConfig))
-		log.Printf("CORS configured with settings: %+v", config.CORSConfig)
+//
+// Returns:
+// - error: ErrCORSCredentialsWithWildcard if AllowCredentials is combined with a "*" origin or
+// AllowAllOrigins, or an error for settings the CORS middleware rejects, such as no origins at all.
+func (s *ServerSetupImpl) SetUpCORS(router *gin.Engine, config ServerConfig) error {
+	if !config.UseCORS {
+		return nil
+	}
+	if err := validateCORSConfig(config.CORSConfig); err != nil {
+		return err
 	}
+
+	router.Use(cors.New(config.CORSConfig))
+	log.Printf("CORS configured with settings: %+v", config.CORSConfig)
+	return nil
 }
 
 // GinServer is the modular implementation of the Server interface.
 // It wraps around Gin's HTTP server and provides modular setup and shutdown.
 type GinServer struct {
-	router      *gin.Engine
-	server      *http.Server
-	serverSetup ServerSetup
-	config      ServerConfig
+	router       *gin.Engine
+	server       *http.Server
+	serverSetup  ServerSetup
+	config       ServerConfig
+	certReloader *CertReloader
+
+	redirectServer *http.Server
+
+	ready     chan struct{}
+	readyOnce sync.Once
+	addr      net.Addr
 }
 
 // NewGinServer creates a new GinServer instance with injected dependencies.
@@ -125,53 +295,232 @@ type GinServer struct {
 //
 // Returns:
 // - Server: A configured Gin server ready to start.
-func NewGinServer(setup ServerSetup, config ServerConfig) Server {
+// - error: An error if CORS, TLS or HTTP/2 cannot be set up, e.g. when the certificate files cannot be loaded.
+func NewGinServer(setup ServerSetup, config ServerConfig) (Server, error) {
 	router := setup.SetUpRouter(config)
-	setup.SetUpCORS(router, config)
+	if err := setup.SetUpCORS(router, config); err != nil {
+		return nil, fmt.Errorf("error setting up CORS: %w", err)
+	}
 
 	// Create the HTTP server instance.
 	server := &http.Server{
-		Addr:    fmt.Sprintf(":%d", config.Port),
-		Handler: router,
+		Addr:              net.JoinHostPort(config.Host, strconv.Itoa(config.Port)),
+		Handler:           router,
+		ReadTimeout:       timeoutOrDefault(config.ReadTimeout, DefaultReadTimeout),
+		ReadHeaderTimeout: timeoutOrDefault(config.ReadHeaderTimeout, DefaultReadHeaderTimeout),
+		WriteTimeout:      timeoutOrDefault(config.WriteTimeout, DefaultWriteTimeout),
+		IdleTimeout:       timeoutOrDefault(config.IdleTimeout, DefaultIdleTimeout),
+	}
+
+	// Upgraded WebSocket connections are not tracked by Shutdown, so close them through the hub.
+	if config.WebSocketHub != nil {
+		server.RegisterOnShutdown(config.WebSocketHub.Close)
 	}
 
 	// Set up TLS if enabled.
 	tlsConfig, err := setup.SetUpTLS(config)
 	if err != nil {
-		log.Fatalf("Error setting up TLS: %v", err)
+		return nil, fmt.Errorf("error setting up TLS: %w", err)
 	}
 	server.TLSConfig = tlsConfig
 
+	// Serve the certificate through a reloader so renewed certificates are picked up on the fly.
+	var certReloader *CertReloader
+	if tlsConfig != nil && tlsConfig.GetCertificate == nil && config.TLSCertFile != "" {
+		certReloader, err = NewCertReloader(config.TLSCertFile, config.TLSKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("error setting up TLS: %w", err)
+		}
+		tlsConfig.Certificates = nil
+		tlsConfig.GetCertificate = certReloader.GetCertificate
+	}
+
+	// Set up HTTP/2 if enabled.
+	if err := configureHTTP2(server, config); err != nil {
+		return nil, fmt.Errorf("error setting up HTTP/2: %w", err)
+	}
+
 	return &GinServer{
-		router:      router,
-		server:      server,
-		serverSetup: setup,
-		config:      config,
+		router:       router,
+		server:       server,
+		serverSetup:  setup,
+		config:       config,
+		certReloader: certReloader,
+
+		redirectServer: newRedirectServer(config),
+
+		ready: make(chan struct{}),
+	}, nil
+}
+
+// timeoutOrDefault returns the default for a zero timeout and no timeout at all for a negative one.
+func timeoutOrDefault(timeout, defaultTimeout time.Duration) time.Duration {
+	if timeout == 0 {
+		return defaultTimeout
+	}
+	if timeout < 0 {
+		return 0
+	}
+	return timeout
+}
+
+// configureHTTP2 enables HTTP/2 over TLS and cleartext h2c on the server as requested by the config.
+// It must run after the server's TLSConfig has been set.
+func configureHTTP2(server *http.Server, config ServerConfig) error {
+	// net/http offers HTTP/2 over TLS by itself while TLSNextProto is nil, so an empty map turns it off
+	if config.UseTLS && !config.UseHTTP2 {
+		server.TLSNextProto = map[string]func(*http.Server, *tls.Conn, http.Handler){}
+	}
+
+	if !config.AllowH2C && !(config.UseHTTP2 && config.UseTLS) {
+		return nil
+	}
+
+	h2Server := &http2.Server{}
+	if config.AllowH2C {
+		server.Handler = h2c.NewHandler(server.Handler, h2Server)
+	}
+	if config.UseHTTP2 && config.UseTLS {
+		if err := http2.ConfigureServer(server, h2Server); err != nil {
+			return fmt.Errorf("failed to configure HTTP/2: %w", err)
+		}
 	}
+	return nil
 }
 
-// Start starts the Gin server, either with or without TLS.
+// Start runs the Gin server, either with or without TLS, and blocks until it stops.
 //
 // Returns:
-// - error: Any error encountered while starting the server.
+// - error: The error that stopped the server, such as the port already being in use or an invalid
+// TLS certificate, or nil once the server has been shut down with GracefulShutdown.
 func (gs *GinServer) Start() error {
+	listener, err := gs.listen()
+	if err != nil {
+		return err
+	}
+	return gs.serve(listener)
+}
+
+// StartAsync starts the Gin server in the background.
+//
+// The listener is bound before StartAsync returns, so a request made afterwards reaches the server.
+//
+// Returns:
+// - <-chan error: Receives the error that stopped the server, or nil after GracefulShutdown, and is then closed.
+// A bind failure such as the port being in use is delivered immediately.
+func (gs *GinServer) StartAsync() <-chan error {
+	errCh := make(chan error, 1)
+
+	listener, err := gs.listen()
+	if err != nil {
+		errCh <- err
+		close(errCh)
+		return errCh
+	}
+
+	go func() {
+		defer close(errCh)
+		errCh <- gs.serve(listener)
+	}()
+	return errCh
+}
+
+// listen binds the server's address, or its Unix socket when one is configured, and starts the
+// HTTPS redirect server if there is one.
+func (gs *GinServer) listen() (net.Listener, error) {
+	if gs.config.UnixSocket != "" {
+		// Remove a socket left behind by a previous run; anything else at the path is kept and reported by Listen
+		if info, err := os.Stat(gs.config.UnixSocket); err == nil && info.Mode()&os.ModeSocket != 0 {
+			os.Remove(gs.config.UnixSocket)
+		}
+
+		listener, err := net.Listen("unix", gs.config.UnixSocket)
+		if err != nil {
+			return nil, fmt.Errorf("failed to listen on unix socket %s: %w", gs.config.UnixSocket, err)
+		}
+		return listener, nil
+	}
+
+	listener, err := net.Listen("tcp", gs.server.Addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", gs.server.Addr, err)
+	}
+
+	if gs.redirectServer != nil {
+		if err := gs.startRedirect(); err != nil {
+			listener.Close()
+			return nil, err
+		}
+	}
+	return listener, nil
+}
+
+// serve serves requests on the listener until the server stops, treating a graceful shutdown as success.
+// The server is reported ready before serving starts, as the bound listener already queues connections.
+func (gs *GinServer) serve(listener net.Listener) error {
+	gs.readyOnce.Do(func() {
+		gs.addr = listener.Addr()
+		close(gs.ready)
+	})
+
+	var err error
 	if gs.config.UseTLS {
-		log.Printf("Starting server on port %d with TLS", gs.config.Port)
-		go func() {
-			if err := gs.server.ListenAndServeTLS("", ""); err != nil && err != http.ErrServerClosed {
-				log.Printf("ListenAndServeTLS error: %v", err)
-			}
-		}()
+		log.Printf("Starting server on %s with TLS", listener.Addr())
+		err = gs.server.ServeTLS(listener, "", "")
 	} else {
-		log.Printf("Starting server on port %d without TLS", gs.config.Port)
-		go func() {
-			if err := gs.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-				log.Printf("ListenAndServe error: %v", err)
-			}
-		}()
+		log.Printf("Starting server on %s without TLS", listener.Addr())
+		err = gs.server.Serve(listener)
 	}
 
-	return nil
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// Ready returns a channel that is closed once the listener is bound, so requests made afterwards are
+// accepted instead of failing with "connection refused". It stays open if the server fails to bind.
+//
+// Example usage:
+//
+//	errCh := server.StartAsync()
+//	select {
+//	case <-server.Ready():
+//	    resp, err := http.Get("http://" + server.Addr().String() + "/healthz")
+//	case err := <-errCh:
+//	    log.Fatalf("Server failed to start: %v", err)
+//	}
+func (gs *GinServer) Ready() <-chan struct{} {
+	return gs.ready
+}
+
+// WaitReady blocks until the listener is bound.
+//
+// Parameters:
+// - ctx: Context bounding the wait, e.g. with a timeout in tests.
+//
+// Returns:
+// - error: ctx.Err() if ctx is done before the server is ready.
+func (gs *GinServer) WaitReady(ctx context.Context) error {
+	select {
+	case <-gs.ready:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Addr returns the address the listener is bound to, which tells the port picked when Port is 0.
+//
+// Returns:
+// - net.Addr: The bound address, or nil until the server is ready.
+func (gs *GinServer) Addr() net.Addr {
+	select {
+	case <-gs.ready:
+		return gs.addr
+	default:
+		return nil
+	}
 }
 
 // GetRouter returns the gin.Engine instance.
@@ -182,25 +531,74 @@ func (gs *GinServer) GetRouter() *gin.Engine {
 	return gs.router
 }
 
-// GracefulShutdown gracefully shuts down the server when interrupted.
+// ReloadTLS reloads the TLS certificate and key from the configured files.
 //
-// This method handles system interrupts (e.g., Ctrl+C) and shuts down the server
-// gracefully, allowing for ongoing requests to finish within a 5-second timeout.
-func (gs *GinServer) GracefulShutdown() {
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, os.Interrupt)
-	<-quit
+// Modified files are also picked up automatically on the next TLS handshake, so calling ReloadTLS
+// is only needed to load them eagerly, e.g. from a SIGHUP handler or a renewal hook.
+//
+// Returns:
+// - error: An error if TLS is not enabled or the key pair cannot be loaded; the previous certificate keeps being served.
+func (gs *GinServer) ReloadTLS() error {
+	if gs.certReloader == nil {
+		return fmt.Errorf("TLS certificate reloading is not enabled")
+	}
+	return gs.certReloader.Reload()
+}
+
+// GracefulShutdown gracefully shuts down the server.
+//
+// The server stops accepting new connections and waits for in-flight requests to finish, until the
+// timeout elapses or ctx is cancelled, whichever comes first. Signal handling is left to the caller;
+// see WaitForSignal for the common case.
+//
+// Parameters:
+// - ctx: Context that, when cancelled, forces the shutdown to stop waiting.
+// - timeout: The grace period for in-flight requests; zero or less uses DefaultShutdownTimeout.
+//
+// Returns:
+// - error: An error if the server could not shut down cleanly, e.g. ctx.Err() when the grace period ran out.
+func (gs *GinServer) GracefulShutdown(ctx context.Context, timeout time.Duration) error {
+	if timeout <= 0 {
+		timeout = DefaultShutdownTimeout
+	}
 
 	log.Println("Shutting down server...")
 
-	// Allow up to 5 seconds for graceful shutdown.
-	ctxShutDown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctxShutDown, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
+	if gs.redirectServer != nil {
+		if err := gs.redirectServer.Shutdown(ctxShutDown); err != nil {
+			log.Printf("HTTPS redirect server forced to shutdown: %v", err)
+		}
+	}
+
 	if err := gs.server.Shutdown(ctxShutDown); err != nil {
 		log.Printf("Server forced to shutdown: %v", err)
+		return fmt.Errorf("failed to shut down server gracefully: %w", err)
 	}
 	log.Println("Server shutdown successfully")
+	return nil
+}
+
+// WaitForSignal blocks until the process receives one of the given signals, or os.Interrupt and
+// SIGTERM when none are given, and returns the signal received.
+//
+// Example usage:
+//
+//	server.StartAsync()
+//	gophergin.WaitForSignal()
+//	server.GracefulShutdown(context.Background(), 10*time.Second)
+func WaitForSignal(signals ...os.Signal) os.Signal {
+	if len(signals) == 0 {
+		signals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, signals...)
+	defer signal.Stop(quit)
+
+	return <-quit
 }
 
 // Example usage:
@@ -218,14 +616,23 @@ func (gs *GinServer) GracefulShutdown() {
 //	        },
 //	    }
 //
-//	    server := gophergin.NewGinServer(&gophergin.ServerSetupImpl{}, config)
-//
-//	    // Start the server
-//	    err := server.Start()
+//	    server, err := gophergin.NewGinServer(&gophergin.ServerSetupImpl{}, config)
 //	    if err != nil {
-//	        log.Fatalf("Failed to start server: %v", err)
+//	        log.Fatalf("Failed to set up server: %v", err)
 //	    }
 //
-//	    // Gracefully shut down the server on interrupt
-//	    server.GracefulShutdown()
+//	    // Start the server in the background
+//	    errCh := server.StartAsync()
+//
+//	    // Gracefully shut down the server on interrupt, unless it failed first
+//	    go func() {
+//	        gophergin.WaitForSignal()
+//	        if err := server.GracefulShutdown(context.Background(), 5*time.Second); err != nil {
+//	            log.Printf("Shutdown error: %v", err)
+//	        }
+//	    }()
+//
+//	    if err := <-errCh; err != nil {
+//	        log.Fatalf("Server error: %v", err)
+//	    }
 //	}
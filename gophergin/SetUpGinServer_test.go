@@ -0,0 +1,165 @@
+package gophergin
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestNewGinServerTimeouts(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		name             string
+		writeTimeout     time.Duration
+		wantWriteTimeout time.Duration
+	}{
+		{"default leaves streaming responses open", 0, 0},
+		{"explicit", 5 * time.Second, 5 * time.Second},
+		{"negative disables", -1, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, err := NewGinServer(&ServerSetupImpl{}, ServerConfig{Port: 8080, WriteTimeout: tt.writeTimeout})
+			if err != nil {
+				t.Fatalf("NewGinServer failed: %v", err)
+			}
+			server := srv.(*GinServer).server
+			if server.WriteTimeout != tt.wantWriteTimeout {
+				t.Errorf("WriteTimeout = %v, want %v", server.WriteTimeout, tt.wantWriteTimeout)
+			}
+			if server.ReadHeaderTimeout != DefaultReadHeaderTimeout {
+				t.Errorf("ReadHeaderTimeout = %v, want %v", server.ReadHeaderTimeout, DefaultReadHeaderTimeout)
+			}
+		})
+	}
+}
+
+func TestSetUpRouterHealthChecksSkipRateLimitAndMiddlewares(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	deny := func(c *gin.Context) { c.AbortWithStatus(http.StatusUnauthorized) }
+	router := (&ServerSetupImpl{}).SetUpRouter(ServerConfig{
+		HealthCheckPath: "/healthz",
+		ReadinessPath:   "/readyz",
+		RateLimitRPS:    1,
+		RateLimitBurst:  1,
+		Middlewares:     []gin.HandlerFunc{deny},
+	})
+	router.GET("/items", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	for _, path := range []string{"/healthz", "/readyz"} {
+		for i := 0; i < 3; i++ {
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+			if recorder.Code != http.StatusOK {
+				t.Fatalf("request %d to %s returned %d, want %d", i, path, recorder.Code, http.StatusOK)
+			}
+		}
+	}
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/items", nil))
+	if recorder.Code != http.StatusUnauthorized {
+		t.Errorf("other route returned %d, want the middleware to reject it with %d", recorder.Code, http.StatusUnauthorized)
+	}
+}
+
+// writeTestCertificate writes a self-signed certificate for 127.0.0.1 and its key as PEM files and
+// returns their paths.
+func writeTestCertificate(t *testing.T) (certFile, keyFile string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "127.0.0.1"},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("failed to marshal key: %v", err)
+	}
+
+	dir := t.TempDir()
+	certFile, keyFile = filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatalf("failed to write certificate: %v", err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatalf("failed to write key: %v", err)
+	}
+	return certFile, keyFile
+}
+
+func TestNewGinServerNegotiatesHTTP2OnlyWhenEnabled(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	certFile, keyFile := writeTestCertificate(t)
+
+	tests := []struct {
+		name         string
+		useHTTP2     bool
+		wantProtocol string
+	}{
+		{"enabled", true, "h2"},
+		{"disabled", false, "http/1.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, err := NewGinServer(&ServerSetupImpl{}, ServerConfig{
+				Host:        "127.0.0.1",
+				UseTLS:      true,
+				TLSCertFile: certFile,
+				TLSKeyFile:  keyFile,
+				UseHTTP2:    tt.useHTTP2,
+			})
+			if err != nil {
+				t.Fatalf("NewGinServer failed: %v", err)
+			}
+			server := srv.(*GinServer)
+			errCh := server.StartAsync()
+			defer func() {
+				server.GracefulShutdown(context.Background(), time.Second)
+				<-errCh
+			}()
+			if err := server.WaitReady(context.Background()); err != nil {
+				t.Fatalf("server did not start: %v", err)
+			}
+
+			conn, err := tls.Dial("tcp", server.Addr().String(), &tls.Config{
+				InsecureSkipVerify: true,
+				NextProtos:         []string{"h2", "http/1.1"},
+			})
+			if err != nil {
+				t.Fatalf("failed to connect: %v", err)
+			}
+			defer conn.Close()
+			if got := conn.ConnectionState().NegotiatedProtocol; got != tt.wantProtocol {
+				t.Errorf("ALPN negotiated %q, want %q", got, tt.wantProtocol)
+			}
+		})
+	}
+}
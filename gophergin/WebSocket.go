@@ -0,0 +1,207 @@
+package gophergin
+
+import (
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+// closeFrameTimeout bounds how long Hub.Close waits to send the close frame to a connection.
+const closeFrameTimeout = time.Second
+
+// WebSocketConn is an upgraded WebSocket connection that may be written to from several goroutines,
+// such as the handler and a Hub broadcast. WriteMessage and WriteJSON are serialized; other write
+// methods of the embedded connection, such as NextWriter, are not and must only be used by the handler.
+type WebSocketConn struct {
+	*websocket.Conn
+	writeMu sync.Mutex
+}
+
+// WriteMessage writes a message of the given type, e.g. websocket.TextMessage.
+func (c *WebSocketConn) WriteMessage(messageType int, data []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return c.Conn.WriteMessage(messageType, data)
+}
+
+// WriteJSON writes v as a JSON text message.
+func (c *WebSocketConn) WriteJSON(v interface{}) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return c.Conn.WriteJSON(v)
+}
+
+// NewWebSocketUpgrader returns an upgrader that accepts WebSocket handshakes from the origins the CORS
+// configuration allows, since browsers do not apply CORS to WebSockets themselves.
+//
+// AllowAllOrigins, AllowOrigins (with "*" patterns when AllowWildcard is set) and AllowOriginFunc are
+// honoured. Requests without an Origin header, which do not come from a browser, are accepted. A
+// configuration that allows no origin at all, such as the zero value, only accepts same-origin handshakes.
+//
+// Parameters:
+// - config: The CORS configuration of the server, e.g. ServerConfig.CORSConfig.
+//
+// Returns:
+// - *websocket.Upgrader: The upgrader, whose buffer sizes and other settings may still be adjusted.
+//
+// Example usage:
+//
+//	upgrader := gophergin.NewWebSocketUpgrader(config.CORSConfig)
+//	router.GET("/ws", gophergin.WebSocket(upgrader, hub, handleChat))
+func NewWebSocketUpgrader(config cors.Config) *websocket.Upgrader {
+	upgrader := &websocket.Upgrader{}
+	if config.AllowAllOrigins || len(config.AllowOrigins) > 0 || config.AllowOriginFunc != nil {
+		upgrader.CheckOrigin = func(r *http.Request) bool {
+			origin := r.Header.Get("Origin")
+			return origin == "" || originAllowed(config, origin)
+		}
+	}
+	return upgrader
+}
+
+// originAllowed reports whether the CORS configuration allows origin.
+func originAllowed(config cors.Config, origin string) bool {
+	if config.AllowAllOrigins {
+		return true
+	}
+	for _, allowed := range config.AllowOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+		if config.AllowWildcard {
+			if prefix, suffix, found := strings.Cut(allowed, "*"); found &&
+				len(origin) >= len(prefix)+len(suffix) && strings.HasPrefix(origin, prefix) && strings.HasSuffix(origin, suffix) {
+				return true
+			}
+		}
+	}
+	return config.AllowOriginFunc != nil && config.AllowOriginFunc(origin)
+}
+
+// WebSocket returns a handler that upgrades the request to a WebSocket and passes the connection to
+// handle. The connection is tracked by hub, if not nil, for as long as handle runs and is closed once
+// it returns. A failed upgrade has already been answered with an HTTP error and is only logged.
+//
+// Parameters:
+// - upgrader: The upgrader, e.g. from NewWebSocketUpgrader.
+// - hub: The Hub to register the connection with for broadcasts and shutdown; nil tracks nothing.
+// - handle: Reads from and writes to the connection until the client leaves.
+//
+// Returns:
+// - gin.HandlerFunc: The WebSocket handler.
+//
+// Example usage:
+//
+//	hub := gophergin.NewHub()
+//	router.GET("/ws", gophergin.WebSocket(upgrader, hub, func(c *gin.Context, conn *gophergin.WebSocketConn) {
+//	    for {
+//	        _, message, err := conn.ReadMessage()
+//	        if err != nil {
+//	            return
+//	        }
+//	        hub.Broadcast(websocket.TextMessage, message)
+//	    }
+//	}))
+func WebSocket(upgrader *websocket.Upgrader, hub *Hub, handle func(c *gin.Context, conn *WebSocketConn)) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ws, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+		if err != nil {
+			log.Printf("WebSocket upgrade failed: %v", err)
+			return
+		}
+		conn := &WebSocketConn{Conn: ws}
+		defer conn.Close()
+
+		if hub != nil {
+			if !hub.register(conn) {
+				return
+			}
+			defer hub.unregister(conn)
+		}
+		handle(c, conn)
+	}
+}
+
+// Hub keeps track of the open WebSocket connections of a server, so messages can be broadcast to all
+// of them and they can be closed cleanly on shutdown. Set ServerConfig.WebSocketHub to have
+// GracefulShutdown close them, as http.Server.Shutdown does not wait for upgraded connections.
+type Hub struct {
+	mu     sync.Mutex
+	conns  map[*WebSocketConn]struct{}
+	closed bool
+}
+
+// NewHub creates an empty Hub.
+func NewHub() *Hub {
+	return &Hub{conns: make(map[*WebSocketConn]struct{})}
+}
+
+// Len returns the number of open connections.
+func (h *Hub) Len() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.conns)
+}
+
+// Broadcast writes the message to every open connection. A connection that fails to receive it is
+// closed, which ends its handler's next read.
+//
+// Parameters:
+// - messageType: The message type, e.g. websocket.TextMessage.
+// - data: The message.
+func (h *Hub) Broadcast(messageType int, data []byte) {
+	for _, conn := range h.snapshot() {
+		if err := conn.WriteMessage(messageType, data); err != nil {
+			conn.Close()
+		}
+	}
+}
+
+// Close sends a "going away" close frame to every open connection and closes it. Connections
+// upgraded afterwards are closed right away.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	h.closed = true
+	h.mu.Unlock()
+
+	closeMessage := websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down")
+	for _, conn := range h.snapshot() {
+		conn.WriteControl(websocket.CloseMessage, closeMessage, time.Now().Add(closeFrameTimeout))
+		conn.Close()
+	}
+}
+
+// register adds conn to the hub, or reports false once the hub is closed.
+func (h *Hub) register(conn *WebSocketConn) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return false
+	}
+	h.conns[conn] = struct{}{}
+	return true
+}
+
+// unregister removes conn from the hub.
+func (h *Hub) unregister(conn *WebSocketConn) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.conns, conn)
+}
+
+// snapshot returns the open connections, so they can be written to without holding the lock.
+func (h *Hub) snapshot() []*WebSocketConn {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	conns := make([]*WebSocketConn, 0, len(h.conns))
+	for conn := range h.conns {
+		conns = append(conns, conn)
+	}
+	return conns
+}
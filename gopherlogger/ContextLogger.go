@@ -0,0 +1,59 @@
+package gopherlogger
+
+import (
+	"context"
+	"log"
+)
+
+// contextKey is the type of the context key under which ContextWithLogger stores a Logger.
+type contextKey struct{}
+
+// globalWriter writes to the current output of the log package's global logger, so a Logger using it
+// follows whatever SetUpLogger or SetUpLoggerFile configured, even if that happens later.
+type globalWriter struct{}
+
+// Write writes p to the global logger's output.
+func (globalWriter) Write(p []byte) (int, error) {
+	return log.Writer().Write(p)
+}
+
+// defaultLogger is returned by LoggerFromContext when the context carries no logger.
+var defaultLogger = NewLogger(globalWriter{}, LevelInfo)
+
+// ContextWithLogger returns a copy of ctx carrying logger, for LoggerFromContext to retrieve further down the call chain.
+//
+// Example usage:
+//
+//	ctx = ContextWithLogger(ctx, logger.With("request_id", requestID))
+//	handleOrder(ctx, order)
+func ContextWithLogger(ctx context.Context, logger *Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, logger)
+}
+
+// LoggerFromContext returns the logger stored in ctx by ContextWithLogger or WithFields.
+// Without one it returns a Logger at LevelInfo writing to the output of the log package,
+// so callers can always log without checking.
+//
+// Example usage:
+//
+//	func handleOrder(ctx context.Context, order Order) {
+//	    LoggerFromContext(ctx).Info("order received", "order_id", order.ID)
+//	}
+func LoggerFromContext(ctx context.Context) *Logger {
+	if logger, ok := ctx.Value(contextKey{}).(*Logger); ok && logger != nil {
+		return logger
+	}
+	return defaultLogger
+}
+
+// WithFields returns a copy of ctx whose logger carries the given key/value pairs on every line,
+// in addition to the fields it already had. It is the context counterpart of Logger.With, used by
+// request middlewares to tag everything logged while handling a request.
+//
+// Example usage:
+//
+//	ctx := WithFields(r.Context(), "request_id", requestID, "user_id", userID)
+//	LoggerFromContext(ctx).Info("payment captured") // ... [INFO] payment captured request_id=... user_id=...
+func WithFields(ctx context.Context, keyvals ...any) context.Context {
+	return ContextWithLogger(ctx, LoggerFromContext(ctx).With(keyvals...))
+}
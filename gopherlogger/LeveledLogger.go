@@ -0,0 +1,340 @@
+package gopherlogger
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Level is the severity of a log entry. Entries below a Logger's level are dropped.
+type Level int32
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+// String returns the tag written in front of entries of this level, e.g. "INFO".
+func (level Level) String() string {
+	switch level {
+	case LevelDebug:
+		return "DEBUG"
+	case LevelInfo:
+		return "INFO"
+	case LevelWarn:
+		return "WARN"
+	case LevelError:
+		return "ERROR"
+	default:
+		return fmt.Sprintf("LEVEL(%d)", int32(level))
+	}
+}
+
+// ParseLevel converts a level name such as "debug" or "WARN" into a Level, e.g. to read it from configuration.
+func ParseLevel(name string) (Level, error) {
+	switch strings.ToUpper(strings.TrimSpace(name)) {
+	case "DEBUG":
+		return LevelDebug, nil
+	case "INFO":
+		return LevelInfo, nil
+	case "WARN", "WARNING":
+		return LevelWarn, nil
+	case "ERROR":
+		return LevelError, nil
+	default:
+		return LevelInfo, fmt.Errorf("unknown log level %q", name)
+	}
+}
+
+// Logger writes leveled log entries, dropping those below its minimum level.
+//
+// In FormatText every entry carries its level tag and optional key/value pairs:
+//
+//	2024/09/20 10:15:04.123456 main.go:42: [WARN] disk almost full free_mb=120
+//
+// In FormatJSON the same entry is written as:
+//
+//	{"timestamp":"2024-09-20T10:15:04.123456Z","level":"WARN","message":"disk almost full","caller":"main.go:42","free_mb":120}
+//
+// A Logger is safe for concurrent use.
+type Logger struct {
+	core   *loggerCore
+	fields []any
+}
+
+// loggerCore is the state shared by a Logger and the children created with With.
+type loggerCore struct {
+	logger  *log.Logger
+	format  Format
+	level   atomic.Int32
+	sampler *sampler
+}
+
+// NewLogger creates a Logger writing to out. The text format uses the same timestamp and file
+// location layout as SetUpLoggerFile.
+//
+// Params:
+//
+//	out - The destination of the entries, e.g. os.Stdout or an io.MultiWriter.
+//	level - The minimum level written; lower entries are dropped.
+//	opts - Optional settings such as WithFormat and WithWriters.
+//
+// Returns:
+//
+//	*Logger - The logger.
+//
+// Example usage:
+//
+//	logger := NewLogger(os.Stdout, LevelWarn)
+//	logger.Info("cache warmed")                  // dropped
+//	logger.Warn("slow request", "path", "/users") // written
+func NewLogger(out io.Writer, level Level, opts ...Option) *Logger {
+	config := newLoggerConfig(opts)
+	return newLogger(append([]io.Writer{out}, config.writers...), level, config)
+}
+
+// newLogger creates a Logger writing to every writer, or discarding its entries when there is none.
+func newLogger(writers []io.Writer, level Level, config loggerConfig) *Logger {
+	out := io.Discard
+	if len(writers) == 1 {
+		out = writers[0]
+	} else if len(writers) > 1 {
+		out = io.MultiWriter(writers...)
+	}
+
+	// The file location is written by output, so that it can also come from a slog record
+	flags := log.Ldate | log.Ltime | log.Lmicroseconds
+	if config.format == FormatJSON {
+		// The JSON entries carry their own timestamp and caller
+		flags = 0
+	}
+
+	core := &loggerCore{
+		logger: log.New(out, "", flags),
+		format: config.format,
+	}
+	if config.sampleFirst > 0 && config.sampleInterval > 0 {
+		core.sampler = newSampler(config.sampleFirst, config.sampleInterval)
+	}
+	core.level.Store(int32(level))
+	return &Logger{core: core}
+}
+
+// NewFileLogger creates a Logger writing to both stdout and a log file in the "logs" directory,
+// named like the files of SetUpLoggerFile. Unlike SetUpLoggerFile it leaves the global logger untouched.
+//
+// WithoutStdout and WithoutFile turn off either output, and WithWriters adds more. WithLogDir,
+// WithoutTimestampPrefix and WithFileMode control where and how the log file is created.
+//
+// Params:
+//
+//	logFileName - The base name for the log file (e.g., "app.log").
+//	level - The minimum level written.
+//	opts - Optional settings such as WithFormat, WithWriters, WithoutStdout, WithoutFile and WithLogDir.
+//
+// Returns:
+//
+//	*Logger - The logger, writing to its other outputs only if the log file could not be opened.
+//	*os.File - The log file, to be closed by the caller, or nil if there is none.
+//	error - An error if the log directory or the log file could not be created.
+//
+// Example usage:
+//
+//	logger, logFile, err := NewFileLogger("app.log", LevelInfo)
+//	if err != nil {
+//	    log.Fatalf("Failed to initialize logger: %v", err)
+//	}
+//	defer logFile.Close()
+func NewFileLogger(logFileName string, level Level, opts ...Option) (*Logger, *os.File, error) {
+	config := newLoggerConfig(opts)
+
+	var writers []io.Writer
+	if !config.noStdout {
+		writers = append(writers, os.Stdout)
+	}
+	writers = append(writers, config.writers...)
+
+	if config.noFile {
+		return newLogger(writers, level, config), nil, nil
+	}
+
+	logFile, err := openLogFile(logFileName, config)
+	if err != nil {
+		return newLogger(writers, level, config), nil, err
+	}
+
+	return newLogger(append(writers, logFile), level, config), logFile, nil
+}
+
+// SetLevel changes the minimum level written, for the logger and every logger derived from it with With.
+func (l *Logger) SetLevel(level Level) {
+	l.core.level.Store(int32(level))
+}
+
+// Level returns the minimum level written.
+func (l *Logger) Level() Level {
+	return Level(l.core.level.Load())
+}
+
+// With returns a child logger that adds the given key/value pairs to every entry, after its own.
+// The child shares the output, format and level of l.
+//
+// Example usage:
+//
+//	requestLogger := logger.With("request_id", requestID, "user_id", userID)
+//	requestLogger.Info("order created", "order_id", orderID)
+func (l *Logger) With(keyvals ...any) *Logger {
+	fields := make([]any, 0, len(l.fields)+len(keyvals))
+	fields = append(fields, l.fields...)
+	fields = append(fields, keyvals...)
+	return &Logger{core: l.core, fields: fields}
+}
+
+// Enabled reports whether entries of the given level are written, to skip building expensive messages.
+func (l *Logger) Enabled(level Level) bool {
+	return level >= l.Level()
+}
+
+// Debug writes a debug entry with optional key/value pairs.
+func (l *Logger) Debug(msg string, keyvals ...any) {
+	l.write(LevelDebug, msg, keyvals)
+}
+
+// Info writes an informational entry with optional key/value pairs.
+func (l *Logger) Info(msg string, keyvals ...any) {
+	l.write(LevelInfo, msg, keyvals)
+}
+
+// Warn writes a warning entry with optional key/value pairs.
+func (l *Logger) Warn(msg string, keyvals ...any) {
+	l.write(LevelWarn, msg, keyvals)
+}
+
+// Error writes an error entry with optional key/value pairs.
+func (l *Logger) Error(msg string, keyvals ...any) {
+	l.write(LevelError, msg, keyvals)
+}
+
+// write outputs one entry attributed to the caller of the level method. It is called by the level
+// methods only, so that caller is two frames up.
+func (l *Logger) write(level Level, msg string, keyvals []any) {
+	l.log(level, msg, caller(2), keyvals)
+}
+
+// log outputs one entry attributed to the "file.go:line" at, if its level is enabled and sampling
+// lets it through.
+func (l *Logger) log(level Level, msg, at string, keyvals []any) {
+	if !l.Enabled(level) {
+		return
+	}
+
+	if l.core.sampler != nil {
+		allowed, summaries := l.core.sampler.allow(level, msg, l.core.sampler.now())
+		for _, summary := range summaries {
+			l.core.output(summary.level, fmt.Sprintf("suppressed %d messages", summary.suppressed), at, []any{"message", summary.msg})
+		}
+		if !allowed {
+			return
+		}
+	}
+
+	if len(l.fields) > 0 {
+		keyvals = append(append([]any(nil), l.fields...), keyvals...)
+	}
+
+	l.core.output(level, msg, at, keyvals)
+}
+
+// output renders and writes one entry attributed to the "file.go:line" at. In FormatText the location
+// follows the timestamp, where log.Lshortfile would put it.
+func (c *loggerCore) output(level Level, msg, at string, keyvals []any) {
+	if c.format == FormatJSON {
+		c.logger.Print(jsonEntry(level, msg, at, keyvals))
+		return
+	}
+
+	var line strings.Builder
+	fmt.Fprintf(&line, "%s: [%s] %s", at, level, msg)
+	writeKeyvals(&line, keyvals)
+	c.logger.Print(line.String())
+}
+
+// caller returns the "file.go:line" of the function calldepth frames above the one calling caller,
+// like log.Lshortfile.
+func caller(calldepth int) string {
+	_, file, line, ok := runtime.Caller(calldepth + 1)
+	if !ok {
+		return "???:0"
+	}
+	return fmt.Sprintf("%s:%d", filepath.Base(file), line)
+}
+
+// writeKeyvals appends key/value pairs as " key=value". A trailing key without a value is written
+// with the value "MISSING" so the mistake shows in the output instead of being lost.
+func writeKeyvals(line *strings.Builder, keyvals []any) {
+	for i := 0; i < len(keyvals); i += 2 {
+		var value any = "MISSING"
+		if i+1 < len(keyvals) {
+			value = keyvals[i+1]
+		}
+
+		text := fmt.Sprint(value)
+		if strings.ContainsAny(text, " \t\n\"=") {
+			text = fmt.Sprintf("%q", text)
+		}
+		fmt.Fprintf(line, " %v=%s", keyvals[i], text)
+	}
+}
+
+// jsonEntry renders an entry as a JSON object, keeping the standard fields first and the
+// key/value pairs in the order they were given.
+func jsonEntry(level Level, msg, caller string, keyvals []any) string {
+	var entry strings.Builder
+	entry.WriteString("{")
+	writeJSONField(&entry, "timestamp", time.Now().UTC().Format(time.RFC3339Nano))
+	entry.WriteString(",")
+	writeJSONField(&entry, "level", level.String())
+	entry.WriteString(",")
+	writeJSONField(&entry, "message", msg)
+	entry.WriteString(",")
+	writeJSONField(&entry, "caller", caller)
+
+	for i := 0; i < len(keyvals); i += 2 {
+		var value any = "MISSING"
+		if i+1 < len(keyvals) {
+			value = keyvals[i+1]
+		}
+		entry.WriteString(",")
+		writeJSONField(&entry, fmt.Sprint(keyvals[i]), value)
+	}
+
+	entry.WriteString("}")
+	return entry.String()
+}
+
+// writeJSONField appends "key":value. Errors are written as their message, and values that cannot
+// be marshalled fall back to their fmt representation.
+func writeJSONField(entry *strings.Builder, key string, value any) {
+	if err, ok := value.(error); ok {
+		value = err.Error()
+	}
+
+	encodedKey, _ := json.Marshal(key)
+	encodedValue, err := json.Marshal(value)
+	if err != nil {
+		encodedValue, _ = json.Marshal(fmt.Sprint(value))
+	}
+
+	entry.Write(encodedKey)
+	entry.WriteString(":")
+	entry.Write(encodedValue)
+}
@@ -0,0 +1,150 @@
+package gopherlogger
+
+import (
+	"io"
+	"os"
+	"time"
+)
+
+// Format selects how a Logger renders its entries.
+type Format string
+
+const (
+	// FormatText writes entries in the standard log format followed by the level tag and key=value pairs.
+	FormatText Format = "text"
+	// FormatJSON writes every entry as a single-line JSON object, ready for ELK or Loki.
+	FormatJSON Format = "json"
+)
+
+// Defaults of the log file options.
+const (
+	// DefaultLogDir is the directory log files are created in.
+	DefaultLogDir = "logs"
+	// DefaultFileMode is the permission mode of new log files, before the umask.
+	DefaultFileMode os.FileMode = 0666
+	// logFileTimestamp is the layout of the date and time prefixed to log file names.
+	logFileTimestamp = "20060102_150405"
+)
+
+// loggerConfig holds the optional settings of NewLogger and NewFileLogger.
+type loggerConfig struct {
+	format   Format
+	writers  []io.Writer
+	noStdout bool
+	noFile   bool
+
+	logDir            string
+	noTimestampPrefix bool
+	fileMode          os.FileMode
+
+	sampleFirst    int
+	sampleInterval time.Duration
+}
+
+// Option configures optional behaviour of a Logger, or of the log file of SetUpLoggerFile and NewStdLogger.
+//
+// Example usage:
+//
+//	logger := NewLogger(os.Stdout, LevelInfo, WithFormat(FormatJSON))
+type Option func(*loggerConfig)
+
+// WithFormat selects the output format, FormatText by default.
+func WithFormat(format Format) Option {
+	return func(c *loggerConfig) {
+		c.format = format
+	}
+}
+
+// WithWriters adds destinations that receive every entry, e.g. a network sink, syslog or a test buffer.
+// They are written to in addition to the outputs of NewLogger and NewFileLogger.
+//
+// Example usage:
+//
+//	var buffer bytes.Buffer
+//	logger, _, err := NewFileLogger("app.log", LevelDebug, WithoutFile(), WithoutStdout(), WithWriters(&buffer))
+func WithWriters(writers ...io.Writer) Option {
+	return func(c *loggerConfig) {
+		c.writers = append(c.writers, writers...)
+	}
+}
+
+// WithoutStdout stops NewFileLogger from writing to stdout.
+func WithoutStdout() Option {
+	return func(c *loggerConfig) {
+		c.noStdout = true
+	}
+}
+
+// WithoutFile stops NewFileLogger from writing to a log file. The log directory is then not created.
+func WithoutFile() Option {
+	return func(c *loggerConfig) {
+		c.noFile = true
+	}
+}
+
+// WithLogDir sets the directory log files are created in, DefaultLogDir by default. Relative paths are
+// resolved against the working directory. Use it to write to a mounted log volume when the working
+// directory is read-only.
+//
+// Example usage:
+//
+//	logFile, err := SetUpLoggerFile("app.log", WithLogDir("/var/log/myapp"))
+func WithLogDir(dir string) Option {
+	return func(c *loggerConfig) {
+		c.logDir = dir
+	}
+}
+
+// WithoutTimestampPrefix names the log file exactly logFileName instead of prefixing it with the date and
+// time, e.g. "app.log" instead of "20240920_101504_app.log". Entries are appended when the file exists.
+func WithoutTimestampPrefix() Option {
+	return func(c *loggerConfig) {
+		c.noTimestampPrefix = true
+	}
+}
+
+// WithFileMode sets the permission mode of new log files, DefaultFileMode by default.
+// It does not change the mode of an existing file.
+//
+// Example usage:
+//
+//	logger, logFile, err := NewFileLogger("audit.log", LevelInfo, WithFileMode(0600))
+func WithFileMode(mode os.FileMode) Option {
+	return func(c *loggerConfig) {
+		c.fileMode = mode
+	}
+}
+
+// WithSampling writes only the first entries with the same message in every interval and drops
+// the rest, so a hot error path cannot drown the other entries or fill the disk.
+//
+// Entries are keyed on their message, not on their key/value pairs, so messages should be constant
+// templates with the details in the pairs. When an interval with dropped entries is over, an entry
+// like "suppressed 998 messages message=..." is written at the level of the dropped ones, together
+// with the next entry of the logger. Sampling is off when first or interval is not positive.
+//
+// Example usage:
+//
+//	logger := NewLogger(os.Stdout, LevelInfo, WithSampling(10, time.Second))
+//	for _, err := range errs {
+//	    logger.Error("write failed", "error", err) // at most 10 per second
+//	}
+func WithSampling(first int, interval time.Duration) Option {
+	return func(c *loggerConfig) {
+		c.sampleFirst = first
+		c.sampleInterval = interval
+	}
+}
+
+// newLoggerConfig applies the given options over the defaults.
+func newLoggerConfig(opts []Option) loggerConfig {
+	config := loggerConfig{
+		format:   FormatText,
+		logDir:   DefaultLogDir,
+		fileMode: DefaultFileMode,
+	}
+	for _, opt := range opts {
+		opt(&config)
+	}
+	return config
+}
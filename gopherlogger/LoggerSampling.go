@@ -0,0 +1,94 @@
+package gopherlogger
+
+import (
+	"sync"
+	"time"
+)
+
+// sampler limits how often entries with the same message are written, to keep a hot error path
+// from flooding the output.
+type sampler struct {
+	first     int
+	interval  time.Duration
+	mu        sync.Mutex
+	entries   map[string]*sampleEntry
+	lastSweep time.Time
+
+	// now is the clock entries are sampled by, replaced in tests
+	now func() time.Time
+}
+
+// sampleEntry counts the entries with one message during the current interval.
+type sampleEntry struct {
+	level      Level
+	start      time.Time
+	count      int
+	suppressed int
+}
+
+// suppressedSummary reports how many entries with a message were dropped during an interval.
+type suppressedSummary struct {
+	level      Level
+	msg        string
+	suppressed int
+}
+
+// newSampler creates a sampler writing the first entries of each message per interval.
+func newSampler(first int, interval time.Duration) *sampler {
+	return &sampler{
+		first:    first,
+		interval: interval,
+		entries:  make(map[string]*sampleEntry),
+		now:      time.Now,
+	}
+}
+
+// allow reports whether an entry with msg is written, and returns the summaries of the intervals
+// that ended since the last call, to be written before it. An entry arriving after its message's
+// interval ended starts a new interval, whether or not a sweep has run since.
+func (s *sampler) allow(level Level, msg string, now time.Time) (bool, []suppressedSummary) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	summaries := s.sweep(now)
+
+	entry, ok := s.entries[msg]
+	if ok && now.Sub(entry.start) >= s.interval {
+		if entry.suppressed > 0 {
+			summaries = append(summaries, suppressedSummary{level: entry.level, msg: msg, suppressed: entry.suppressed})
+		}
+		ok = false
+	}
+	if !ok {
+		entry = &sampleEntry{start: now}
+		s.entries[msg] = entry
+	}
+	entry.level = level
+	entry.count++
+	if entry.count <= s.first {
+		return true, summaries
+	}
+	entry.suppressed++
+	return false, summaries
+}
+
+// sweep ends the intervals that are over, at most once per interval, so that messages which are not
+// logged again do not hold memory, and returns a summary for every message that had entries dropped.
+func (s *sampler) sweep(now time.Time) []suppressedSummary {
+	if now.Sub(s.lastSweep) < s.interval {
+		return nil
+	}
+	s.lastSweep = now
+
+	var summaries []suppressedSummary
+	for msg, entry := range s.entries {
+		if now.Sub(entry.start) < s.interval {
+			continue
+		}
+		if entry.suppressed > 0 {
+			summaries = append(summaries, suppressedSummary{level: entry.level, msg: msg, suppressed: entry.suppressed})
+		}
+		delete(s.entries, msg)
+	}
+	return summaries
+}
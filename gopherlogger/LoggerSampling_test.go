@@ -0,0 +1,113 @@
+package gopherlogger
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSamplerBurst(t *testing.T) {
+	s := newSampler(3, time.Second)
+	start := time.Unix(1700000000, 0)
+
+	allowed := 0
+	for i := 0; i < 1000; i++ {
+		ok, summaries := s.allow(LevelError, "write failed", start.Add(time.Duration(i)*time.Millisecond/2))
+		if len(summaries) > 0 {
+			t.Fatalf("summary %+v written during the first interval", summaries)
+		}
+		if ok {
+			allowed++
+		}
+	}
+	if allowed != 3 {
+		t.Fatalf("%d entries of the burst allowed, want 3", allowed)
+	}
+
+	// Other messages are sampled on their own
+	if ok, _ := s.allow(LevelInfo, "request served", start.Add(time.Millisecond)); !ok {
+		t.Fatal("first entry of another message was dropped")
+	}
+
+	// The next entry after the interval reports the dropped ones and starts a new interval
+	ok, summaries := s.allow(LevelError, "write failed", start.Add(time.Second))
+	if !ok {
+		t.Fatal("first entry of the next interval was dropped")
+	}
+	want := []suppressedSummary{{level: LevelError, msg: "write failed", suppressed: 997}}
+	if len(summaries) != 1 || summaries[0] != want[0] {
+		t.Fatalf("summaries %+v, want %+v", summaries, want)
+	}
+}
+
+func TestSamplerStartsNewIntervalBetweenSweeps(t *testing.T) {
+	s := newSampler(1, time.Second)
+	start := time.Unix(1700000000, 0)
+
+	// The first call sweeps, so the next sweep is due a second later
+	s.allow(LevelInfo, "request served", start)
+	s.allow(LevelError, "write failed", start.Add(500*time.Millisecond))
+	s.allow(LevelError, "write failed", start.Add(600*time.Millisecond))
+
+	// The sweep at one second leaves "write failed" alone as its interval runs until 1.5s
+	s.allow(LevelInfo, "request served", start.Add(time.Second))
+
+	// Its interval has ended by 1.6s although the next sweep is only due at 2s
+	ok, summaries := s.allow(LevelError, "write failed", start.Add(1600*time.Millisecond))
+	if !ok {
+		t.Fatal("first entry after the interval ended was dropped")
+	}
+	want := suppressedSummary{level: LevelError, msg: "write failed", suppressed: 1}
+	if len(summaries) != 1 || summaries[0] != want {
+		t.Fatalf("summaries %+v, want [%+v]", summaries, want)
+	}
+}
+
+// fakeClock is a clock for the sampler that only moves when advanced.
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+func TestLoggerSamplingWritesSubsetAndSummary(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(&buf, LevelInfo, WithSampling(3, time.Minute))
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	logger.core.sampler.now = clock.Now
+
+	for i := 0; i < 100; i++ {
+		logger.Error("write failed", "attempt", i)
+		clock.Advance(100 * time.Millisecond)
+	}
+	logger.Info("still running")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("burst wrote %d lines, want 3 sampled entries and 1 other:\n%s", len(lines), buf.String())
+	}
+	for i, line := range lines[:3] {
+		if !strings.Contains(line, "write failed") || !strings.Contains(line, "attempt="+strconv.Itoa(i)) {
+			t.Errorf("line %d is %q, want sampled entry %d", i, line, i)
+		}
+	}
+
+	clock.Advance(time.Minute)
+	buf.Reset()
+	logger.Error("write failed", "attempt", 100)
+
+	lines = strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("after the interval wrote %d lines, want a summary and the entry:\n%s", len(lines), buf.String())
+	}
+	if !strings.Contains(lines[0], "[ERROR] suppressed 97 messages") || !strings.Contains(lines[0], "write failed") {
+		t.Errorf("summary line is %q, want it to report 97 suppressed \"write failed\" entries", lines[0])
+	}
+	if !strings.Contains(lines[1], "attempt=100") {
+		t.Errorf("entry line is %q, want attempt=100", lines[1])
+	}
+}
@@ -0,0 +1,135 @@
+package gopherlogger
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"runtime"
+)
+
+// SlogHandler is a slog.Handler writing records through a Logger, so code and libraries using
+// log/slog share its outputs, format, level and sampling.
+//
+// Levels are mapped onto the four Logger levels: anything below slog.LevelInfo is written as
+// LevelDebug, anything from slog.LevelError up as LevelError. Attributes become key/value pairs,
+// with the names of enclosing groups joined by dots, e.g. "request.method". The entry is timestamped
+// by the Logger when it is written and attributed to the file location recorded by slog.
+type SlogHandler struct {
+	logger *Logger
+	prefix string
+	attrs  []any
+}
+
+// NewSlogHandler creates a slog.Handler writing through logger. The Logger's fields set with With
+// come first on every entry.
+//
+// Params:
+//
+//	logger - The Logger to write to.
+//
+// Returns:
+//
+//	*SlogHandler - The handler, for slog.New.
+//
+// Example usage:
+//
+//	logger, logFile, err := NewFileLogger("app.log", LevelInfo)
+//	if err != nil {
+//	    log.Fatalf("Failed to initialize logger: %v", err)
+//	}
+//	defer logFile.Close()
+//
+//	slog.SetDefault(slog.New(NewSlogHandler(logger)))
+//	slog.Info("server started", "port", 8080)
+func NewSlogHandler(logger *Logger) *SlogHandler {
+	return &SlogHandler{logger: logger}
+}
+
+// Enabled reports whether the Logger writes entries of the given level.
+func (h *SlogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.logger.Enabled(levelFromSlog(level))
+}
+
+// Handle writes the record through the Logger.
+func (h *SlogHandler) Handle(_ context.Context, record slog.Record) error {
+	keyvals := make([]any, 0, len(h.attrs)+2*record.NumAttrs())
+	keyvals = append(keyvals, h.attrs...)
+	record.Attrs(func(attr slog.Attr) bool {
+		keyvals = appendAttr(keyvals, h.prefix, attr)
+		return true
+	})
+
+	h.logger.log(levelFromSlog(record.Level), record.Message, recordCaller(record), keyvals)
+	return nil
+}
+
+// WithAttrs returns a handler adding attrs to every entry, inside the groups opened so far.
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+
+	child := *h
+	child.attrs = append([]any(nil), h.attrs...)
+	for _, attr := range attrs {
+		child.attrs = appendAttr(child.attrs, h.prefix, attr)
+	}
+	return &child
+}
+
+// WithGroup returns a handler qualifying the keys of attributes added later with name.
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	child := *h
+	child.prefix = h.prefix + name + "."
+	return &child
+}
+
+// appendAttr appends attr as key/value pairs with its key qualified by prefix, flattening groups.
+// Empty attributes are skipped, and the attributes of a group without a key are inlined, as
+// slog.Handler requires.
+func appendAttr(keyvals []any, prefix string, attr slog.Attr) []any {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return keyvals
+	}
+
+	if attr.Value.Kind() == slog.KindGroup {
+		if attr.Key != "" {
+			prefix += attr.Key + "."
+		}
+		for _, member := range attr.Value.Group() {
+			keyvals = appendAttr(keyvals, prefix, member)
+		}
+		return keyvals
+	}
+
+	return append(keyvals, prefix+attr.Key, attr.Value.Any())
+}
+
+// levelFromSlog maps a slog level onto the nearest Logger level at or below it.
+func levelFromSlog(level slog.Level) Level {
+	switch {
+	case level < slog.LevelInfo:
+		return LevelDebug
+	case level < slog.LevelWarn:
+		return LevelInfo
+	case level < slog.LevelError:
+		return LevelWarn
+	default:
+		return LevelError
+	}
+}
+
+// recordCaller returns the "file.go:line" the record was logged from, or "???:0" when slog did not record it.
+func recordCaller(record slog.Record) string {
+	if record.PC == 0 {
+		return "???:0"
+	}
+	frame, _ := runtime.CallersFrames([]uintptr{record.PC}).Next()
+	return fmt.Sprintf("%s:%d", filepath.Base(frame.File), frame.Line)
+}
@@ -16,9 +16,13 @@ import (
 // back to logging to stdout. It configures the log format to include timestamps, log levels,
 // and the file location of the log entry. The logs are written to both the log file and stdout.
 //
+// The directory, the timestamp prefix and the file mode can be changed with WithLogDir,
+// WithoutTimestampPrefix and WithFileMode.
+//
 // Params:
 //
 //	logFileName - The base name for the log file (e.g., "app.log").
+//	opts - Optional log file settings: WithLogDir, WithoutTimestampPrefix and WithFileMode.
 //
 // Returns:
 //
@@ -34,31 +38,80 @@ import (
 //	defer logFile.Close()
 //
 // In this example, the logger writes to both stdout and a file named with the current timestamp.
-func SetUpLoggerFile(logFileName string) (*os.File, error) {
-	// Ensure the logs directory exists
-	if err := os.MkdirAll("logs", 0755); err != nil {
-		return nil, fmt.Errorf("failed to create logs directory: %w", err)
+//
+// SetUpLoggerFile reconfigures the global logger of the log package, which every package logging through
+// it shares. Use NewStdLogger or NewFileLogger for a logger that owns its output.
+func SetUpLoggerFile(logFileName string, opts ...Option) (*os.File, error) {
+	logger, logFile, err := NewStdLogger(logFileName, opts...)
+	if err != nil {
+		log.Printf("Error opening log file, using stdout: %v", err)
+		log.SetOutput(os.Stdout)
+		return nil, err
 	}
 
-	// Get the current date and time for the log file prefix
-	currentTime := time.Now().Format("20060102_150405")
-	logFilePath := filepath.Join("logs", fmt.Sprintf("%s_%s", currentTime, logFileName))
+	// Install the logger's output and format on the global logger
+	log.SetOutput(logger.Writer())
+	log.SetFlags(logger.Flags())
 
-	// Open the log file
-	logFile, err := os.OpenFile(logFilePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0666)
+	log.Printf("Logging initialized. Log file: %s", logFile.Name())
+	return logFile, nil
+}
+
+// NewStdLogger creates a standard library logger writing to both stdout and a timestamped log file,
+// like SetUpLoggerFile, but without touching the global logger of the log package.
+//
+// Each call returns an independent logger, so several components of one process, or several tests,
+// can log to their own files without overriding each other's output.
+//
+// Params:
+//
+//	logFileName - The base name for the log file (e.g., "app.log").
+//	opts - Optional log file settings: WithLogDir, WithoutTimestampPrefix and WithFileMode.
+//
+// Returns:
+//
+//	*log.Logger - The logger, or nil if the log file could not be opened.
+//	*os.File    - The log file, to be closed by the caller once logging is done.
+//	error       - An error message if the log directory or the log file could not be created.
+//
+// Example usage:
+//
+//	logger, logFile, err := NewStdLogger("worker.log")
+//	if err != nil {
+//	    log.Fatalf("Failed to initialize logger: %v", err)
+//	}
+//	defer logFile.Close()
+//
+//	logger.Println("worker started")
+func NewStdLogger(logFileName string, opts ...Option) (*log.Logger, *os.File, error) {
+	logFile, err := openLogFile(logFileName, newLoggerConfig(opts))
 	if err != nil {
-		log.Printf("Error opening log file %s, using stdout: %v", logFilePath, err)
-		log.SetOutput(os.Stdout)
-		return nil, fmt.Errorf("failed to open log file: %w", err)
+		return nil, nil, err
 	}
 
-	// Set up multi-writer to write to both stdout and log file
-	multiWriter := io.MultiWriter(os.Stdout, logFile)
+	// Write to both stdout and the log file, with timestamps and the file location of each entry
+	logger := log.New(io.MultiWriter(os.Stdout, logFile), "", log.Ldate|log.Ltime|log.Lmicroseconds|log.Lshortfile)
+	return logger, logFile, nil
+}
+
+// openLogFile creates the log directory if needed and opens logFileName in it for appending,
+// prefixed by the current date and time unless the prefix is turned off.
+func openLogFile(logFileName string, config loggerConfig) (*os.File, error) {
+	// Ensure the log directory exists
+	if err := os.MkdirAll(config.logDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create log directory %s: %w", config.logDir, err)
+	}
 
-	// Configure log format to include timestamp, log level, and file location
-	log.SetOutput(multiWriter)
-	log.SetFlags(log.Ldate | log.Ltime | log.Lmicroseconds | log.Lshortfile)
+	// Prefix the file name with the current date and time
+	if !config.noTimestampPrefix {
+		logFileName = fmt.Sprintf("%s_%s", time.Now().Format(logFileTimestamp), logFileName)
+	}
+	logFilePath := filepath.Join(config.logDir, logFileName)
 
-	log.Printf("Logging initialized. Log file: %s", logFilePath)
+	// Open the log file
+	logFile, err := os.OpenFile(logFilePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, config.fileMode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open log file %s: %w", logFilePath, err)
+	}
 	return logFile, nil
 }
@@ -0,0 +1,141 @@
+package gophermongo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ErrNotFound is returned, wrapped, by FindByID, UpdateByID and DeleteByID when no document has the given ID.
+//
+// Example usage:
+//
+//	user, err := FindByID[User](ctx, users, id)
+//	if errors.Is(err, ErrNotFound) {
+//	    http.Error(w, "user not found", http.StatusNotFound)
+//	}
+var ErrNotFound = errors.New("document not found")
+
+// InsertOne inserts doc into the collection.
+//
+// Params:
+//
+//	ctx - The context for cancelling the insert.
+//	coll - The collection to insert into, e.g. one returned by GetCollection.
+//	doc - The document, typically a struct with bson tags.
+//
+// Returns:
+//
+//	interface{} - The _id of the document, generated by the driver as a primitive.ObjectID when doc has none.
+//	error - An error if the document cannot be encoded or the insert fails, e.g. on a duplicate key.
+//
+// Example usage:
+//
+//	type User struct {
+//	    ID    primitive.ObjectID `bson:"_id,omitempty"`
+//	    Email string             `bson:"email"`
+//	}
+//
+//	id, err := InsertOne(ctx, users, User{Email: "ada@example.com"})
+//	if err != nil {
+//	    log.Fatalf("Failed to insert user: %v", err)
+//	}
+func InsertOne[T any](ctx context.Context, coll *mongo.Collection, doc T) (interface{}, error) {
+	result, err := coll.InsertOne(ctx, doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert document into %s: %w", coll.Name(), err)
+	}
+	return result.InsertedID, nil
+}
+
+// FindByID returns the document with the given _id, decoded into a T.
+//
+// Params:
+//
+//	ctx - The context for cancelling the query.
+//	coll - The collection to search.
+//	id - The _id of the document, of the type it is stored with, e.g. a primitive.ObjectID.
+//
+// Returns:
+//
+//	*T - The decoded document.
+//	error - An error wrapping ErrNotFound if there is no such document, or an error if the query or decoding fails.
+//
+// Example usage:
+//
+//	user, err := FindByID[User](ctx, users, id)
+//	if err != nil {
+//	    log.Fatalf("Failed to find user: %v", err)
+//	}
+func FindByID[T any](ctx context.Context, coll *mongo.Collection, id interface{}) (*T, error) {
+	var doc T
+	if err := coll.FindOne(ctx, bson.D{{Key: "_id", Value: id}}).Decode(&doc); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, fmt.Errorf("failed to find document %v in %s: %w", id, coll.Name(), ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to find document %v in %s: %w", id, coll.Name(), err)
+	}
+	return &doc, nil
+}
+
+// UpdateByID sets the fields of doc on the document with the given _id. Fields doc leaves out, e.g.
+// through omitempty tags, keep their stored value, and an _id in doc must match id.
+//
+// Params:
+//
+//	ctx - The context for cancelling the update.
+//	coll - The collection holding the document.
+//	id - The _id of the document.
+//	doc - The fields to set, typically the same struct type the document was inserted with.
+//
+// Returns:
+//
+//	error - An error wrapping ErrNotFound if there is no such document, or an error if the update fails.
+//
+// Example usage:
+//
+//	user.Email = "ada@lovelace.dev"
+//	if err := UpdateByID(ctx, users, user.ID, user); err != nil {
+//	    log.Fatalf("Failed to update user: %v", err)
+//	}
+func UpdateByID[T any](ctx context.Context, coll *mongo.Collection, id interface{}, doc T) error {
+	result, err := coll.UpdateByID(ctx, id, bson.D{{Key: "$set", Value: doc}})
+	if err != nil {
+		return fmt.Errorf("failed to update document %v in %s: %w", id, coll.Name(), err)
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("failed to update document %v in %s: %w", id, coll.Name(), ErrNotFound)
+	}
+	return nil
+}
+
+// DeleteByID deletes the document with the given _id.
+//
+// Params:
+//
+//	ctx - The context for cancelling the delete.
+//	coll - The collection holding the document.
+//	id - The _id of the document.
+//
+// Returns:
+//
+//	error - An error wrapping ErrNotFound if there is no such document, or an error if the delete fails.
+//
+// Example usage:
+//
+//	if err := DeleteByID(ctx, users, id); err != nil && !errors.Is(err, ErrNotFound) {
+//	    log.Fatalf("Failed to delete user: %v", err)
+//	}
+func DeleteByID(ctx context.Context, coll *mongo.Collection, id interface{}) error {
+	result, err := coll.DeleteOne(ctx, bson.D{{Key: "_id", Value: id}})
+	if err != nil {
+		return fmt.Errorf("failed to delete document %v from %s: %w", id, coll.Name(), err)
+	}
+	if result.DeletedCount == 0 {
+		return fmt.Errorf("failed to delete document %v from %s: %w", id, coll.Name(), ErrNotFound)
+	}
+	return nil
+}
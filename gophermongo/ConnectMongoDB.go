@@ -7,14 +7,19 @@ import (
 	"time"
 
 	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 // ConnectToMongoDB establishes a connection to MongoDB with retries and a context timeout.
 //
 // This function attempts to connect to MongoDB using the provided connection string (DSN),
 // retrying the connection up to 'maxRetries' times with a delay of 5 seconds between retries.
-// It also applies a timeout to the entire connection attempt using the context.
+// It also applies a timeout to the entire connection attempt using the context. Each attempt is
+// verified with a ping, which WithSkipPing disables and WithVerifyCommand replaces. Use
+// ConnectToMongoDatabase to get a handle on a database along with the client.
+//
+// Failures are handled as in the Postgres connectors: a client that connected but failed verification
+// is disconnected before the next attempt so it does not leak, the wait between attempts ends as soon
+// as ctx does, and once the attempts are used up the error is returned rather than exiting the process.
 //
 // Params:
 //
@@ -22,6 +27,7 @@ import (
 //	dsn - The MongoDB connection string (Data Source Name).
 //	timeout - The timeout duration for the connection attempt.
 //	maxRetries - The maximum number of retries before giving up.
+//	opts - Optional settings such as WithClientOptions, WithMaxPoolSize, WithReadPreference, WithOnRetry and WithSkipPing.
 //
 // Returns:
 //
@@ -35,8 +41,10 @@ import (
 //	if err != nil {
 //	    log.Fatalf("Failed to connect to MongoDB: %v", err)
 //	}
-//	defer client.Disconnect(ctx)
-func ConnectToMongoDB(ctx context.Context, dsn string, timeout time.Duration, maxRetries int) (*mongo.Client, error) {
+//	defer DisconnectWithTimeout(context.Background(), client, DefaultDisconnectTimeout)
+func ConnectToMongoDB(ctx context.Context, dsn string, timeout time.Duration, maxRetries int, opts ...Option) (*mongo.Client, error) {
+	connectOpts := newConnectOptions(opts)
+
 	// Set a timeout for the connection operation using the context
 	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
@@ -59,12 +67,13 @@ func ConnectToMongoDB(ctx context.Context, dsn string, timeout time.Duration, ma
 		default:
 			// Try to establish a connection to MongoDB
 			log.Printf("Attempting to connect to MongoDB... (Attempt %d of %d)", i+1, maxRetries)
-			client, err = mongo.Connect(ctx, options.Client().ApplyURI(dsn))
+			client, err = mongo.Connect(ctx, connectOpts.clientOptionsFor(dsn)...)
 			if err == nil {
-				// Successfully connected, verify the connection
-				if err = client.Ping(ctx, nil); err != nil {
-					// If ping fails, log the error and prepare to retry
-					log.Printf("Ping to MongoDB failed: %v", err)
+				// Successfully connected, verify the connection unless disabled WithSkipPing
+				if err = connectOpts.verify(ctx, client); err != nil {
+					// If verification fails, log the error, release the client and prepare to retry
+					log.Printf("Verifying the MongoDB connection failed: %v", err)
+					client.Disconnect(context.Background())
 				} else {
 					// Connection is successful
 					log.Println("Connected to MongoDB successfully")
@@ -72,15 +81,23 @@ func ConnectToMongoDB(ctx context.Context, dsn string, timeout time.Duration, ma
 				}
 			}
 
-			// Log the failure and retry after a delay
-			log.Printf("Connection attempt %d failed: %v\n", i+1, err)
-			log.Printf("Retrying connection in %v seconds...", retryDelay.Seconds())
-			time.Sleep(retryDelay) // Wait before the next retry
+			// Report the failure and retry after a delay
+			connectOpts.reportFailure(i+1, err)
+
+			// Wait before the next retry, giving up early if the context ends
+			if i < maxRetries-1 {
+				log.Printf("Retrying connection in %v seconds...", retryDelay.Seconds())
+				select {
+				case <-ctx.Done():
+					return nil, fmt.Errorf("context timed out while trying to connect to MongoDB: %w", ctx.Err())
+				case <-time.After(retryDelay):
+				}
+			}
 		}
 	}
 
-	// Log final failure before exiting
-	log.Fatalf("Failed to connect to MongoDB after %d attempts: %v", maxRetries, err)
+	// Log the final failure and leave it to the caller to decide whether to exit
+	log.Printf("Failed to connect to MongoDB after %d attempts: %v", maxRetries, err)
 	return nil, fmt.Errorf("failed to connect to MongoDB after %d retries: %w", maxRetries, err)
 }
 
@@ -98,10 +115,10 @@ func ConnectToMongoDB(ctx context.Context, dsn string, timeout time.Duration, ma
 // 	ctx := context.Background()
 // 	client, err := gophermongo.ConnectToMongoDB(ctx, "mongodb://localhost:27017", 10*time.Second, 3)
 // 	if err != nil {
-// 		// This log will not be hit because ConnectToMongoDB exits the application on failure.
+// 		// ConnectToMongoDB returns the error instead of exiting, so the caller decides how to fail.
 // 		log.Fatalf("Unable to continue: %v", err)
 // 	}
-// 	defer client.Disconnect(ctx)
+// 	defer gophermongo.DisconnectWithTimeout(context.Background(), client, gophermongo.DefaultDisconnectTimeout)
 
 // 	// Continue with your application logic...
 // }
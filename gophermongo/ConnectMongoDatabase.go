@@ -0,0 +1,67 @@
+package gophermongo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/connstring"
+)
+
+// ConnectToMongoDatabase connects to MongoDB like ConnectToMongoDB and also returns the named database.
+//
+// It is the single entry point for callers that want a *mongo.Database rather than a bare client,
+// so connecting, retrying and logging behave exactly as in ConnectToMongoDB.
+//
+// Params:
+//
+//	ctx - The context for connection management (with timeout support).
+//	dsn - The MongoDB connection string (Data Source Name).
+//	dbName - The name of the database to return. When empty, the database named in the DSN path is used.
+//	timeout - The timeout duration for the connection attempt.
+//	maxRetries - The maximum number of retries before giving up.
+//	opts - Optional settings, as for ConnectToMongoDB.
+//
+// Returns:
+//
+//	*mongo.Client - The connected MongoDB client, needed to disconnect.
+//	*mongo.Database - The database named dbName.
+//	error - An error if no database name is given or the connection fails.
+//
+// Example usage:
+//
+//	client, database, err := ConnectToMongoDatabase(ctx, "mongodb://localhost:27017/shop", "", 10*time.Second, 3)
+//	if err != nil {
+//	    log.Fatalf("Failed to connect to MongoDB: %v", err)
+//	}
+//	defer DisconnectWithTimeout(context.Background(), client, DefaultDisconnectTimeout)
+//
+//	orders := GetCollection(database, "orders")
+func ConnectToMongoDatabase(ctx context.Context, dsn, dbName string, timeout time.Duration, maxRetries int, opts ...Option) (*mongo.Client, *mongo.Database, error) {
+	if dbName == "" {
+		name, err := databaseFromDSN(dsn)
+		if err != nil {
+			return nil, nil, err
+		}
+		dbName = name
+	}
+
+	client, err := ConnectToMongoDB(ctx, dsn, timeout, maxRetries, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return client, GetDatabase(client, dbName), nil
+}
+
+// databaseFromDSN returns the database named in the path of a MongoDB connection string.
+func databaseFromDSN(dsn string) (string, error) {
+	connString, err := connstring.ParseAndValidate(dsn)
+	if err != nil {
+		return "", fmt.Errorf("invalid MongoDB connection string: %w", err)
+	}
+	if connString.Database == "" {
+		return "", fmt.Errorf("missing database name: pass dbName or include it in the connection string")
+	}
+	return connString.Database, nil
+}
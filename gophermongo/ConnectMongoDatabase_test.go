@@ -0,0 +1,66 @@
+package gophermongo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"go.mongodb.org/mongo-driver/mongo/writeconcern"
+)
+
+func TestConnectEntryPointsProduceEquivalentClients(t *testing.T) {
+	// The driver connects lazily, so without the ping no server is needed
+	dsn := "mongodb://127.0.0.1:1/shop"
+	opts := []Option{WithSkipPing(), WithReadPreference(readpref.Secondary()), WithWriteConcern(writeconcern.Majority())}
+	ctx := context.Background()
+
+	client, err := ConnectToMongoDB(ctx, dsn, time.Second, 1, opts...)
+	if err != nil {
+		t.Fatalf("ConnectToMongoDB failed: %v", err)
+	}
+	defer DisconnectWithTimeout(ctx, client, DefaultDisconnectTimeout)
+	viaClient := GetDatabase(client, "shop")
+
+	tests := []struct {
+		name   string
+		dbName string
+		want   string
+	}{
+		{"database from the DSN", "", "shop"},
+		{"database given", "billing", "billing"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			otherClient, database, err := ConnectToMongoDatabase(ctx, dsn, tt.dbName, time.Second, 1, opts...)
+			if err != nil {
+				t.Fatalf("ConnectToMongoDatabase failed: %v", err)
+			}
+			defer DisconnectWithTimeout(ctx, otherClient, DefaultDisconnectTimeout)
+
+			if database.Name() != tt.want {
+				t.Errorf("database is %q, want %q", database.Name(), tt.want)
+			}
+			if database.Client() != otherClient {
+				t.Error("database does not belong to the returned client")
+			}
+			if got, want := database.ReadPreference().Mode(), viaClient.ReadPreference().Mode(); got != want {
+				t.Errorf("read preference is %v, want %v as from ConnectToMongoDB", got, want)
+			}
+			if got, want := database.WriteConcern().W, viaClient.WriteConcern().W; got != want {
+				t.Errorf("write concern is w=%v, want w=%v as from ConnectToMongoDB", got, want)
+			}
+		})
+	}
+}
+
+func TestConnectToMongoDatabaseRequiresDatabaseName(t *testing.T) {
+	client, database, err := ConnectToMongoDatabase(context.Background(), "mongodb://127.0.0.1:1", "", time.Second, 1, WithSkipPing())
+	if err == nil {
+		DisconnectWithTimeout(context.Background(), client, DefaultDisconnectTimeout)
+		t.Fatalf("ConnectToMongoDatabase without a database name returned %v", database.Name())
+	}
+	if client != nil || database != nil {
+		t.Error("ConnectToMongoDatabase returned a client along with the error")
+	}
+}
@@ -0,0 +1,45 @@
+package gophermongo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// DefaultDisconnectTimeout is a sensible bound for DisconnectWithTimeout.
+const DefaultDisconnectTimeout = 10 * time.Second
+
+// DisconnectWithTimeout closes the client's connections, waiting at most timeout for in-use
+// connections to be returned to the pool.
+//
+// The timeout is applied on top of ctx, so the function can be deferred with a background context
+// right after connecting, the way a *sql.DB is closed. Once it returns, operations on the client fail.
+//
+// Params:
+//
+//	ctx - The parent context of the disconnect.
+//	client - The MongoDB client to disconnect.
+//	timeout - The maximum time the disconnect may take.
+//
+// Returns:
+//
+//	error - An error if the disconnect fails or does not finish in time.
+//
+// Example usage:
+//
+//	client, err := ConnectToMongoDB(ctx, "mongodb://localhost:27017", 10*time.Second, 3)
+//	if err != nil {
+//	    log.Fatalf("Failed to connect to MongoDB: %v", err)
+//	}
+//	defer DisconnectWithTimeout(context.Background(), client, DefaultDisconnectTimeout)
+func DisconnectWithTimeout(ctx context.Context, client *mongo.Client, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	if err := client.Disconnect(ctx); err != nil {
+		return fmt.Errorf("failed to disconnect from MongoDB: %w", err)
+	}
+	return nil
+}
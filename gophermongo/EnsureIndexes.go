@@ -0,0 +1,75 @@
+package gophermongo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// EnsureIndexes creates the given indexes on the collection if they do not exist yet.
+//
+// MongoDB treats creating an index identical to an existing one as a no-op, so this function is
+// safe to call on every start. Creating an index whose name matches an existing index with a
+// different definition fails.
+//
+// Params:
+//
+//	ctx - The context for cancelling the index creation.
+//	coll - The collection to index, e.g. one returned by GetCollection.
+//	models - The indexes to ensure, e.g. built with UniqueIndex and TTLIndex.
+//
+// Returns:
+//
+//	[]string - The names of the indexes, whether they were just created or already existed.
+//	error - An error if an index cannot be created.
+//
+// Example usage:
+//
+//	users := GetCollection(database, "users")
+//	names, err := EnsureIndexes(ctx, users, []mongo.IndexModel{
+//	    UniqueIndex("email"),
+//	    TTLIndex("expiresAt", 0),
+//	})
+//	if err != nil {
+//	    log.Fatalf("Failed to create indexes: %v", err)
+//	}
+func EnsureIndexes(ctx context.Context, coll *mongo.Collection, models []mongo.IndexModel) ([]string, error) {
+	if len(models) == 0 {
+		return nil, nil
+	}
+
+	names, err := coll.Indexes().CreateMany(ctx, models)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create indexes on %s: %w", coll.Name(), err)
+	}
+	return names, nil
+}
+
+// UniqueIndex returns an ascending index over the given fields that rejects duplicate values.
+//
+// Example usage:
+//
+//	UniqueIndex("tenantId", "email") // unique per tenant
+func UniqueIndex(fields ...string) mongo.IndexModel {
+	keys := bson.D{}
+	for _, field := range fields {
+		keys = append(keys, bson.E{Key: field, Value: 1})
+	}
+	return mongo.IndexModel{
+		Keys:    keys,
+		Options: options.Index().SetUnique(true),
+	}
+}
+
+// TTLIndex returns an index that makes MongoDB delete documents once the time in the given date
+// field is older than expireAfter. Use zero to expire documents exactly at the time stored in the field.
+func TTLIndex(field string, expireAfter time.Duration) mongo.IndexModel {
+	return mongo.IndexModel{
+		Keys:    bson.D{{Key: field, Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(int32(expireAfter / time.Second)),
+	}
+}
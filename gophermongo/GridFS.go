@@ -0,0 +1,172 @@
+package gophermongo
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/gridfs"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// DefaultGridFSBucket is the bucket used when an empty bucket name is given, matching the driver's default.
+const DefaultGridFSBucket = "fs"
+
+// gridFSOptions holds the optional settings of UploadGridFS.
+type gridFSOptions struct {
+	chunkSizeBytes int32
+}
+
+// GridFSOption configures optional behaviour of UploadGridFS.
+type GridFSOption func(*gridFSOptions)
+
+// WithChunkSize sets the size of the chunks a file is split into, in bytes. Values of zero or less
+// keep the driver default of 255 KiB.
+//
+// Example usage:
+//
+//	id, err := UploadGridFS(ctx, database, "videos", "intro.mp4", file, WithChunkSize(1024*1024))
+func WithChunkSize(bytes int32) GridFSOption {
+	return func(o *gridFSOptions) {
+		o.chunkSizeBytes = bytes
+	}
+}
+
+// UploadGridFS stores the contents of r as a new file in a GridFS bucket, split into chunks so that
+// files larger than the 16 MB document limit can be stored.
+//
+// The upload is aborted and the chunks written so far are removed when reading r fails or ctx ends.
+//
+// Params:
+//
+//	ctx - The context for cancelling the upload; its deadline applies to the whole upload.
+//	db - The database holding the bucket, e.g. one returned by GetDatabase.
+//	bucketName - The bucket to store the file in; empty uses DefaultGridFSBucket.
+//	filename - The name recorded for the file. GridFS does not require names to be unique.
+//	r - The contents of the file.
+//	opts - Optional settings, e.g. WithChunkSize.
+//
+// Returns:
+//
+//	primitive.ObjectID - The ID of the stored file, for DownloadGridFS.
+//	error - An error if the bucket cannot be opened or the upload fails.
+//
+// Example usage:
+//
+//	file, err := os.Open("report.pdf")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer file.Close()
+//
+//	id, err := UploadGridFS(ctx, database, "reports", "report.pdf", file)
+//	if err != nil {
+//	    log.Fatalf("Failed to upload report: %v", err)
+//	}
+func UploadGridFS(ctx context.Context, db *mongo.Database, bucketName, filename string, r io.Reader, opts ...GridFSOption) (primitive.ObjectID, error) {
+	settings := &gridFSOptions{}
+	for _, opt := range opts {
+		opt(settings)
+	}
+
+	bucket, err := openGridFSBucket(ctx, db, bucketName)
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+
+	uploadOptions := options.GridFSUpload()
+	if settings.chunkSizeBytes > 0 {
+		uploadOptions.SetChunkSizeBytes(settings.chunkSizeBytes)
+	}
+
+	id, err := bucket.UploadFromStream(filename, &contextReader{ctx: ctx, r: r}, uploadOptions)
+	if err != nil {
+		return primitive.NilObjectID, fmt.Errorf("failed to upload %s to GridFS bucket %s: %w", filename, bucket.GetFilesCollection().Name(), err)
+	}
+	return id, nil
+}
+
+// DownloadGridFS writes the contents of a file stored in a GridFS bucket to w.
+//
+// Params:
+//
+//	ctx - The context for cancelling the download; its deadline applies to the whole download.
+//	db - The database holding the bucket, e.g. one returned by GetDatabase.
+//	bucketName - The bucket the file is stored in; empty uses DefaultGridFSBucket.
+//	id - The ID returned by UploadGridFS.
+//	w - The destination of the contents.
+//
+// Returns:
+//
+//	error - An error if the file does not exist (wrapping gridfs.ErrFileNotFound) or the download fails.
+//
+// Example usage:
+//
+//	var buf bytes.Buffer
+//	if err := DownloadGridFS(ctx, database, "reports", id, &buf); err != nil {
+//	    log.Fatalf("Failed to download report: %v", err)
+//	}
+func DownloadGridFS(ctx context.Context, db *mongo.Database, bucketName string, id primitive.ObjectID, w io.Writer) error {
+	bucket, err := openGridFSBucket(ctx, db, bucketName)
+	if err != nil {
+		return err
+	}
+
+	if _, err := bucket.DownloadToStream(id, &contextWriter{ctx: ctx, w: w}); err != nil {
+		return fmt.Errorf("failed to download %s from GridFS bucket %s: %w", id.Hex(), bucket.GetFilesCollection().Name(), err)
+	}
+	return nil
+}
+
+// openGridFSBucket opens the named bucket of db and applies the deadline of ctx to its reads and writes,
+// as the driver's streaming API takes deadlines rather than contexts.
+func openGridFSBucket(ctx context.Context, db *mongo.Database, bucketName string) (*gridfs.Bucket, error) {
+	if bucketName == "" {
+		bucketName = DefaultGridFSBucket
+	}
+
+	bucket, err := gridfs.NewBucket(db, options.GridFSBucket().SetName(bucketName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open GridFS bucket %s: %w", bucketName, err)
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := bucket.SetReadDeadline(deadline); err != nil {
+			return nil, fmt.Errorf("failed to set GridFS read deadline: %w", err)
+		}
+		if err := bucket.SetWriteDeadline(deadline); err != nil {
+			return nil, fmt.Errorf("failed to set GridFS write deadline: %w", err)
+		}
+	}
+	return bucket, nil
+}
+
+// contextReader fails reads once its context has ended, so that cancelling the context stops an upload
+// between chunks.
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (c *contextReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.r.Read(p)
+}
+
+// contextWriter fails writes once its context has ended, so that cancelling the context stops a download
+// between chunks.
+type contextWriter struct {
+	ctx context.Context
+	w   io.Writer
+}
+
+func (c *contextWriter) Write(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.w.Write(p)
+}
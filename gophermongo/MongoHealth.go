@@ -0,0 +1,107 @@
+package gophermongo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+)
+
+// HealthStatus is the outcome of one check made by StartMongoHealthMonitor.
+//
+// Fields:
+//
+//	Healthy - Whether the deployment answered the ping.
+//	Err - The ping error when the deployment is unhealthy, nil otherwise.
+//	CheckedAt - When the check completed.
+type HealthStatus struct {
+	Healthy   bool
+	Err       error
+	CheckedAt time.Time
+}
+
+// PingMongo verifies that the primary of the deployment answers within the timeout.
+//
+// Params:
+//
+//	ctx - The context for cancelling the ping.
+//	client - The MongoDB client to check.
+//	timeout - The maximum duration of the ping.
+//
+// Returns:
+//
+//	error - An error if MongoDB cannot be reached in time, nil if it is healthy.
+//
+// Example usage:
+//
+//	if err := PingMongo(ctx, client, 2*time.Second); err != nil {
+//	    log.Printf("MongoDB is unreachable: %v", err)
+//	}
+func PingMongo(ctx context.Context, client *mongo.Client, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	if err := client.Ping(ctx, readpref.Primary()); err != nil {
+		return fmt.Errorf("failed to ping MongoDB: %w", err)
+	}
+	return nil
+}
+
+// StartMongoHealthMonitor pings MongoDB every interval in the background and reports each result on
+// the returned channel.
+//
+// The first check runs immediately and each ping may take at most interval. The channel is closed
+// once ctx is cancelled.
+//
+// Params:
+//
+//	ctx - The context whose cancellation stops the monitor.
+//	client - The MongoDB client to monitor.
+//	interval - The time between two checks.
+//
+// Returns:
+//
+//	<-chan HealthStatus - The results of the checks.
+//
+// Example usage:
+//
+//	go func() {
+//	    for status := range StartMongoHealthMonitor(ctx, client, 30*time.Second) {
+//	        if !status.Healthy {
+//	            log.Printf("MongoDB is unhealthy: %v", status.Err)
+//	        }
+//	    }
+//	}()
+func StartMongoHealthMonitor(ctx context.Context, client *mongo.Client, interval time.Duration) <-chan HealthStatus {
+	statuses := make(chan HealthStatus, 1)
+
+	go func() {
+		defer close(statuses)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			err := PingMongo(ctx, client, interval)
+			if ctx.Err() != nil {
+				return
+			}
+
+			select {
+			case statuses <- HealthStatus{Healthy: err == nil, Err: err, CheckedAt: time.Now()}:
+			case <-ctx.Done():
+				return
+			}
+
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return statuses
+}
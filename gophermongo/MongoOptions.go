@@ -0,0 +1,137 @@
+package gophermongo
+
+import (
+	"context"
+	"log"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"go.mongodb.org/mongo-driver/mongo/writeconcern"
+)
+
+// connectOptions holds the optional settings of ConnectToMongoDB.
+type connectOptions struct {
+	onRetry       func(attempt int, err error)
+	clientOptions []*options.ClientOptions
+	skipPing      bool
+	verifyCommand bson.D
+}
+
+// Option configures optional behaviour of ConnectToMongoDB.
+//
+// Example usage:
+//
+//	client, err := ConnectToMongoDB(ctx, dsn, 10*time.Second, 3, WithOnRetry(func(attempt int, err error) {
+//	    logger.Warn("mongo connection attempt failed", "attempt", attempt, "error", err)
+//	}))
+type Option func(*connectOptions)
+
+// WithOnRetry sets a callback invoked after every failed connection attempt with the 1-based attempt
+// number and its error. When it is set, failed attempts are no longer written to the standard log.
+func WithOnRetry(onRetry func(attempt int, err error)) Option {
+	return func(o *connectOptions) {
+		o.onRetry = onRetry
+	}
+}
+
+// WithClientOptions layers driver client options over the ones derived from the connection string.
+// Later options win over earlier ones, and every option wins over the URI.
+//
+// Example usage:
+//
+//	client, err := ConnectToMongoDB(ctx, dsn, 10*time.Second, 3, WithClientOptions(
+//	    options.Client().SetAppName("orders").SetRetryWrites(true),
+//	))
+func WithClientOptions(clientOptions ...*options.ClientOptions) Option {
+	return func(o *connectOptions) {
+		o.clientOptions = append(o.clientOptions, clientOptions...)
+	}
+}
+
+// WithMaxPoolSize sets the maximum number of connections in the client's pool.
+func WithMaxPoolSize(size uint64) Option {
+	return WithClientOptions(options.Client().SetMaxPoolSize(size))
+}
+
+// WithReadPreference sets which members of the replica set reads are sent to, e.g. readpref.SecondaryPreferred().
+func WithReadPreference(pref *readpref.ReadPref) Option {
+	return WithClientOptions(options.Client().SetReadPreference(pref))
+}
+
+// WithWriteConcern sets the acknowledgement requested for writes, e.g. writeconcern.Majority().
+func WithWriteConcern(concern *writeconcern.WriteConcern) Option {
+	return WithClientOptions(options.Client().SetWriteConcern(concern))
+}
+
+// WithServerAPI pins the client to a version of the MongoDB Stable API, as required by some Atlas tiers.
+//
+// Example usage:
+//
+//	client, err := ConnectToMongoDB(ctx, dsn, 10*time.Second, 3, WithServerAPI(options.ServerAPIVersion1))
+func WithServerAPI(version options.ServerAPIVersion) Option {
+	return WithClientOptions(options.Client().SetServerAPIOptions(options.ServerAPI(version)))
+}
+
+// WithSkipPing returns the client without verifying the connection, for managed or proxied deployments,
+// such as some serverless tiers, that reject pings but serve real operations.
+//
+// The tradeoff is that ConnectToMongoDB then succeeds as soon as the client is created, and its retries
+// only cover errors in the connection string or client options. An unreachable server or bad credentials
+// surface on the first real operation instead. Prefer WithVerifyCommand when the deployment accepts any
+// cheap command.
+func WithSkipPing() Option {
+	return func(o *connectOptions) {
+		o.skipPing = true
+	}
+}
+
+// WithVerifyCommand verifies the connection by running command against the admin database instead of
+// pinging. Connection attempts whose command fails are retried like failed pings. WithSkipPing takes
+// precedence when both are given.
+//
+// Example usage:
+//
+//	client, err := ConnectToMongoDB(ctx, dsn, 10*time.Second, 3, WithVerifyCommand(bson.D{{Key: "hello", Value: 1}}))
+func WithVerifyCommand(command bson.D) Option {
+	return func(o *connectOptions) {
+		o.verifyCommand = command
+	}
+}
+
+// verify checks that client can reach the deployment: with the configured command, with a ping by
+// default, or not at all WithSkipPing.
+func (o *connectOptions) verify(ctx context.Context, client *mongo.Client) error {
+	switch {
+	case o.skipPing:
+		return nil
+	case o.verifyCommand != nil:
+		return client.Database("admin").RunCommand(ctx, o.verifyCommand).Err()
+	default:
+		return client.Ping(ctx, nil)
+	}
+}
+
+// clientOptionsFor returns the options passed to mongo.Connect: the URI first, then the layered options.
+func (o *connectOptions) clientOptionsFor(dsn string) []*options.ClientOptions {
+	return append([]*options.ClientOptions{options.Client().ApplyURI(dsn)}, o.clientOptions...)
+}
+
+// newConnectOptions applies the given options over the defaults.
+func newConnectOptions(opts []Option) *connectOptions {
+	options := &connectOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+	return options
+}
+
+// reportFailure passes a failed attempt to the WithOnRetry callback, falling back to the standard log.
+func (o *connectOptions) reportFailure(attempt int, err error) {
+	if o.onRetry != nil {
+		o.onRetry(attempt, err)
+		return
+	}
+	log.Printf("Connection attempt %d failed: %v\n", attempt, err)
+}
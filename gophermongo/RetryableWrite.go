@@ -0,0 +1,82 @@
+package gophermongo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// retryableWriteBaseDelay is the delay before the first retry of RetryableWrite, doubling for each
+// further one up to retryableWriteMaxDelay. It is a variable so tests can shorten it.
+var retryableWriteBaseDelay = 100 * time.Millisecond
+
+// retryableWriteMaxDelay caps the delay between the attempts of RetryableWrite.
+const retryableWriteMaxDelay = 5 * time.Second
+
+// retryableWriteLabel is the error label the server and driver attach to write errors that are safe to
+// retry. TransientTransactionError is left out on purpose: it calls for retrying a whole transaction,
+// which session.WithTransaction already does, not a single write of it.
+const retryableWriteLabel = "RetryableWriteError"
+
+// RetryableWrite runs fn and, while it fails with an error MongoDB labels RetryableWriteError, runs it
+// again with exponential backoff, up to maxRetries more times.
+//
+// The driver already retries a write once after a primary stepdown or network error; this covers
+// failovers that take longer, such as an election. Errors without that label, e.g. a
+// duplicate key, are returned right away, unwrapped. fn should be idempotent or a single write the
+// server deduplicates, as a write may have been applied before its error was reported. Do not call it
+// inside a transaction: use session.WithTransaction, which retries the whole transaction instead.
+//
+// Params:
+//
+//	ctx - The context whose cancellation stops the retries.
+//	fn - The write to run, e.g. a call to InsertOne or UpdateByID.
+//	maxRetries - The number of retries after the first attempt.
+//
+// Returns:
+//
+//	error - nil once fn succeeds, the error of fn if it is not retryable, or an error wrapping the last
+//	    retryable error once the retries are used up or ctx ends.
+//
+// Example usage:
+//
+//	err := RetryableWrite(ctx, func() error {
+//	    _, err := InsertOne(ctx, orders, order)
+//	    return err
+//	}, 3)
+//	if err != nil {
+//	    log.Printf("Failed to save order: %v", err)
+//	}
+func RetryableWrite(ctx context.Context, fn func() error, maxRetries int) error {
+	delay := retryableWriteBaseDelay
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil {
+			return nil
+		}
+		if !isRetryableWriteError(err) {
+			return err
+		}
+		if attempt >= maxRetries {
+			return fmt.Errorf("MongoDB write failed after %d retries: %w", maxRetries, err)
+		}
+
+		log.Printf("Retrying MongoDB write in %v after a retryable error: %v", delay, err)
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("context ended while retrying MongoDB write: %w (last error: %w)", ctx.Err(), err)
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, retryableWriteMaxDelay)
+	}
+}
+
+// isRetryableWriteError reports whether err carries the retryableWriteLabel.
+func isRetryableWriteError(err error) bool {
+	var serverErr mongo.ServerError
+	return errors.As(err, &serverErr) && serverErr.HasErrorLabel(retryableWriteLabel)
+}
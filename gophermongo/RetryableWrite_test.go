@@ -0,0 +1,92 @@
+package gophermongo
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// shortenRetryableWriteDelay makes RetryableWrite retry after a millisecond for the rest of the test.
+func shortenRetryableWriteDelay(t *testing.T) {
+	t.Helper()
+	original := retryableWriteBaseDelay
+	retryableWriteBaseDelay = time.Millisecond
+	t.Cleanup(func() { retryableWriteBaseDelay = original })
+}
+
+func TestRetryableWriteLabels(t *testing.T) {
+	shortenRetryableWriteDelay(t)
+	tests := []struct {
+		name         string
+		err          error
+		wantAttempts int
+	}{
+		{"retryable write", mongo.WriteException{Labels: []string{"RetryableWriteError"}}, 2},
+		{"transient transaction", mongo.WriteException{Labels: []string{"TransientTransactionError"}}, 1},
+		{"duplicate key", mongo.WriteException{WriteErrors: mongo.WriteErrors{{Code: 11000, Message: "duplicate key"}}}, 1},
+		{"not a server error", errors.New("boom"), 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := 0
+			err := RetryableWrite(context.Background(), func() error {
+				attempts++
+				return tt.err
+			}, 1)
+			if err == nil {
+				t.Fatal("RetryableWrite succeeded, want an error")
+			}
+			if attempts != tt.wantAttempts {
+				t.Errorf("made %d attempts, want %d", attempts, tt.wantAttempts)
+			}
+		})
+	}
+}
+
+func TestRetryableWriteSucceedsAfterRetryableError(t *testing.T) {
+	shortenRetryableWriteDelay(t)
+
+	attempts := 0
+	start := time.Now()
+	err := RetryableWrite(context.Background(), func() error {
+		attempts++
+		if attempts < 3 {
+			return mongo.CommandError{Code: 189, Name: "PrimarySteppedDown", Labels: []string{"RetryableWriteError"}}
+		}
+		return nil
+	}, 3)
+	if err != nil {
+		t.Fatalf("RetryableWrite failed: %v", err)
+	}
+	if attempts != 3 {
+		t.Errorf("made %d attempts, want 3", attempts)
+	}
+	if elapsed := time.Since(start); elapsed >= 100*time.Millisecond {
+		t.Errorf("retries took %v, want the shortened delay", elapsed)
+	}
+}
+
+func TestRetryableWriteStopsWhenContextEnds(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	retryable := mongo.WriteException{Labels: []string{"RetryableWriteError"}}
+
+	attempts := 0
+	err := RetryableWrite(ctx, func() error {
+		attempts++
+		cancel()
+		return retryable
+	}, 3)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("RetryableWrite returned %v, want %v", err, context.Canceled)
+	}
+	var writeErr mongo.WriteException
+	if !errors.As(err, &writeErr) {
+		t.Errorf("RetryableWrite returned %v, want it to wrap the last write error", err)
+	}
+	if attempts != 1 {
+		t.Errorf("made %d attempts, want 1", attempts)
+	}
+}
@@ -0,0 +1,69 @@
+package gopherpostgres
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"regexp"
+
+	"gorm.io/gorm"
+)
+
+// extensionNamePattern matches the names of PostgreSQL extensions, such as "pgcrypto" or "uuid-ossp".
+// Anything else is rejected because the name has to be interpolated into CREATE EXTENSION.
+var extensionNamePattern = regexp.MustCompile(`^[a-z0-9_][a-z0-9_-]*$`)
+
+// EnableExtension checks if the given extension is enabled in PostgreSQL and enables it if it is not.
+//
+// The extension is looked up in the pg_extension system catalog first and only created when missing,
+// so calling this function on every start is safe. The name must consist of lowercase letters, digits,
+// underscores and hyphens, which covers the extensions shipped with PostgreSQL and rules out SQL injection.
+//
+// Params:
+//
+//	db - The GORM database connection (*gorm.DB) used to interact with the PostgreSQL database.
+//	name - The extension name, e.g. "pgcrypto", "postgis" or "citext".
+//
+// Returns:
+//
+//	error - Returns an error if the name is invalid, or if the check or enabling the extension fails.
+//
+// Example usage:
+//
+//	for _, extension := range []string{"pgcrypto", "citext"} {
+//	    if err := EnableExtension(db, extension); err != nil {
+//	        log.Fatalf("Error enabling %s extension: %v", extension, err)
+//	    }
+//	}
+func EnableExtension(db *gorm.DB, name string) error {
+	if !extensionNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid extension name %q", name)
+	}
+
+	// Get the underlying sql.DB connection from GORM
+	sqlDB, err := db.DB()
+	if err != nil {
+		return fmt.Errorf("failed to get database connection from GORM: %w", err)
+	}
+
+	// Query PostgreSQL to check if the extension is already enabled
+	var exists int
+	err = sqlDB.QueryRow("SELECT 1 FROM pg_extension WHERE extname = $1", name).Scan(&exists)
+
+	// Handle the case where the extension is not found
+	if err == sql.ErrNoRows {
+		// The name was validated above, so quoting it as an identifier is safe
+		if _, err := sqlDB.Exec(fmt.Sprintf("CREATE EXTENSION IF NOT EXISTS %q", name)); err != nil {
+			return fmt.Errorf("failed to create %s extension: %w", name, err)
+		}
+		log.Printf("%s extension enabled successfully", name)
+	} else if err != nil {
+		// Return an error if the query failed for any other reason
+		return fmt.Errorf("failed to check for %s extension: %w", name, err)
+	} else {
+		// Log success if the extension is already enabled
+		log.Printf("%s extension is already enabled", name)
+	}
+
+	return nil
+}
@@ -0,0 +1,107 @@
+package gopherpostgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"gorm.io/gorm/logger"
+)
+
+// LeveledLogger receives the entries of NewGORMLogger as a message with key/value pairs, at the level
+// GORM logged them. *gopherlogger.Logger satisfies it, as do other structured loggers with matching methods.
+type LeveledLogger interface {
+	Info(msg string, keyvals ...any)
+	Warn(msg string, keyvals ...any)
+	Error(msg string, keyvals ...any)
+}
+
+// NewGORMLogger returns a GORM logger that writes SQL statements, slow-query warnings and errors to out.
+//
+// Statements are logged as "query" at Info, slow ones as "slow query" at Warn and failed ones as
+// "query failed" at Error, with the SQL, the elapsed milliseconds and the affected rows as key/value
+// pairs, so out's own level filtering and text or JSON encoding apply. Record-not-found errors are not logged.
+//
+// Params:
+//
+//	out - The logger to write to, e.g. a *gopherlogger.Logger; nil writes plain lines through the standard log package.
+//	slowThreshold - Queries taking longer than this are logged as warnings; zero disables the warning.
+//	level - The most verbose level to log, e.g. logger.Warn, or logger.Info to log every statement.
+//
+// Returns:
+//
+//	logger.Interface - The logger, ready to be passed WithGORMLogger or in gorm.Config.
+//
+// Example usage:
+//
+//	appLogger := gopherlogger.NewLogger(os.Stdout, gopherlogger.LevelInfo, gopherlogger.WithFormat(gopherlogger.FormatJSON))
+//	db, err := ConnectToPostgresGORM(ctx, dsn, 10*time.Second, 3,
+//	    WithGORMLogger(NewGORMLogger(appLogger, 200*time.Millisecond, logger.Warn)))
+func NewGORMLogger(out LeveledLogger, slowThreshold time.Duration, level logger.LogLevel) logger.Interface {
+	if out == nil {
+		return logger.New(log.Default(), logger.Config{
+			SlowThreshold:             slowThreshold,
+			LogLevel:                  level,
+			IgnoreRecordNotFoundError: true,
+			Colorful:                  false,
+		})
+	}
+	return &gormLogger{out: out, slowThreshold: slowThreshold, level: level}
+}
+
+// gormLogger adapts a LeveledLogger to GORM's logger.Interface.
+type gormLogger struct {
+	out           LeveledLogger
+	slowThreshold time.Duration
+	level         logger.LogLevel
+}
+
+// LogMode returns a copy of the logger that logs up to level.
+func (l *gormLogger) LogMode(level logger.LogLevel) logger.Interface {
+	copied := *l
+	copied.level = level
+	return &copied
+}
+
+// Info logs a GORM informational message.
+func (l *gormLogger) Info(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= logger.Info {
+		l.out.Info(fmt.Sprintf(msg, data...))
+	}
+}
+
+// Warn logs a GORM warning.
+func (l *gormLogger) Warn(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= logger.Warn {
+		l.out.Warn(fmt.Sprintf(msg, data...))
+	}
+}
+
+// Error logs a GORM error.
+func (l *gormLogger) Error(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= logger.Error {
+		l.out.Error(fmt.Sprintf(msg, data...))
+	}
+}
+
+// Trace logs a finished statement: failed ones at Error, slow ones at Warn and the rest at Info.
+func (l *gormLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	if l.level <= logger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	switch {
+	case err != nil && l.level >= logger.Error && !errors.Is(err, logger.ErrRecordNotFound):
+		sql, rows := fc()
+		l.out.Error("query failed", "error", err, "elapsed_ms", elapsed.Milliseconds(), "rows", rows, "sql", sql)
+	case l.slowThreshold > 0 && elapsed > l.slowThreshold && l.level >= logger.Warn:
+		sql, rows := fc()
+		l.out.Warn("slow query", "elapsed_ms", elapsed.Milliseconds(), "threshold_ms", l.slowThreshold.Milliseconds(), "rows", rows, "sql", sql)
+	case l.level >= logger.Info:
+		sql, rows := fc()
+		l.out.Info("query", "elapsed_ms", elapsed.Milliseconds(), "rows", rows, "sql", sql)
+	}
+}
@@ -0,0 +1,73 @@
+package gopherpostgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"gorm.io/gorm/logger"
+)
+
+// recordingLogger is a LeveledLogger that keeps the level and message of every entry.
+type recordingLogger struct {
+	entries []string
+}
+
+func (l *recordingLogger) Info(msg string, keyvals ...any) {
+	l.entries = append(l.entries, "INFO "+msg)
+}
+func (l *recordingLogger) Warn(msg string, keyvals ...any) {
+	l.entries = append(l.entries, "WARN "+msg)
+}
+func (l *recordingLogger) Error(msg string, keyvals ...any) {
+	l.entries = append(l.entries, "ERROR "+msg)
+}
+
+func TestGORMLoggerTrace(t *testing.T) {
+	statement := func() (string, int64) { return "SELECT 1", 1 }
+	ctx := context.Background()
+
+	tests := []struct {
+		name  string
+		level logger.LogLevel
+		begin time.Time
+		err   error
+		want  string
+	}{
+		{"failed", logger.Warn, time.Now(), errors.New("syntax error"), "ERROR query failed"},
+		{"record not found", logger.Warn, time.Now(), logger.ErrRecordNotFound, ""},
+		{"slow", logger.Warn, time.Now().Add(-time.Second), nil, "WARN slow query"},
+		{"fast at warn", logger.Warn, time.Now(), nil, ""},
+		{"fast at info", logger.Info, time.Now(), nil, "INFO query"},
+		{"silent", logger.Silent, time.Now(), errors.New("syntax error"), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := &recordingLogger{}
+			gormLogger := NewGORMLogger(out, 200*time.Millisecond, tt.level)
+			gormLogger.Trace(ctx, tt.begin, statement, tt.err)
+
+			got := ""
+			if len(out.entries) > 0 {
+				got = out.entries[0]
+			}
+			if len(out.entries) > 1 || got != tt.want {
+				t.Errorf("logged %v, want %q", out.entries, tt.want)
+			}
+		})
+	}
+}
+
+func TestGORMLoggerLogMode(t *testing.T) {
+	out := &recordingLogger{}
+	quiet := NewGORMLogger(out, 0, logger.Warn)
+	verbose := quiet.LogMode(logger.Info)
+
+	quiet.Info(context.Background(), "migrating %s", "users")
+	verbose.Info(context.Background(), "migrating %s", "users")
+
+	if len(out.entries) != 1 || out.entries[0] != "INFO migrating users" {
+		t.Errorf("logged %v, want one \"INFO migrating users\"", out.entries)
+	}
+}
@@ -0,0 +1,97 @@
+package gopherpostgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/lib/pq"
+)
+
+// Errors the connect functions wrap around the underlying failure, so that callers can tell the
+// cause apart with errors.Is. The driver's own error stays in the chain for errors.As and logging.
+//
+// Example usage:
+//
+//	db, err := ConnectPostgresDB(ctx, dsn, 10*time.Second, 3)
+//	switch {
+//	case errors.Is(err, ErrAuthFailed):
+//	    log.Fatalf("Check the database credentials: %v", err)
+//	case errors.Is(err, ErrServerUnreachable), errors.Is(err, ErrConnectTimeout):
+//	    log.Fatalf("PostgreSQL is down or firewalled: %v", err)
+//	}
+var (
+	// ErrInvalidDSN is returned for a DSN that is empty or cannot be parsed.
+	ErrInvalidDSN = errors.New("invalid database URL (DSN)")
+	// ErrConnectTimeout is returned when the connect timeout or a network timeout elapsed before the server answered.
+	ErrConnectTimeout = errors.New("timed out connecting to PostgreSQL")
+	// ErrServerUnreachable is returned when the server could not be reached, e.g. the connection was
+	// refused or the host name could not be resolved.
+	ErrServerUnreachable = errors.New("PostgreSQL server unreachable")
+	// ErrAuthFailed is returned when the server rejected the credentials or the client is not allowed to connect.
+	ErrAuthFailed = errors.New("PostgreSQL authentication failed")
+)
+
+// invalidAuthorizationClass is the SQLSTATE class of authentication failures, e.g. 28P01 invalid_password.
+const invalidAuthorizationClass = "28"
+
+// validateDSN parses dsn, a URL or a list of key=value pairs, so that a malformed one is reported as
+// ErrInvalidDSN up front instead of failing every connection attempt. Passwords are redacted from the error.
+func validateDSN(dsn string) error {
+	if dsn == "" {
+		return fmt.Errorf("%w: missing required database URL", ErrInvalidDSN)
+	}
+	if _, err := pgconn.ParseConfig(dsn); err != nil {
+		return invalidDSNError(err)
+	}
+	return nil
+}
+
+// invalidDSNError wraps a DSN parse error from pgx in ErrInvalidDSN. pgx redacts the password in its
+// own message, but a URL parse error below it quotes the DSN whole, so only its reason is kept.
+func invalidDSNError(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return fmt.Errorf("%w: %v", ErrInvalidDSN, urlErr.Err)
+	}
+	return fmt.Errorf("%w: %w", ErrInvalidDSN, err)
+}
+
+// connectError wraps err with message and, when the cause can be told, the matching error above.
+func connectError(message string, err error) error {
+	if kind := connectErrorKind(err); kind != nil {
+		return fmt.Errorf("%s: %w: %w", message, kind, err)
+	}
+	return fmt.Errorf("%s: %w", message, err)
+}
+
+// connectErrorKind classifies a connection failure reported by lib/pq, pgx or the network.
+func connectErrorKind(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && string(pqErr.Code.Class()) == invalidAuthorizationClass {
+		return ErrAuthFailed
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && strings.HasPrefix(pgErr.Code, invalidAuthorizationClass) {
+		return ErrAuthFailed
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ErrConnectTimeout
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return ErrConnectTimeout
+	}
+
+	var opErr *net.OpError
+	var dnsErr *net.DNSError
+	if errors.As(err, &opErr) || errors.As(err, &dnsErr) {
+		return ErrServerUnreachable
+	}
+	return nil
+}
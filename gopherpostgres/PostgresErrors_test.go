@@ -0,0 +1,46 @@
+package gopherpostgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/lib/pq"
+)
+
+func TestConnectErrorKind(t *testing.T) {
+	refused := &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}
+
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{"pq invalid password", &pq.Error{Code: "28P01"}, ErrAuthFailed},
+		{"pq invalid authorization", fmt.Errorf("failed to ping: %w", &pq.Error{Code: "28000"}), ErrAuthFailed},
+		{"pq other class", &pq.Error{Code: "3D000"}, nil},
+		{"pgx invalid password", &pgconn.PgError{Code: "28P01"}, ErrAuthFailed},
+		{"pgx invalid authorization", fmt.Errorf("failed to connect: %w", &pgconn.PgError{Code: "28000"}), ErrAuthFailed},
+		{"pgx other class", &pgconn.PgError{Code: "3D000"}, nil},
+		{"deadline exceeded", fmt.Errorf("failed to ping: %w", context.DeadlineExceeded), ErrConnectTimeout},
+		{"dial timeout", &net.OpError{Op: "dial", Net: "tcp", Err: os.ErrDeadlineExceeded}, ErrConnectTimeout},
+		{"DNS timeout", &net.DNSError{Err: "i/o timeout", Name: "db.internal", IsTimeout: true}, ErrConnectTimeout},
+		{"connection refused", refused, ErrServerUnreachable},
+		{"wrapped connection refused", fmt.Errorf("failed to connect: %w", refused), ErrServerUnreachable},
+		{"unknown host", &net.DNSError{Err: "no such host", Name: "db.internal", IsNotFound: true}, ErrServerUnreachable},
+		{"cancelled", context.Canceled, nil},
+		{"other", errors.New("driver: bad connection"), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := connectErrorKind(tt.err); got != tt.want {
+				t.Errorf("connectErrorKind(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
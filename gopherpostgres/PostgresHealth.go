@@ -0,0 +1,127 @@
+package gopherpostgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// HealthStatus is the outcome of one check made by StartHealthMonitor.
+//
+// Fields:
+//
+//	Healthy - Whether the database answered the ping.
+//	Err - The ping error when the database is unhealthy, nil otherwise.
+//	CheckedAt - When the check completed.
+type HealthStatus struct {
+	Healthy   bool
+	Err       error
+	CheckedAt time.Time
+}
+
+// PingPostgres verifies that a live connection to the database can be made within the timeout.
+//
+// Params:
+//
+//	ctx - The context for cancelling the ping.
+//	db - The database to check.
+//	timeout - The maximum duration of the ping.
+//
+// Returns:
+//
+//	error - An error if the database cannot be reached in time, nil if it is healthy.
+//
+// Example usage:
+//
+//	if err := PingPostgres(ctx, db, 2*time.Second); err != nil {
+//	    log.Printf("PostgreSQL is unreachable: %v", err)
+//	}
+func PingPostgres(ctx context.Context, db *sql.DB, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	if err := db.PingContext(ctx); err != nil {
+		return fmt.Errorf("failed to ping PostgreSQL: %w", err)
+	}
+	return nil
+}
+
+// PingPostgresGORM is PingPostgres for a connection opened with GORM.
+func PingPostgresGORM(ctx context.Context, db *gorm.DB, timeout time.Duration) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return fmt.Errorf("failed to get database connection from GORM: %w", err)
+	}
+	return PingPostgres(ctx, sqlDB, timeout)
+}
+
+// StartHealthMonitor pings the database every interval and reports each result on the returned channel.
+//
+// The first check runs immediately. Each ping may take at most interval. The monitor stops and closes
+// the channel once ctx is cancelled, so range over the channel to consume it.
+//
+// Params:
+//
+//	ctx - The context whose cancellation stops the monitor.
+//	db - The database to monitor.
+//	interval - The time between two checks.
+//
+// Returns:
+//
+//	<-chan HealthStatus - The results of the checks.
+//
+// Example usage:
+//
+//	for status := range StartHealthMonitor(ctx, db, 30*time.Second) {
+//	    if !status.Healthy {
+//	        log.Printf("PostgreSQL is unhealthy: %v", status.Err)
+//	    }
+//	}
+func StartHealthMonitor(ctx context.Context, db *sql.DB, interval time.Duration) <-chan HealthStatus {
+	statuses := make(chan HealthStatus, 1)
+
+	go func() {
+		defer close(statuses)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			err := PingPostgres(ctx, db, interval)
+			if ctx.Err() != nil {
+				return
+			}
+
+			select {
+			case statuses <- HealthStatus{Healthy: err == nil, Err: err, CheckedAt: time.Now()}:
+			case <-ctx.Done():
+				return
+			}
+
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return statuses
+}
+
+// StartHealthMonitorGORM is StartHealthMonitor for a connection opened with GORM.
+//
+// Returns:
+//
+//	<-chan HealthStatus - The results of the checks.
+//	error - An error if the underlying database connection cannot be retrieved from GORM.
+func StartHealthMonitorGORM(ctx context.Context, db *gorm.DB, interval time.Duration) (<-chan HealthStatus, error) {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get database connection from GORM: %w", err)
+	}
+	return StartHealthMonitor(ctx, sqlDB, interval), nil
+}
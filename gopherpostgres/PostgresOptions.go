@@ -0,0 +1,249 @@
+package gopherpostgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"math/rand"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm/logger"
+)
+
+const (
+	// DefaultRetryDelay is the wait before the first retry of a failed connection attempt.
+	DefaultRetryDelay = 1 * time.Second
+	// DefaultBackoffFactor multiplies the retry delay after every failed attempt.
+	DefaultBackoffFactor = 2.0
+	// DefaultMaxRetryDelay caps the retry delay however many attempts have failed.
+	DefaultMaxRetryDelay = 30 * time.Second
+)
+
+// PoolConfig holds the connection pool settings applied to a database after it connects.
+// Zero values leave the database/sql defaults in place.
+//
+// Fields:
+//
+//	MaxOpenConns - The maximum number of open connections to the database.
+//	MaxIdleConns - The maximum number of idle connections kept in the pool.
+//	ConnMaxLifetime - The maximum amount of time a connection may be reused.
+//	ConnMaxIdleTime - The maximum amount of time a connection may sit idle.
+type PoolConfig struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+}
+
+// connectOptions holds the optional settings of the connect functions.
+type connectOptions struct {
+	pool          *PoolConfig
+	retryDelay    time.Duration
+	backoffFactor float64
+	maxRetryDelay time.Duration
+	gormLogger    logger.Interface
+	onRetry       func(attempt int, err error)
+	tls           *TLSConfig
+
+	statementTimeout     time.Duration
+	idleInTransactionTTL time.Duration
+}
+
+// Option configures optional behaviour of ConnectPostgresDB and ConnectToPostgresGORM.
+//
+// Example usage:
+//
+//	db, err := ConnectPostgresDB(ctx, dsn, 10*time.Second, 3, WithPoolConfig(PoolConfig{
+//	    MaxOpenConns:    25,
+//	    MaxIdleConns:    5,
+//	    ConnMaxLifetime: 30 * time.Minute,
+//	}))
+type Option func(*connectOptions)
+
+// WithPoolConfig applies the given connection pool settings once the connection is established.
+func WithPoolConfig(pool PoolConfig) Option {
+	return func(o *connectOptions) {
+		o.pool = &pool
+	}
+}
+
+// WithRetryDelay sets the wait before the first retry. Non-positive values keep DefaultRetryDelay.
+func WithRetryDelay(delay time.Duration) Option {
+	return func(o *connectOptions) {
+		if delay > 0 {
+			o.retryDelay = delay
+		}
+	}
+}
+
+// WithBackoff sets the exponential backoff applied between retries.
+//
+// Parameters:
+//
+//	factor - The multiplier applied to the delay after every failed attempt. Use 1 for a fixed delay.
+//	maxDelay - The upper bound of the delay. Non-positive values keep DefaultMaxRetryDelay.
+func WithBackoff(factor float64, maxDelay time.Duration) Option {
+	return func(o *connectOptions) {
+		if factor >= 1 {
+			o.backoffFactor = factor
+		}
+		if maxDelay > 0 {
+			o.maxRetryDelay = maxDelay
+		}
+	}
+}
+
+// WithGORMLogger sets the logger GORM uses for SQL statements, slow queries and errors, e.g. one
+// returned by NewGORMLogger. It only affects ConnectToPostgresGORM.
+func WithGORMLogger(gormLogger logger.Interface) Option {
+	return func(o *connectOptions) {
+		o.gormLogger = gormLogger
+	}
+}
+
+// WithOnRetry sets a callback invoked after every failed connection attempt with the 1-based attempt
+// number and its error, e.g. to feed the caller's own logger or metrics. When it is set, the failed
+// attempts are reported to the callback instead of the standard log.
+func WithOnRetry(onRetry func(attempt int, err error)) Option {
+	return func(o *connectOptions) {
+		o.onRetry = onRetry
+	}
+}
+
+// WithStatementTimeout makes the server cancel any statement running longer than timeout, so a runaway
+// query cannot hold a connection indefinitely. It sets the statement_timeout session parameter on every
+// connection of the pool, replicas included. The query then fails with SQLSTATE 57014 (query_canceled).
+// The server counts in milliseconds, so timeout is rounded up to a whole millisecond.
+func WithStatementTimeout(timeout time.Duration) Option {
+	return func(o *connectOptions) {
+		o.statementTimeout = timeout
+	}
+}
+
+// WithIdleInTransactionTimeout makes the server close any connection that sits idle inside an open
+// transaction for longer than timeout, releasing the locks a forgotten Commit or Rollback would hold.
+// It sets the idle_in_transaction_session_timeout session parameter on every connection of the pool.
+func WithIdleInTransactionTimeout(timeout time.Duration) Option {
+	return func(o *connectOptions) {
+		o.idleInTransactionTTL = timeout
+	}
+}
+
+// newConnectOptions applies the given options over the defaults.
+func newConnectOptions(opts []Option) *connectOptions {
+	options := &connectOptions{
+		retryDelay:    DefaultRetryDelay,
+		backoffFactor: DefaultBackoffFactor,
+		maxRetryDelay: DefaultMaxRetryDelay,
+	}
+	for _, opt := range opts {
+		opt(options)
+	}
+	return options
+}
+
+// applyPool configures the pool of db with the settings given WithPoolConfig, if any.
+func (o *connectOptions) applyPool(db *sql.DB) {
+	if o.pool == nil {
+		return
+	}
+
+	if o.pool.MaxOpenConns > 0 {
+		db.SetMaxOpenConns(o.pool.MaxOpenConns)
+	}
+	if o.pool.MaxIdleConns > 0 {
+		db.SetMaxIdleConns(o.pool.MaxIdleConns)
+	}
+	if o.pool.ConnMaxLifetime > 0 {
+		db.SetConnMaxLifetime(o.pool.ConnMaxLifetime)
+	}
+	if o.pool.ConnMaxIdleTime > 0 {
+		db.SetConnMaxIdleTime(o.pool.ConnMaxIdleTime)
+	}
+}
+
+// backoff returns the wait after the given failed attempt, counted from zero.
+//
+// The delay grows by backoffFactor per attempt up to maxRetryDelay, and a random jitter of up to
+// half the delay is subtracted so that many clients restarting together do not retry in lockstep.
+func (o *connectOptions) backoff(attempt int) time.Duration {
+	delay := float64(o.retryDelay)
+	for i := 0; i < attempt && delay < float64(o.maxRetryDelay); i++ {
+		delay *= o.backoffFactor
+	}
+	if delay > float64(o.maxRetryDelay) {
+		delay = float64(o.maxRetryDelay)
+	}
+
+	return time.Duration(delay - rand.Float64()*delay/2)
+}
+
+// wait sleeps for delay, returning ctx.Err() early if the context ends first.
+func wait(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// reportFailure hands a failed attempt to the callback given WithOnRetry, or logs it when there is none.
+func (o *connectOptions) reportFailure(attempt int, err error) {
+	if o.onRetry != nil {
+		o.onRetry(attempt, err)
+		return
+	}
+	log.Printf("Connection attempt %d failed: %v", attempt, err)
+}
+
+// sessionDSN adds the session parameters given WithStatementTimeout and WithIdleInTransactionTimeout
+// to dsn. Both drivers send them in the startup message of every new connection, so they apply to
+// the whole pool without a SET per connection. dsn may be a URL or a list of key=value pairs.
+func (o *connectOptions) sessionDSN(dsn string) (string, error) {
+	params := make(map[string]string)
+	if o.statementTimeout > 0 {
+		params["statement_timeout"] = timeoutMillis(o.statementTimeout)
+	}
+	if o.idleInTransactionTTL > 0 {
+		params["idle_in_transaction_session_timeout"] = timeoutMillis(o.idleInTransactionTTL)
+	}
+	if len(params) == 0 {
+		return dsn, nil
+	}
+
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+		u, err := url.Parse(dsn)
+		if err != nil {
+			// The error would contain the DSN and its password
+			return "", ErrInvalidDSN
+		}
+		query := u.Query()
+		for key, value := range params {
+			query.Set(key, value)
+		}
+		u.RawQuery = query.Encode()
+		return u.String(), nil
+	}
+
+	for _, key := range []string{"statement_timeout", "idle_in_transaction_session_timeout"} {
+		if value, ok := params[key]; ok {
+			dsn += fmt.Sprintf(" %s=%s", key, value)
+		}
+	}
+	return dsn, nil
+}
+
+// timeoutMillis formats a positive timeout in whole milliseconds, the unit of the session parameters,
+// rounding up so that a sub-millisecond timeout does not become 0, which disables it.
+func timeoutMillis(timeout time.Duration) string {
+	millis := (timeout + time.Millisecond - 1) / time.Millisecond
+	return strconv.FormatInt(int64(millis), 10)
+}
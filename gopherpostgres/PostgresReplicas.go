@@ -0,0 +1,263 @@
+package gopherpostgres
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultReplicaCheckInterval is how often ReplicaDB pings its replicas to track their health.
+const DefaultReplicaCheckInterval = 30 * time.Second
+
+// replica is one read replica together with its last known health. number is its 1-based position,
+// used in log messages so DSNs and their passwords stay out of the logs.
+type replica struct {
+	number  int
+	db      *sql.DB
+	healthy atomic.Bool
+}
+
+// ReplicaDB routes writes to a primary database and spreads reads across its read replicas.
+//
+// Reads go round-robin to the replicas currently marked healthy and fall back to the primary when
+// none is. Replica health is refreshed every DefaultReplicaCheckInterval, and a replica whose query
+// fails with a connection error is taken out of rotation until the next check succeeds.
+type ReplicaDB struct {
+	primary  *sql.DB
+	replicas []*replica
+	next     atomic.Uint64
+	cancel   context.CancelFunc
+	done     chan struct{}
+	closing  sync.Once
+}
+
+// ConnectPostgresDBWithReplicas connects to a primary PostgreSQL database and its read replicas.
+//
+// The primary is connected with ConnectPostgresDB and must be reachable. Replicas that cannot be
+// reached yet are kept out of rotation and picked up by the periodic health check once they come up.
+//
+// Params:
+//
+//	ctx - The context for managing connection timeout and cancellation.
+//	primaryDSN - The connection string of the primary, which receives all writes.
+//	replicaDSNs - The connection strings of the read replicas.
+//	timeout - The timeout duration for connecting to the primary and for the first ping of each replica.
+//	maxRetries - The maximum number of retries for the primary before giving up.
+//	opts - Optional settings; the pool and retry settings apply to the primary and every replica.
+//
+// Returns:
+//
+//	*ReplicaDB - The connected databases on success.
+//	error - An error if a DSN is empty or the primary cannot be reached.
+//
+// Example usage:
+//
+//	db, err := ConnectPostgresDBWithReplicas(ctx, primaryDSN, []string{replica1DSN, replica2DSN}, 10*time.Second, 3)
+//	if err != nil {
+//	    log.Fatalf("Failed to connect to PostgreSQL: %v", err)
+//	}
+//	defer db.Close()
+//
+//	rows, err := db.QueryContext(ctx, "SELECT id, name FROM users") // served by a replica
+//	_, err = db.ExecContext(ctx, "UPDATE users SET name = $1 WHERE id = $2", name, id) // served by the primary
+func ConnectPostgresDBWithReplicas(ctx context.Context, primaryDSN string, replicaDSNs []string, timeout time.Duration, maxRetries int, opts ...Option) (*ReplicaDB, error) {
+	for _, dsn := range replicaDSNs {
+		if dsn == "" {
+			return nil, fmt.Errorf("%w: missing required replica database URL", ErrInvalidDSN)
+		}
+	}
+
+	primary, err := ConnectPostgresDB(ctx, primaryDSN, timeout, maxRetries, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	options := newConnectOptions(opts)
+	replicas := make([]*replica, 0, len(replicaDSNs))
+	for i, dsn := range replicaDSNs {
+		dsn, err := options.sessionDSN(dsn)
+		if err != nil {
+			closeAll(primary, replicas)
+			return nil, fmt.Errorf("failed to open replica %d: %w", i+1, err)
+		}
+		db, err := options.openDB(dsn)
+		if err != nil {
+			closeAll(primary, replicas)
+			return nil, fmt.Errorf("failed to open replica %d: %w", i+1, err)
+		}
+		options.applyPool(db)
+
+		r := &replica{number: i + 1, db: db}
+		if err := PingPostgres(ctx, db, timeout); err != nil {
+			log.Printf("Replica %d is unreachable, reads go elsewhere until it recovers: %v", i+1, err)
+		} else {
+			r.healthy.Store(true)
+		}
+		replicas = append(replicas, r)
+	}
+
+	monitorCtx, cancel := context.WithCancel(context.Background())
+	rdb := &ReplicaDB{
+		primary:  primary,
+		replicas: replicas,
+		cancel:   cancel,
+		done:     make(chan struct{}),
+	}
+	go rdb.monitor(monitorCtx, DefaultReplicaCheckInterval)
+
+	return rdb, nil
+}
+
+// closeAll closes the primary and the replicas opened so far.
+func closeAll(primary *sql.DB, replicas []*replica) {
+	primary.Close()
+	for _, r := range replicas {
+		r.db.Close()
+	}
+}
+
+// monitor refreshes the health of every replica each interval until ctx is cancelled.
+func (r *ReplicaDB) monitor(ctx context.Context, interval time.Duration) {
+	defer close(r.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.CheckReplicas(ctx, interval)
+		}
+	}
+}
+
+// CheckReplicas pings every replica and updates its health, logging replicas that go down or recover.
+// It runs periodically on its own and only needs to be called to refresh the health sooner.
+func (r *ReplicaDB) CheckReplicas(ctx context.Context, timeout time.Duration) {
+	for _, rep := range r.replicas {
+		err := PingPostgres(ctx, rep.db, timeout)
+		if ctx.Err() != nil {
+			return
+		}
+
+		healthy := err == nil
+		if rep.healthy.Swap(healthy) != healthy {
+			if healthy {
+				log.Printf("Replica %d recovered", rep.number)
+			} else {
+				log.Printf("Replica %d is unhealthy: %v", rep.number, err)
+			}
+		}
+	}
+}
+
+// Primary returns the primary database, for writes and for reads that must see the latest data.
+func (r *ReplicaDB) Primary() *sql.DB {
+	return r.primary
+}
+
+// Reader returns the next healthy replica in round-robin order, or the primary if no replica is healthy.
+func (r *ReplicaDB) Reader() *sql.DB {
+	if rep := r.nextReplica(); rep != nil {
+		return rep.db
+	}
+	return r.primary
+}
+
+// nextReplica returns the next healthy replica, or nil if there is none.
+func (r *ReplicaDB) nextReplica() *replica {
+	count := uint64(len(r.replicas))
+	if count == 0 {
+		return nil
+	}
+
+	start := r.next.Add(1) - 1
+	for i := uint64(0); i < count; i++ {
+		rep := r.replicas[(start+i)%count]
+		if rep.healthy.Load() {
+			return rep
+		}
+	}
+	return nil
+}
+
+// HealthyReplicas returns how many replicas are currently in rotation.
+func (r *ReplicaDB) HealthyReplicas() int {
+	healthy := 0
+	for _, rep := range r.replicas {
+		if rep.healthy.Load() {
+			healthy++
+		}
+	}
+	return healthy
+}
+
+// QueryContext runs a query on a healthy replica, retrying on the primary if the replica turns out to be down.
+func (r *ReplicaDB) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	if rep := r.nextReplica(); rep != nil {
+		rows, err := rep.db.QueryContext(ctx, query, args...)
+		if err == nil || !isConnectionError(err) {
+			return rows, err
+		}
+		r.markUnhealthy(rep, err)
+	}
+	return r.primary.QueryContext(ctx, query, args...)
+}
+
+// QueryRowContext runs a query expected to return at most one row on a healthy replica, or on the primary
+// if no replica is healthy. Errors are deferred until Scan, as with sql.DB.
+func (r *ReplicaDB) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	return r.Reader().QueryRowContext(ctx, query, args...)
+}
+
+// ExecContext runs a statement on the primary.
+func (r *ReplicaDB) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return r.primary.ExecContext(ctx, query, args...)
+}
+
+// BeginTx starts a transaction on the primary.
+func (r *ReplicaDB) BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) {
+	return r.primary.BeginTx(ctx, opts)
+}
+
+// Close stops the health checks and closes the primary and every replica.
+func (r *ReplicaDB) Close() error {
+	var err error
+	r.closing.Do(func() {
+		r.cancel()
+		<-r.done
+
+		errs := []error{r.primary.Close()}
+		for _, rep := range r.replicas {
+			errs = append(errs, rep.db.Close())
+		}
+		err = errors.Join(errs...)
+	})
+	return err
+}
+
+// markUnhealthy takes a replica out of rotation after a failed query.
+func (r *ReplicaDB) markUnhealthy(rep *replica, err error) {
+	if rep.healthy.Swap(false) {
+		log.Printf("Replica %d is unhealthy: %v", rep.number, err)
+	}
+}
+
+// isConnectionError reports whether err means the database could not be reached, as opposed to a failing query.
+func isConnectionError(err error) bool {
+	if errors.Is(err, driver.ErrBadConn) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
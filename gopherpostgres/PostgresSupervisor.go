@@ -0,0 +1,106 @@
+package gopherpostgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// defaultMaxIdleConns is the database/sql default restored after draining a pool configured without MaxIdleConns.
+const defaultMaxIdleConns = 2
+
+// StartPoolSupervisor pings the database every interval in the background and, when a ping fails,
+// drains the pool and re-establishes it, so that the first queries after a database restart or
+// failover do not fail one by one on dead pooled connections.
+//
+// Draining closes every idle connection; connections in use are discarded by the driver once their
+// query fails. The supervisor then pings with the backoff given WithRetryDelay and WithBackoff until
+// the database answers, reporting each failed attempt to the WithOnRetry callback, or the standard
+// log without one. The supervisor stops once ctx is cancelled.
+//
+// Params:
+//
+//	ctx - The context whose cancellation stops the supervisor.
+//	db - The connection pool to supervise, e.g. one returned by ConnectPostgresDB.
+//	interval - The time between two health pings; each ping may take at most interval.
+//	opts - The options the pool was connected with, so that its MaxIdleConns is restored after draining,
+//	    plus WithOnRetry, WithRetryDelay and WithBackoff for the reconnection.
+//
+// Example usage:
+//
+//	db, err := ConnectPostgresDB(ctx, dsn, 10*time.Second, 3, opts...)
+//	if err != nil {
+//	    log.Fatalf("Failed to connect to PostgreSQL: %v", err)
+//	}
+//	StartPoolSupervisor(ctx, db, 15*time.Second, opts...)
+func StartPoolSupervisor(ctx context.Context, db *sql.DB, interval time.Duration, opts ...Option) {
+	options := newConnectOptions(opts)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			if err := PingPostgres(ctx, db, interval); err != nil && ctx.Err() == nil {
+				options.recoverPool(ctx, db, interval, err)
+			}
+		}
+	}()
+}
+
+// StartPoolSupervisorGORM is StartPoolSupervisor for a connection opened with GORM.
+//
+// Returns:
+//
+//	error - An error if the underlying database connection cannot be retrieved from GORM.
+func StartPoolSupervisorGORM(ctx context.Context, db *gorm.DB, interval time.Duration, opts ...Option) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return fmt.Errorf("failed to get database connection from GORM: %w", err)
+	}
+	StartPoolSupervisor(ctx, sqlDB, interval, opts...)
+	return nil
+}
+
+// recoverPool drains the pool after the failed ping err and pings with backoff until the database
+// answers again or ctx is cancelled.
+func (o *connectOptions) recoverPool(ctx context.Context, db *sql.DB, timeout time.Duration, err error) {
+	log.Printf("Lost connection to PostgreSQL, draining the pool: %v", err)
+	o.drainIdle(db)
+
+	for attempt := 1; ; attempt++ {
+		o.reportFailure(attempt, err)
+		if wait(ctx, o.backoff(attempt-1)) != nil {
+			return
+		}
+
+		if err = PingPostgres(ctx, db, timeout); err == nil {
+			log.Printf("Reconnected to PostgreSQL after %d failed attempts", attempt)
+			return
+		}
+		if ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+// drainIdle closes every idle connection of db, then restores the idle limit for new connections.
+func (o *connectOptions) drainIdle(db *sql.DB) {
+	maxIdle := defaultMaxIdleConns
+	if o.pool != nil && o.pool.MaxIdleConns > 0 {
+		maxIdle = o.pool.MaxIdleConns
+	}
+
+	db.SetMaxIdleConns(0)
+	db.SetMaxIdleConns(maxIdle)
+}
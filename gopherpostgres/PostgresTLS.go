@@ -0,0 +1,135 @@
+package gopherpostgres
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"database/sql"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// TLSConfig describes how connections to the server are secured, as an alternative to the sslmode,
+// sslrootcert, sslcert and sslkey DSN parameters.
+//
+// Fields:
+//
+//	Config - The base TLS configuration, e.g. with a custom RootCAs pool or GetClientCertificate; nil starts
+//	    from one requiring TLS 1.2 or later. Its ServerName defaults to the host of the DSN.
+//	CAFile - A PEM file with the certificates the server certificate must chain to, e.g. the CA bundle of a
+//	    managed database. It replaces the RootCAs of Config; empty keeps them, or the system pool.
+//	CertFile, KeyFile - A PEM certificate and private key presented to the server for mutual TLS.
+type TLSConfig struct {
+	Config   *tls.Config
+	CAFile   string
+	CertFile string
+	KeyFile  string
+}
+
+// WithTLS connects over TLS with the server certificate verified against the host name, whatever
+// sslmode the DSN asks for. Connections are then made through the pgx driver instead of lib/pq,
+// which cannot take a *tls.Config; the connect functions return the same types either way.
+//
+// Example usage:
+//
+//	db, err := ConnectPostgresDB(ctx, dsn, 10*time.Second, 3, WithTLS(TLSConfig{
+//	    CAFile:   "/etc/ssl/db/ca.pem",
+//	    CertFile: "/etc/ssl/db/client.pem",
+//	    KeyFile:  "/etc/ssl/db/client.key",
+//	}))
+func WithTLS(config TLSConfig) Option {
+	return func(o *connectOptions) {
+		o.tls = &config
+	}
+}
+
+// clientConfig builds the TLS configuration from the configured files over the base Config.
+func (c *TLSConfig) clientConfig() (*tls.Config, error) {
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+	if c.Config != nil {
+		config = c.Config.Clone()
+	}
+
+	if c.CAFile != "" {
+		caPEM, err := os.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file %s: %w", c.CAFile, err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("no PEM certificates found in CA file %s", c.CAFile)
+		}
+		config.RootCAs = pool
+	}
+
+	if c.CertFile != "" || c.KeyFile != "" {
+		certificate, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		config.Certificates = append(config.Certificates, certificate)
+	}
+
+	return config, nil
+}
+
+// openDB opens a connection pool for dsn without connecting yet: with lib/pq, or with pgx when TLS
+// is configured WithTLS.
+func (o *connectOptions) openDB(dsn string) (*sql.DB, error) {
+	if o.tls == nil {
+		return sql.Open("postgres", dsn)
+	}
+
+	config, err := pgx.ParseConfig(dsn)
+	if err != nil {
+		return nil, invalidDSNError(err)
+	}
+	tlsConfig, err := o.tls.clientConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	// Every host tried, including the fallbacks of a multi-host DSN, gets TLS for its own name
+	config.TLSConfig = tlsConfigFor(tlsConfig, config.Host)
+	for _, fallback := range config.Fallbacks {
+		fallback.TLSConfig = tlsConfigFor(tlsConfig, fallback.Host)
+	}
+	return stdlib.OpenDB(*config), nil
+}
+
+// openGORM opens dsn with GORM, which connects and pings, going through openDB when TLS is configured.
+func (o *connectOptions) openGORM(dsn string, config *gorm.Config) (*gorm.DB, error) {
+	if o.tls == nil {
+		return gorm.Open(postgres.Open(dsn), config)
+	}
+
+	sqlDB, err := o.openDB(dsn)
+	if err != nil {
+		return nil, err
+	}
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), config)
+	if err != nil {
+		sqlDB.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// tlsConfigFor returns the TLS configuration for a connection to host, with the host as ServerName
+// unless one was given. Unix sockets get none, as TLS does not apply to them.
+func tlsConfigFor(base *tls.Config, host string) *tls.Config {
+	if strings.HasPrefix(host, "/") {
+		return nil
+	}
+
+	config := base.Clone()
+	if config.ServerName == "" {
+		config.ServerName = host
+	}
+	return config
+}
@@ -0,0 +1,193 @@
+package gopherpostgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"log"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// migrationSuffix is the file name suffix of the migrations applied by RunMigrations.
+const migrationSuffix = ".up.sql"
+
+// Migration is one schema migration read from a migrations directory.
+//
+// Fields:
+//
+//	Version - The numeric prefix of the file name, e.g. 3 for "0003_add_email_index.up.sql".
+//	Name - The file name.
+type Migration struct {
+	Version int64
+	Name    string
+}
+
+// RunMigrations applies the pending "*.up.sql" migrations found in dir, in version order.
+//
+// A migration's version is the number before the first underscore of its file name, e.g.
+// "0001_create_users.up.sql" has version 1. Applied versions are recorded in a schema_migrations
+// table, created on first use, so running the migrations again only applies the new ones. Each
+// migration runs in its own transaction together with its record, so a failing migration leaves
+// no partial changes behind and stops the run.
+//
+// Params:
+//
+//	ctx - The context for cancelling the migrations.
+//	db - The database to migrate.
+//	migrationsFS - The file system holding the migrations, typically an embed.FS.
+//	dir - The directory of the migrations within migrationsFS, "." for its root.
+//
+// Returns:
+//
+//	error - An error if a migration cannot be read or applied.
+//
+// Example usage:
+//
+//	//go:embed migrations/*.sql
+//	var migrations embed.FS
+//
+//	if err := RunMigrations(ctx, db, migrations, "migrations"); err != nil {
+//	    log.Fatalf("Failed to migrate the database: %v", err)
+//	}
+func RunMigrations(ctx context.Context, db *sql.DB, migrationsFS fs.FS, dir string) error {
+	pending, err := PendingMigrations(ctx, db, migrationsFS, dir)
+	if err != nil {
+		return err
+	}
+
+	for _, migration := range pending {
+		if err := applyMigration(ctx, db, migrationsFS, dir, migration); err != nil {
+			return err
+		}
+		log.Printf("Applied migration %s", migration.Name)
+	}
+
+	return nil
+}
+
+// PendingMigrations lists the migrations in dir that RunMigrations would apply, in the order it
+// would apply them, without changing the database beyond creating the schema_migrations table.
+// Use it to preview a run.
+//
+// Params:
+//
+//	ctx - The context for cancelling the lookup.
+//	db - The database to check.
+//	migrationsFS - The file system holding the migrations.
+//	dir - The directory of the migrations within migrationsFS.
+//
+// Returns:
+//
+//	[]Migration - The migrations not applied yet, sorted by version.
+//	error - An error if the migrations cannot be listed or the applied versions cannot be read.
+func PendingMigrations(ctx context.Context, db *sql.DB, migrationsFS fs.FS, dir string) ([]Migration, error) {
+	migrations, err := listMigrations(migrationsFS, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version BIGINT PRIMARY KEY,
+		applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`); err != nil {
+		return nil, fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+
+	applied, err := appliedVersions(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []Migration
+	for _, migration := range migrations {
+		if !applied[migration.Version] {
+			pending = append(pending, migration)
+		}
+	}
+	return pending, nil
+}
+
+// listMigrations returns the migrations in dir sorted by version, rejecting duplicate versions.
+func listMigrations(migrationsFS fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(migrationsFS, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations directory %s: %w", dir, err)
+	}
+
+	var migrations []Migration
+	seen := make(map[int64]string)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, migrationSuffix) {
+			continue
+		}
+
+		prefix, _, _ := strings.Cut(strings.TrimSuffix(name, migrationSuffix), "_")
+		version, err := strconv.ParseInt(prefix, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("migration %s does not start with a numeric version", name)
+		}
+		if other, ok := seen[version]; ok {
+			return nil, fmt.Errorf("migrations %s and %s share version %d", other, name, version)
+		}
+		seen[version] = name
+
+		migrations = append(migrations, Migration{Version: version, Name: name})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// appliedVersions returns the versions recorded in the schema_migrations table.
+func appliedVersions(ctx context.Context, db *sql.DB) (map[int64]bool, error) {
+	rows, err := db.QueryContext(ctx, "SELECT version FROM schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read applied migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int64]bool)
+	for rows.Next() {
+		var version int64
+		if err := rows.Scan(&version); err != nil {
+			return nil, fmt.Errorf("failed to read applied migrations: %w", err)
+		}
+		applied[version] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read applied migrations: %w", err)
+	}
+	return applied, nil
+}
+
+// applyMigration runs one migration and records its version in a single transaction.
+func applyMigration(ctx context.Context, db *sql.DB, migrationsFS fs.FS, dir string, migration Migration) error {
+	script, err := fs.ReadFile(migrationsFS, path.Join(dir, migration.Name))
+	if err != nil {
+		return fmt.Errorf("failed to read migration %s: %w", migration.Name, err)
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction for migration %s: %w", migration.Name, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, string(script)); err != nil {
+		return fmt.Errorf("failed to apply migration %s: %w", migration.Name, err)
+	}
+	if _, err := tx.ExecContext(ctx, "INSERT INTO schema_migrations (version) VALUES ($1)", migration.Version); err != nil {
+		return fmt.Errorf("failed to record migration %s: %w", migration.Name, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit migration %s: %w", migration.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package gopherpostgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// WithTransaction runs fn inside a database transaction.
+//
+// The transaction is committed when fn returns nil and rolled back when fn returns an error or panics.
+// A panic is re-raised after the rollback, so it still reaches the caller's recovery handling.
+//
+// Params:
+//
+//	ctx - The context of the transaction; cancelling it rolls the transaction back.
+//	db - The database to run the transaction on.
+//	fn - The work to do; every statement must go through tx.
+//
+// Returns:
+//
+//	error - The error returned by fn, or an error if the transaction cannot be started, committed or rolled back.
+//
+// Example usage:
+//
+//	err := WithTransaction(ctx, db, func(tx *sql.Tx) error {
+//	    if _, err := tx.ExecContext(ctx, "UPDATE accounts SET balance = balance - $1 WHERE id = $2", amount, from); err != nil {
+//	        return err
+//	    }
+//	    _, err := tx.ExecContext(ctx, "UPDATE accounts SET balance = balance + $1 WHERE id = $2", amount, to)
+//	    return err
+//	})
+func WithTransaction(ctx context.Context, db *sql.DB, fn func(tx *sql.Tx) error) (err error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	committed := false
+	defer func() {
+		if committed {
+			return
+		}
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+		if rollbackErr := tx.Rollback(); rollbackErr != nil && !errors.Is(rollbackErr, sql.ErrTxDone) {
+			err = errors.Join(err, fmt.Errorf("failed to roll back transaction: %w", rollbackErr))
+		}
+	}()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	committed = true
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// WithTransactionGORM is WithTransaction for a connection opened with GORM. fn must use the given tx
+// rather than db for its queries.
+//
+// Example usage:
+//
+//	err := WithTransactionGORM(ctx, db, func(tx *gorm.DB) error {
+//	    if err := tx.Create(&order).Error; err != nil {
+//	        return err
+//	    }
+//	    return tx.Model(&stock).Update("quantity", gorm.Expr("quantity - ?", order.Quantity)).Error
+//	})
+func WithTransactionGORM(ctx context.Context, db *gorm.DB, fn func(tx *gorm.DB) error) error {
+	// gorm.DB.Transaction already commits on success and rolls back on error or panic
+	return db.WithContext(ctx).Transaction(fn)
+}
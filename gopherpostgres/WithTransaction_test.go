@@ -0,0 +1,141 @@
+package gopherpostgres
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+)
+
+// fakeDB is a database/sql connector whose connections record the statements and transaction
+// commands they receive.
+type fakeDB struct {
+	mu        sync.Mutex
+	log       []string
+	commitErr error
+}
+
+func (d *fakeDB) Connect(context.Context) (driver.Conn, error) { return &fakeConn{db: d}, nil }
+func (d *fakeDB) Driver() driver.Driver                        { return nil }
+
+// record appends an entry to the log.
+func (d *fakeDB) record(entry string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.log = append(d.log, entry)
+}
+
+// Log returns the entries recorded so far.
+func (d *fakeDB) Log() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]string(nil), d.log...)
+}
+
+// fakeConn is a connection of a fakeDB.
+type fakeConn struct {
+	db *fakeDB
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("prepared statements are not supported")
+}
+func (c *fakeConn) Close() error { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	c.db.record("BEGIN")
+	return &fakeTx{db: c.db}, nil
+}
+func (c *fakeConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.db.record(query)
+	return driver.RowsAffected(1), nil
+}
+
+// fakeTx is a transaction of a fakeConn.
+type fakeTx struct {
+	db *fakeDB
+}
+
+func (tx *fakeTx) Commit() error {
+	tx.db.record("COMMIT")
+	return tx.db.commitErr
+}
+func (tx *fakeTx) Rollback() error {
+	tx.db.record("ROLLBACK")
+	return nil
+}
+
+func TestWithTransaction(t *testing.T) {
+	errFailed := errors.New("insufficient funds")
+	commitErr := errors.New("serialization failure")
+
+	tests := []struct {
+		name      string
+		fn        func(tx *sql.Tx) error
+		commitErr error
+		wantErr   error
+		wantPanic bool
+		wantLog   []string
+	}{
+		{
+			name:    "commit",
+			fn:      func(tx *sql.Tx) error { _, err := tx.Exec("UPDATE accounts"); return err },
+			wantLog: []string{"BEGIN", "UPDATE accounts", "COMMIT"},
+		},
+		{
+			name: "error rolls back",
+			fn: func(tx *sql.Tx) error {
+				tx.Exec("UPDATE accounts")
+				return errFailed
+			},
+			wantErr: errFailed,
+			wantLog: []string{"BEGIN", "UPDATE accounts", "ROLLBACK"},
+		},
+		{
+			name: "panic rolls back",
+			fn: func(tx *sql.Tx) error {
+				tx.Exec("UPDATE accounts")
+				panic("boom")
+			},
+			wantPanic: true,
+			wantLog:   []string{"BEGIN", "UPDATE accounts", "ROLLBACK"},
+		},
+		{
+			name:      "failed commit",
+			fn:        func(tx *sql.Tx) error { return nil },
+			commitErr: commitErr,
+			wantErr:   commitErr,
+			wantLog:   []string{"BEGIN", "COMMIT"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeDB{commitErr: tt.commitErr}
+			db := sql.OpenDB(fake)
+			defer db.Close()
+
+			var err error
+			panicked := func() (panicked bool) {
+				defer func() {
+					if r := recover(); r != nil {
+						panicked = true
+					}
+				}()
+				err = WithTransaction(context.Background(), db, tt.fn)
+				return false
+			}()
+
+			if panicked != tt.wantPanic {
+				t.Fatalf("panicked: %v, want %v", panicked, tt.wantPanic)
+			}
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && err != nil) {
+				t.Fatalf("WithTransaction returned %v, want %v", err, tt.wantErr)
+			}
+			if got := fake.Log(); !slices.Equal(got, tt.wantLog) {
+				t.Errorf("database received %v, want %v", got, tt.wantLog)
+			}
+		})
+	}
+}
@@ -0,0 +1,377 @@
+package gophersmtp
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/emersion/go-imap"
+	"github.com/emersion/go-imap/client"
+)
+
+// inboxName is the IMAP folder a Mailbox reads from.
+const inboxName = "INBOX"
+
+// ErrMailboxNotConnected is returned by Mailbox methods called before Connect or after Close.
+var ErrMailboxNotConnected = errors.New("mailbox is not connected")
+
+// ReceivedEmail is a message fetched from an IMAP mailbox.
+type ReceivedEmail struct {
+	UID     uint32
+	Subject string
+	From    string
+	Date    time.Time
+	Body    string
+}
+
+// Mailbox reads incoming email from the INBOX of an IMAP server over implicit TLS (usually port 993).
+//
+// A Mailbox holds a single connection and is safe for concurrent use; calls are serialised. A call
+// interrupted by its context drops the connection, so the next Connect dials a new one.
+type Mailbox struct {
+	imapHost  string
+	imapPort  string
+	username  string
+	password  string
+	tlsConfig *tls.Config
+
+	mu     sync.Mutex
+	conn   net.Conn
+	client *client.Client
+}
+
+// NewMailbox creates a Mailbox for the given IMAP account. Call Connect before reading messages.
+//
+// Params:
+//   - imapHost: The host of the IMAP server.
+//   - imapPort: The port of the IMAP server, usually 993.
+//   - username: The login of the mailbox.
+//   - password: The password of the mailbox.
+//   - tlsConfig: Optional TLS settings; nil verifies the server certificate against imapHost.
+//
+// Example usage:
+//
+//	mailbox := NewMailbox("imap.gmail.com", "993", "me@gmail.com", "app-password", nil)
+//	if err := mailbox.Connect(ctx); err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer mailbox.Close()
+//	emails, err := mailbox.ListUnread(ctx)
+func NewMailbox(imapHost, imapPort, username, password string, tlsConfig *tls.Config) GopherMailboxInterface {
+	return &Mailbox{
+		imapHost:  imapHost,
+		imapPort:  imapPort,
+		username:  username,
+		password:  password,
+		tlsConfig: tlsConfig,
+	}
+}
+
+// Connect dials the IMAP server, logs in and selects the INBOX.
+//
+// Returns:
+//   - error: ctx.Err() if the context ended first, otherwise an error if connecting, logging in or
+//     selecting the INBOX fails.
+func (m *Mailbox) Connect(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.client != nil {
+		return nil
+	}
+
+	tlsConfig := &tls.Config{ServerName: m.imapHost}
+	if m.tlsConfig != nil {
+		tlsConfig = m.tlsConfig.Clone()
+		if tlsConfig.ServerName == "" {
+			tlsConfig.ServerName = m.imapHost
+		}
+	}
+
+	addr := net.JoinHostPort(m.imapHost, m.imapPort)
+	dialer := &tls.Dialer{Config: tlsConfig}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to connect to IMAP server %s: %w", addr, err)
+	}
+
+	m.conn = conn
+	err = m.withConnContext(ctx, func() error {
+		c, err := client.New(conn)
+		if err != nil {
+			return fmt.Errorf("failed to create IMAP client for %s: %w", addr, err)
+		}
+		if err := c.Login(m.username, m.password); err != nil {
+			return fmt.Errorf("IMAP login failed: %w", err)
+		}
+		if _, err := c.Select(inboxName, false); err != nil {
+			return fmt.Errorf("failed to select %s: %w", inboxName, err)
+		}
+		m.client = c
+		return nil
+	})
+	if err != nil {
+		conn.Close()
+		m.client, m.conn = nil, nil
+		return err
+	}
+	return nil
+}
+
+// ListUnread fetches every unread message in the INBOX without marking it as read.
+//
+// Returns:
+//   - []ReceivedEmail: The unread messages in ascending UID order.
+//   - error: ErrMailboxNotConnected before Connect, otherwise an error if searching or fetching fails.
+func (m *Mailbox) ListUnread(ctx context.Context) ([]ReceivedEmail, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.client == nil {
+		return nil, ErrMailboxNotConnected
+	}
+
+	var emails []ReceivedEmail
+	err := m.withConnContext(ctx, func() error {
+		criteria := imap.NewSearchCriteria()
+		criteria.WithoutFlags = []string{imap.SeenFlag}
+		uids, err := m.client.UidSearch(criteria)
+		if err != nil {
+			return fmt.Errorf("failed to search unread messages: %w", err)
+		}
+		if len(uids) == 0 {
+			return nil
+		}
+
+		emails, err = m.fetch(uids...)
+		return err
+	})
+	return emails, err
+}
+
+// FetchMessage fetches the message with the given UID without marking it as read.
+//
+// Returns:
+//   - *ReceivedEmail: The parsed message.
+//   - error: ErrMailboxNotConnected before Connect, or an error if the message does not exist or cannot be parsed.
+func (m *Mailbox) FetchMessage(ctx context.Context, uid uint32) (*ReceivedEmail, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.client == nil {
+		return nil, ErrMailboxNotConnected
+	}
+
+	var emails []ReceivedEmail
+	err := m.withConnContext(ctx, func() error {
+		var err error
+		emails, err = m.fetch(uid)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(emails) == 0 {
+		return nil, fmt.Errorf("message with UID %d not found", uid)
+	}
+	return &emails[0], nil
+}
+
+// MarkRead flags the message with the given UID as seen.
+//
+// Returns:
+//   - error: ErrMailboxNotConnected before Connect, otherwise an error if the server refuses the change.
+func (m *Mailbox) MarkRead(ctx context.Context, uid uint32) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.client == nil {
+		return ErrMailboxNotConnected
+	}
+
+	return m.withConnContext(ctx, func() error {
+		seqSet := new(imap.SeqSet)
+		seqSet.AddNum(uid)
+		item := imap.FormatFlagsOp(imap.AddFlags, true)
+		if err := m.client.UidStore(seqSet, item, []interface{}{imap.SeenFlag}, nil); err != nil {
+			return fmt.Errorf("failed to mark message %d as read: %w", uid, err)
+		}
+		return nil
+	})
+}
+
+// Close logs out and closes the connection. Calling Close on a Mailbox that is not connected is a no-op.
+func (m *Mailbox) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.client == nil {
+		return nil
+	}
+
+	err := m.client.Logout()
+	m.conn.Close()
+	m.client = nil
+	m.conn = nil
+	return err
+}
+
+// fetch downloads and parses the messages with the given UIDs, using BODY.PEEK so they stay unread.
+func (m *Mailbox) fetch(uids ...uint32) ([]ReceivedEmail, error) {
+	seqSet := new(imap.SeqSet)
+	seqSet.AddNum(uids...)
+	section := &imap.BodySectionName{Peek: true}
+	items := []imap.FetchItem{section.FetchItem(), imap.FetchUid}
+
+	messages := make(chan *imap.Message, len(uids))
+	done := make(chan error, 1)
+	go func() {
+		done <- m.client.UidFetch(seqSet, items, messages)
+	}()
+
+	var emails []ReceivedEmail
+	var parseErr error
+	for msg := range messages {
+		literal := msg.GetBody(section)
+		if literal == nil {
+			continue
+		}
+
+		email, err := parseReceivedEmail(literal)
+		if err != nil {
+			if parseErr == nil {
+				parseErr = fmt.Errorf("failed to parse message %d: %w", msg.Uid, err)
+			}
+			continue
+		}
+		email.UID = msg.Uid
+		emails = append(emails, email)
+	}
+
+	if err := <-done; err != nil {
+		return nil, fmt.Errorf("failed to fetch messages: %w", err)
+	}
+	if parseErr != nil {
+		return nil, parseErr
+	}
+	return emails, nil
+}
+
+// withConnContext runs fn, interrupting any blocking read or write on the connection once ctx ends.
+// An interrupted connection cannot be reused, so it is closed and dropped, and the next Connect dials
+// a new one. m.mu must be held.
+func (m *Mailbox) withConnContext(ctx context.Context, fn func() error) error {
+	conn := m.conn
+	stop := context.AfterFunc(ctx, func() {
+		conn.SetDeadline(time.Now())
+	})
+
+	err := fn()
+	if !stop() {
+		conn.Close()
+		m.client, m.conn = nil, nil
+		return ctx.Err()
+	}
+	return err
+}
+
+// parseReceivedEmail parses a raw RFC 5322 message into a ReceivedEmail.
+//
+// The body is the first text/plain part of a multipart message, falling back to the first text/html
+// part, and is returned with its transfer encoding removed.
+func parseReceivedEmail(r io.Reader) (ReceivedEmail, error) {
+	msg, err := mail.ReadMessage(r)
+	if err != nil {
+		return ReceivedEmail{}, err
+	}
+
+	decoder := new(mime.WordDecoder)
+	subject, err := decoder.DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil {
+		subject = msg.Header.Get("Subject")
+	}
+	from, err := decoder.DecodeHeader(msg.Header.Get("From"))
+	if err != nil {
+		from = msg.Header.Get("From")
+	}
+
+	// A missing or malformed Date header leaves Date as the zero time
+	date, _ := msg.Header.Date()
+
+	body, err := readTextBody(msg.Header.Get("Content-Type"), msg.Header.Get("Content-Transfer-Encoding"), msg.Body)
+	if err != nil {
+		return ReceivedEmail{}, err
+	}
+
+	return ReceivedEmail{Subject: subject, From: from, Date: date, Body: body}, nil
+}
+
+// readTextBody returns the decoded text of a message body, descending into multipart bodies.
+func readTextBody(contentType, transferEncoding string, body io.Reader) (string, error) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = "text/plain"
+	}
+
+	if !strings.HasPrefix(mediaType, "multipart/") {
+		data, err := io.ReadAll(decodeTransferEncoding(transferEncoding, body))
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
+	}
+
+	// Prefer the plain text alternative, but keep the first HTML part in case there is none
+	var html string
+	reader := multipart.NewReader(body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return html, nil
+		}
+		if err != nil {
+			return "", err
+		}
+
+		partType := part.Header.Get("Content-Type")
+		partMediaType, _, _ := mime.ParseMediaType(partType)
+		switch {
+		case partMediaType == "" || partMediaType == "text/plain" || strings.HasPrefix(partMediaType, "multipart/"):
+			text, err := readTextBody(partType, part.Header.Get("Content-Transfer-Encoding"), part)
+			if err != nil {
+				return "", err
+			}
+			if text != "" {
+				return text, nil
+			}
+		case partMediaType == "text/html" && html == "":
+			html, err = readTextBody(partType, part.Header.Get("Content-Transfer-Encoding"), part)
+			if err != nil {
+				return "", err
+			}
+		}
+	}
+}
+
+// decodeTransferEncoding wraps body in a decoder for its Content-Transfer-Encoding.
+func decodeTransferEncoding(transferEncoding string, body io.Reader) io.Reader {
+	switch strings.ToLower(strings.TrimSpace(transferEncoding)) {
+	case "base64":
+		return base64.NewDecoder(base64.StdEncoding, body)
+	case "quoted-printable":
+		return quotedprintable.NewReader(body)
+	default:
+		return body
+	}
+}
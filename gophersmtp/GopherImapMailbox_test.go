@@ -0,0 +1,151 @@
+package gophersmtp
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/textproto"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeIMAPMessage is the single unread message in the INBOX of a fakeIMAPServer.
+const fakeIMAPMessage = "From: alice@example.com\r\nSubject: Hello\r\nContent-Type: text/plain\r\n\r\nHi there\r\n"
+
+// fakeIMAPServer serves an INBOX holding fakeIMAPMessage over implicit TLS. The first UID FETCH it
+// receives is left unanswered until the connection closes, so a client can be interrupted mid-fetch.
+type fakeIMAPServer struct {
+	listener net.Listener
+	fetching chan struct{}
+
+	mu          sync.Mutex
+	connections int
+	stalled     bool
+}
+
+// newFakeIMAPServer starts a fakeIMAPServer that is stopped when the test ends.
+func newFakeIMAPServer(t *testing.T, tlsConfig *tls.Config) *fakeIMAPServer {
+	t.Helper()
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", tlsConfig)
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	server := &fakeIMAPServer{listener: listener, fetching: make(chan struct{})}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			server.mu.Lock()
+			server.connections++
+			server.mu.Unlock()
+			go server.serve(conn)
+		}
+	}()
+	return server
+}
+
+// Connections returns the number of connections accepted so far.
+func (s *fakeIMAPServer) Connections() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.connections
+}
+
+// serve answers the commands of one connection.
+func (s *fakeIMAPServer) serve(conn net.Conn) {
+	defer conn.Close()
+	text := textproto.NewConn(conn)
+
+	text.PrintfLine("* OK [CAPABILITY IMAP4rev1] fake IMAP server ready")
+	for {
+		line, err := text.ReadLine()
+		if err != nil {
+			return
+		}
+		tag, command, _ := strings.Cut(line, " ")
+		command = strings.ToUpper(command)
+
+		switch {
+		case strings.HasPrefix(command, "CAPABILITY"):
+			text.PrintfLine("* CAPABILITY IMAP4rev1")
+		case strings.HasPrefix(command, "LOGIN"):
+		case strings.HasPrefix(command, "SELECT"):
+			text.PrintfLine("* 1 EXISTS")
+			text.PrintfLine("* 0 RECENT")
+			text.PrintfLine(`* FLAGS (\Seen)`)
+			text.PrintfLine("* OK [UIDVALIDITY 1] UIDs valid")
+			text.PrintfLine("%s OK [READ-WRITE] SELECT completed", tag)
+			continue
+		case strings.HasPrefix(command, "UID SEARCH"):
+			text.PrintfLine("* SEARCH 1")
+		case strings.HasPrefix(command, "UID FETCH"):
+			s.mu.Lock()
+			stall := !s.stalled
+			s.stalled = true
+			s.mu.Unlock()
+			if stall {
+				close(s.fetching)
+				// Wait for the client to give up and close the connection
+				text.ReadLine()
+				return
+			}
+			text.PrintfLine("* 1 FETCH (UID 1 BODY[] {%d}\r\n%s)", len(fakeIMAPMessage), fakeIMAPMessage)
+		case strings.HasPrefix(command, "LOGOUT"):
+			text.PrintfLine("* BYE logging out")
+			text.PrintfLine("%s OK LOGOUT completed", tag)
+			return
+		default:
+			text.PrintfLine("%s BAD unknown command", tag)
+			continue
+		}
+		text.PrintfLine("%s OK completed", tag)
+	}
+}
+
+func TestMailboxReconnectsAfterCancelledFetch(t *testing.T) {
+	serverTLS, clientTLS := newTestTLSConfigs(t)
+	server := newFakeIMAPServer(t, serverTLS)
+	host, port, _ := net.SplitHostPort(server.listener.Addr().String())
+	mailbox := NewMailbox(host, port, "me@example.com", "password", clientTLS)
+	defer mailbox.Close()
+
+	if err := mailbox.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+
+	// Cancel once the server has received the fetch, leaving the connection mid-exchange
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-server.fetching
+		cancel()
+	}()
+	if _, err := mailbox.ListUnread(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("ListUnread returned %v, want %v", err, context.Canceled)
+	}
+	if _, err := mailbox.ListUnread(context.Background()); !errors.Is(err, ErrMailboxNotConnected) {
+		t.Fatalf("ListUnread after the interruption returned %v, want %v", err, ErrMailboxNotConnected)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := mailbox.Connect(ctx); err != nil {
+		t.Fatalf("Connect after the interruption failed: %v", err)
+	}
+	if got := server.Connections(); got != 2 {
+		t.Errorf("server accepted %d connections, want Connect to dial a second one", got)
+	}
+	emails, err := mailbox.ListUnread(ctx)
+	if err != nil {
+		t.Fatalf("ListUnread after reconnecting failed: %v", err)
+	}
+	if len(emails) != 1 || emails[0].UID != 1 || emails[0].Subject != "Hello" {
+		t.Fatalf("ListUnread returned %+v, want the message with UID 1", emails)
+	}
+}
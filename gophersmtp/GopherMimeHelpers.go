@@ -0,0 +1,344 @@
+package gophersmtp
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// BodyEncoding is the Content-Transfer-Encoding applied to text/plain and text/html bodies.
+type BodyEncoding string
+
+const (
+	// BodyEncoding8Bit sends the body unencoded, which some relays mangle when it contains non-ASCII text.
+	BodyEncoding8Bit BodyEncoding = ""
+	// BodyEncodingQuotedPrintable keeps ASCII text readable and escapes everything else.
+	BodyEncodingQuotedPrintable BodyEncoding = "quoted-printable"
+	// BodyEncodingBase64 encodes the whole body, which is more compact for mostly non-ASCII text.
+	BodyEncodingBase64 BodyEncoding = "base64"
+)
+
+// base64LineLength is the maximum length of a base64 encoded line allowed by RFC 2045.
+const base64LineLength = 76
+
+// lineWrapper is an io.Writer that inserts a CRLF after every base64LineLength bytes.
+type lineWrapper struct {
+	w       io.Writer
+	written int
+}
+
+// Write writes p to the underlying writer, breaking it into lines of base64LineLength bytes.
+func (l *lineWrapper) Write(p []byte) (int, error) {
+	n := 0
+	for len(p) > 0 {
+		chunk := base64LineLength - l.written
+		if chunk > len(p) {
+			chunk = len(p)
+		}
+
+		m, err := l.w.Write(p[:chunk])
+		n += m
+		l.written += m
+		if err != nil {
+			return n, err
+		}
+		p = p[chunk:]
+
+		if l.written == base64LineLength {
+			if _, err := l.w.Write([]byte("\r\n")); err != nil {
+				return n, err
+			}
+			l.written = 0
+		}
+	}
+	return n, nil
+}
+
+// writeBase64 streams the contents of r into w as base64 wrapped at 76 characters per line.
+func writeBase64(w io.Writer, r io.Reader) error {
+	encoder := base64.NewEncoder(base64.StdEncoding, &lineWrapper{w: w})
+	if _, err := io.Copy(encoder, r); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// buildMessage composes a single-part email with the given extra header lines.
+//
+// Params:
+//   - subject: The subject of the email.
+//   - body: The content of the email.
+//   - isHtml: A flag indicating whether the body is HTML.
+//   - headers: Extra CRLF-terminated header lines placed before the standard ones.
+//
+// Returns:
+//   - []byte: The composed message, ready to be handed to the SMTP DATA command.
+func (e *EmailService) buildMessage(subject, body string, isHtml bool, headers string) []byte {
+	contentType := "text/plain"
+	if isHtml {
+		contentType = "text/html"
+	}
+
+	encoding, encodedBody := e.encodeBody(body)
+	transferEncoding := ""
+	if encoding != "" {
+		transferEncoding = fmt.Sprintf("Content-Transfer-Encoding: %s\r\n", encoding)
+	}
+
+	msg := fmt.Sprintf("%s%sSubject: %s\r\nMIME-version: 1.0;\r\nContent-Type: %s; charset=\"UTF-8\";\r\n%s\r\n%s",
+		e.addressHeaders(headers), headers, encodeSubject(subject), contentType, transferEncoding, encodedBody)
+	return []byte(msg)
+}
+
+// encodeSubject wraps a subject containing non-ASCII characters in an RFC 2047 encoded-word.
+// ASCII subjects are returned unchanged.
+func encodeSubject(subject string) string {
+	return mime.QEncoding.Encode("UTF-8", subject)
+}
+
+// encodeBody encodes a text body with the encoding chosen WithBodyEncoding.
+//
+// Returns:
+//   - BodyEncoding: The value for the Content-Transfer-Encoding header, empty when the body is sent as is.
+//   - string: The encoded body.
+func (e *EmailService) encodeBody(body string) (BodyEncoding, string) {
+	var buffer bytes.Buffer
+	switch e.bodyEncoding {
+	case BodyEncodingQuotedPrintable:
+		writer := quotedprintable.NewWriter(&buffer)
+		writer.Write([]byte(body))
+		writer.Close()
+	case BodyEncodingBase64:
+		writeBase64(&buffer, strings.NewReader(body))
+	default:
+		return "", body
+	}
+	return e.bodyEncoding, buffer.String()
+}
+
+// senderAddress returns the address used as envelope sender and in the From header.
+func (e *EmailService) senderAddress() string {
+	if e.fromAddress != "" {
+		return e.fromAddress
+	}
+	return e.username
+}
+
+// addressHeaders renders the From and Reply-To header lines, leaving out any that the extra headers already set.
+func (e *EmailService) addressHeaders(headers string) string {
+	var lines strings.Builder
+	if !hasHeader(headers, "From") {
+		from := mail.Address{Name: e.fromName, Address: e.senderAddress()}
+		fmt.Fprintf(&lines, "From: %s\r\n", from.String())
+	}
+	if len(e.replyTo) > 0 && !hasHeader(headers, "Reply-To") {
+		fmt.Fprintf(&lines, "Reply-To: %s\r\n", strings.Join(e.replyTo, ", "))
+	}
+	return lines.String()
+}
+
+// hasHeader reports whether the CRLF-terminated header lines contain the named header.
+func hasHeader(headers, name string) bool {
+	for _, line := range strings.Split(headers, "\r\n") {
+		key, _, found := strings.Cut(line, ":")
+		if found && strings.EqualFold(strings.TrimSpace(key), name) {
+			return true
+		}
+	}
+	return false
+}
+
+// buildMultipartMessage composes a multipart email carrying the body, inline images and attachments.
+//
+// Params:
+//   - multipartType: The multipart subtype, e.g. "multipart/mixed" or "multipart/related".
+//   - subject: The subject of the email.
+//   - body: The content of the email.
+//   - isHtml: A flag indicating whether the body is HTML.
+//   - headers: Extra CRLF-terminated header lines placed before the standard ones.
+//   - attachmentPaths: File paths to attach.
+//   - inlineImagePaths: Image paths to embed inline, referenced from the body by file name.
+//   - attachmentData: Attachments read from memory, added after the files.
+//
+// Returns:
+//   - []byte: The composed message.
+//   - error: An error if a file or attachment cannot be read or a part cannot be written.
+func (e *EmailService) buildMultipartMessage(multipartType, subject, body string, isHtml bool, headers string, attachmentPaths, inlineImagePaths []string, attachmentData []Attachment) ([]byte, error) {
+	contentType := "text/plain"
+	if isHtml {
+		contentType = "text/html"
+	}
+
+	// Refuse too many attachments and oversized messages before any file is read
+	if e.maxAttachments > 0 && len(attachmentPaths)+len(attachmentData) > e.maxAttachments {
+		return nil, &TooManyAttachmentsError{Count: len(attachmentPaths) + len(attachmentData), Limit: e.maxAttachments}
+	}
+	if err := e.checkEstimatedSize(body, attachmentPaths, inlineImagePaths, attachmentData); err != nil {
+		return nil, err
+	}
+
+	var buffer bytes.Buffer
+	writer := multipart.NewWriter(&buffer)
+
+	// Set headers, terminated by the blank line that separates them from the parts
+	fmt.Fprintf(&buffer, "%s%sSubject: %s\r\nMIME-version: 1.0;\r\nContent-Type: %s; boundary=%s\r\n\r\n", e.addressHeaders(headers), headers, encodeSubject(subject), multipartType, writer.Boundary())
+
+	// Add body part
+	encoding, encodedBody := e.encodeBody(body)
+	bodyHeader := map[string][]string{
+		"Content-Type": {contentType + "; charset=\"UTF-8\""},
+	}
+	if encoding != "" {
+		bodyHeader["Content-Transfer-Encoding"] = []string{string(encoding)}
+	}
+	bodyPart, err := writer.CreatePart(bodyHeader)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := bodyPart.Write([]byte(encodedBody)); err != nil {
+		return nil, err
+	}
+
+	// Attach inline images
+	for _, path := range inlineImagePaths {
+		if err := attachInlineImage(writer, path); err != nil {
+			return nil, err
+		}
+	}
+
+	// Attach files
+	for _, path := range attachmentPaths {
+		if err := attachFile(writer, path); err != nil {
+			return nil, err
+		}
+	}
+
+	// Attach in-memory data
+	for _, attachment := range attachmentData {
+		if err := attachData(writer, attachment); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// base64EncodedSize returns the size of n bytes once base64 encoded and wrapped into 76 character lines.
+func base64EncodedSize(n int64) int64 {
+	encoded := (n + 2) / 3 * 4
+	return encoded + encoded/base64LineLength*2
+}
+
+// checkEstimatedSize compares the size of the body plus the encoded files against the limit set
+// WithMaxMessageBytes, using the file sizes on disk so nothing has to be read. In-memory attachments
+// count when their reader reports its length, as bytes.Buffer, bytes.Reader and strings.Reader do;
+// others are only measured once the message is composed.
+func (e *EmailService) checkEstimatedSize(body string, attachmentPaths, inlineImagePaths []string, attachmentData []Attachment) error {
+	if e.maxMessageBytes <= 0 {
+		return nil
+	}
+
+	size := int64(len(body))
+	for _, path := range append(append([]string(nil), attachmentPaths...), inlineImagePaths...) {
+		info, err := os.Stat(path)
+		if err != nil {
+			return fmt.Errorf("failed to read size of %s: %w", path, err)
+		}
+		size += base64EncodedSize(info.Size())
+	}
+	for _, attachment := range attachmentData {
+		if sized, ok := attachment.Data.(interface{ Len() int }); ok {
+			size += base64EncodedSize(int64(sized.Len()))
+		}
+	}
+
+	if size > e.maxMessageBytes {
+		return &MessageTooLargeError{Size: size, Limit: e.maxMessageBytes}
+	}
+	return nil
+}
+
+// contentTypeByExtension returns the MIME type for the file's extension,
+// falling back to application/octet-stream when it is unknown.
+func contentTypeByExtension(path string) string {
+	if contentType := mime.TypeByExtension(filepath.Ext(path)); contentType != "" {
+		return contentType
+	}
+	return "application/octet-stream"
+}
+
+// attachFile adds the file at filePath to the email as a base64 encoded attachment.
+//
+// Params:
+//   - writer: The multipart writer of the email being composed.
+//   - filePath: Path of the file to attach.
+//
+// Returns:
+//   - error: An error if the file cannot be read or the part cannot be written.
+func attachFile(writer *multipart.Writer, filePath string) error {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to open attachment %s: %w", filePath, err)
+	}
+	defer file.Close()
+
+	part, err := writer.CreatePart(map[string][]string{
+		"Content-Type":              {contentTypeByExtension(filePath)},
+		"Content-Transfer-Encoding": {"base64"},
+		"Content-Disposition":       {fmt.Sprintf("attachment; filename=%q", filepath.Base(filePath))},
+	})
+	if err != nil {
+		return err
+	}
+
+	return writeBase64(part, file)
+}
+
+// attachInlineImage embeds the image at imagePath into the email as an inline part.
+//
+// The part carries a Content-ID equal to the image's file name, so the HTML body can
+// reference it with `<img src="cid:logo.png">`.
+//
+// Params:
+//   - writer: The multipart writer of the email being composed.
+//   - imagePath: Path of the image to embed.
+//
+// Returns:
+//   - error: An error naming the image if it cannot be read or the part cannot be written.
+func attachInlineImage(writer *multipart.Writer, imagePath string) error {
+	file, err := os.Open(imagePath)
+	if err != nil {
+		return fmt.Errorf("failed to open inline image %s: %w", imagePath, err)
+	}
+	defer file.Close()
+
+	// Get the file's MIME type
+	mimeType := "image/" + strings.TrimPrefix(filepath.Ext(imagePath), ".")
+	partHeader := make(map[string][]string)
+	partHeader["Content-Type"] = []string{mimeType}
+	partHeader["Content-Transfer-Encoding"] = []string{"base64"}
+	partHeader["Content-Disposition"] = []string{`inline; filename="` + filepath.Base(imagePath) + `"`}
+	partHeader["Content-ID"] = []string{`<` + filepath.Base(imagePath) + `>`}
+
+	part, err := writer.CreatePart(partHeader)
+	if err != nil {
+		return err
+	}
+
+	// Stream the image bytes into the part as base64
+	if err := writeBase64(part, file); err != nil {
+		return fmt.Errorf("failed to encode inline image %s: %w", imagePath, err)
+	}
+	return nil
+}
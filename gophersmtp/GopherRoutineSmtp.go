@@ -1,40 +1,50 @@
 package gophersmtp
 
 import (
-	"bytes"
-	"encoding/base64"
+	"context"
 	"fmt"
-	"io"
+	"html/template"
 	"log"
-	"mime/multipart"
-	"net/smtp"
-	"os"
-	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 )
 
-var EmailResultsChan = make(chan EmailResult)
+// resultsBufferSize is how many results Results() holds for a reader before new ones are dropped.
+const resultsBufferSize = 100
 
+// EmailResult reports the outcome of an asynchronous send.
 type EmailResult struct {
 	Recipient string
 	Error     error
 }
 
 // EmailRoutineService introduces Go routines to enhance email sending efficiency.
+//
+// Messages are composed synchronously, so problems such as a missing attachment are returned
+// immediately, while delivery happens in a Go routine and is reported via the service's own
+// results channel.
 type EmailRoutineService struct {
-	smtpHost string
-	smtpPort string
-	username string
-	password string
+	mailer       *EmailService
+	results      chan EmailResult
+	out          chan EmailResult
+	done         chan struct{}
+	consumerDone chan struct{}
+
+	mu      sync.Mutex
+	closed  bool
+	pending sync.WaitGroup
 }
 
-func NewEmailRoutineService(smtpHost, smtpPort, username, password string) GopherSmtpInterface {
+// NewEmailRoutineService creates a new EmailRoutineService and starts the Go routine that logs send results.
+// It accepts the same options as NewEmailService.
+func NewEmailRoutineService(smtpHost, smtpPort, username, password string, opts ...EmailOption) GopherSmtpRoutineInterface {
 	service := &EmailRoutineService{
-		smtpHost: smtpHost,
-		smtpPort: smtpPort,
-		username: username,
-		password: password,
+		mailer:       newEmailService(smtpHost, smtpPort, username, password, opts),
+		results:      make(chan EmailResult),
+		out:          make(chan EmailResult, resultsBufferSize),
+		done:         make(chan struct{}),
+		consumerDone: make(chan struct{}),
 	}
 
 	// Start a goroutine to handle results
@@ -43,6 +53,21 @@ func NewEmailRoutineService(smtpHost, smtpPort, username, password string) Gophe
 	return service
 }
 
+// Results returns the channel on which this service publishes the outcome of every send.
+//
+// Every result is logged by the service regardless of whether anyone reads this channel. The
+// channel buffers up to 100 results; when it is full, further results are only logged. It is
+// closed by Close once the last result has been published.
+//
+// Example usage:
+//
+//	service := NewEmailRoutineService("smtp.example.com", "587", "user", "password")
+//	service.SendEmail([]string{"a@example.com"}, "Hello", "Hi there", false)
+//	result := <-service.Results()
+func (e *EmailRoutineService) Results() <-chan EmailResult {
+	return e.out
+}
+
 // SendEmail sends an email to the recipients using a Go routine and reports results via channel.
 //
 // This function sends a basic email to the specified recipients. It can handle either text or HTML content.
@@ -56,23 +81,69 @@ func NewEmailRoutineService(smtpHost, smtpPort, username, password string) Gophe
 // Returns:
 //   - error: An error message if the email fails to send.
 func (e *EmailRoutineService) SendEmail(to []string, subject, body string, isHtml bool) error {
-	mime := "text/plain"
-	if isHtml {
-		mime = "text/html"
-	}
+	return e.SendEmailContext(context.Background(), to, subject, body, isHtml)
+}
 
-	msg := fmt.Sprintf("Subject: %s\r\nMIME-version: 1.0;\r\nContent-Type: %s; charset=\"UTF-8\";\r\n\r\n%s", subject, mime, body)
+// SendEmailContext is like SendEmail, but the delivery is aborted when ctx is cancelled and the
+// result is no longer reported once ctx is done.
+func (e *EmailRoutineService) SendEmailContext(ctx context.Context, to []string, subject, body string, isHtml bool) error {
+	return e.Send(ctx, EmailMessage{To: to, Subject: subject, Body: body, IsHTML: isHtml})
+}
 
-	// Go routine to send email asynchronously
-	go func() {
-		err := smtp.SendMail(e.smtpHost+":"+e.smtpPort, smtp.PlainAuth("", e.username, e.password, e.smtpHost), e.username, to, []byte(msg))
-		EmailResultsChan <- EmailResult{
-			Recipient: strings.Join(to, ", "),
-			Error:     err,
-		}
-	}()
+// SendTextEmail sends a plain text email to the recipients.
+//
+// This is a convenience wrapper around SendEmail with the `isHtml` flag set to false.
+//
+// Params:
+//   - to: A list of recipient email addresses.
+//   - subject: The subject of the email.
+//   - body: The plain text content of the email.
+//
+// Returns:
+//   - error: An error message if the email fails to send.
+func (e *EmailRoutineService) SendTextEmail(to []string, subject, body string) error {
+	return e.SendEmail(to, subject, body, false)
+}
 
-	return nil
+// SendHTMLEmail sends an HTML email to the recipients.
+//
+// This is a convenience wrapper around SendEmail with the `isHtml` flag set to true.
+//
+// Params:
+//   - to: A list of recipient email addresses.
+//   - subject: The subject of the email.
+//   - body: The HTML content of the email.
+//
+// Returns:
+//   - error: An error message if the email fails to send.
+func (e *EmailRoutineService) SendHTMLEmail(to []string, subject, body string) error {
+	return e.SendEmail(to, subject, body, true)
+}
+
+// SendTemplateEmail renders an HTML template with the given data and sends the result as an HTML email.
+//
+// The template is executed before anything is sent, so rendering errors are returned without
+// contacting the SMTP server.
+//
+// Params:
+//   - to: A list of recipient email addresses.
+//   - subject: The subject of the email.
+//   - tmpl: The parsed HTML template, e.g. from ParseTemplateFiles.
+//   - data: The data the template is executed with.
+//
+// Returns:
+//   - error: An error if the template fails to render or the email fails to send.
+//
+// Example usage:
+//
+//	tmpl, _ := ParseTemplateFiles("templates/welcome.html")
+//	err := service.SendTemplateEmail([]string{"user@example.com"}, "Welcome", tmpl, struct{ Name string }{"Ada"})
+func (e *EmailRoutineService) SendTemplateEmail(to []string, subject string, tmpl *template.Template, data any) error {
+	body, err := renderTemplate(tmpl, data)
+	if err != nil {
+		return err
+	}
+	return e.SendHTMLEmail(to, subject, body)
 }
 
 // SendEmailWithAttachments sends an email with attachments using a Go routine and reports results via channel.
@@ -89,45 +160,29 @@ func (e *EmailRoutineService) SendEmail(to []string, subject, body string, isHtm
 // Returns:
 //   - error: An error message if the email fails to send.
 func (e *EmailRoutineService) SendEmailWithAttachments(to []string, subject, body string, attachmentPaths []string, isHtml bool) error {
-	mime := "text/plain"
-	if isHtml {
-		mime = "text/html"
-	}
-
-	var buffer bytes.Buffer
-	writer := multipart.NewWriter(&buffer)
-
-	headers := fmt.Sprintf("Subject: %s\r\nMIME-version: 1.0;\r\nContent-Type: multipart/mixed; boundary=%s\r\n", subject, writer.Boundary())
-	buffer.Write([]byte(headers))
-
-	// Create body part of the email
-	bodyPart, err := writer.CreatePart(map[string][]string{
-		"Content-Type": {mime + "; charset=\"UTF-8\""},
-	})
-	if err != nil {
-		return err
-	}
-	bodyPart.Write([]byte(body))
-
-	// Attach files to the email
-	for _, path := range attachmentPaths {
-		err := e.attachFile(writer, path)
-		if err != nil {
-			return err
-		}
-	}
-	writer.Close()
+	return e.SendEmailWithAttachmentsContext(context.Background(), to, subject, body, attachmentPaths, isHtml)
+}
 
-	// Go routine to send email asynchronously
-	go func() {
-		err := smtp.SendMail(e.smtpHost+":"+e.smtpPort, smtp.PlainAuth("", e.username, e.password, e.smtpHost), e.username, to, buffer.Bytes())
-		EmailResultsChan <- EmailResult{
-			Recipient: strings.Join(to, ", "),
-			Error:     err,
-		}
-	}()
+// SendEmailWithAttachmentsContext is like SendEmailWithAttachments but honours ctx cancellation.
+func (e *EmailRoutineService) SendEmailWithAttachmentsContext(ctx context.Context, to []string, subject, body string, attachmentPaths []string, isHtml bool) error {
+	return e.Send(ctx, EmailMessage{To: to, Subject: subject, Body: body, IsHTML: isHtml, Attachments: attachmentPaths})
+}
 
-	return nil
+// SendEmailWithAttachment sends an email with a single attachment. The isHtml flag determines text or HTML format.
+//
+// This is a convenience wrapper around SendEmailWithAttachments for the common single-file case.
+//
+// Params:
+//   - to: A list of recipient email addresses.
+//   - subject: The subject of the email.
+//   - body: The content of the email.
+//   - attachmentPath: The file path of the attachment.
+//   - isHtml: A flag indicating whether the email should be sent in HTML format.
+//
+// Returns:
+//   - error: An error message if the email fails to send.
+func (e *EmailRoutineService) SendEmailWithAttachment(to []string, subject, body string, attachmentPath string, isHtml bool) error {
+	return e.SendEmailWithAttachments(to, subject, body, []string{attachmentPath}, isHtml)
 }
 
 // SendEmailWithHeaders sends an email with custom headers using a Go routine and reports results via channel.
@@ -144,28 +199,12 @@ func (e *EmailRoutineService) SendEmailWithAttachments(to []string, subject, bod
 // Returns:
 //   - error: An error message if the email fails to send.
 func (e *EmailRoutineService) SendEmailWithHeaders(to []string, subject, body string, headers map[string]string, isHtml bool) error {
-	mime := "text/plain"
-	if isHtml {
-		mime = "text/html"
-	}
-
-	headerText := ""
-	for key, value := range headers {
-		headerText += fmt.Sprintf("%s: %s\r\n", key, value)
-	}
-
-	msg := fmt.Sprintf("%sSubject: %s\r\nMIME-version: 1.0;\r\nContent-Type: %s; charset=\"UTF-8\";\r\n\r\n%s", headerText, subject, mime, body)
-
-	// Go routine to send email asynchronously
-	go func() {
-		err := smtp.SendMail(e.smtpHost+":"+e.smtpPort, smtp.PlainAuth("", e.username, e.password, e.smtpHost), e.username, to, []byte(msg))
-		EmailResultsChan <- EmailResult{
-			Recipient: strings.Join(to, ", "),
-			Error:     err,
-		}
-	}()
+	return e.SendEmailWithHeadersContext(context.Background(), to, subject, body, headers, isHtml)
+}
 
-	return nil
+// SendEmailWithHeadersContext is like SendEmailWithHeaders but honours ctx cancellation.
+func (e *EmailRoutineService) SendEmailWithHeadersContext(ctx context.Context, to []string, subject, body string, headers map[string]string, isHtml bool) error {
+	return e.Send(ctx, EmailMessage{To: to, Subject: subject, Body: body, IsHTML: isHtml, Headers: headers})
 }
 
 // ScheduleEmail schedules an email to be sent at a specific time using a Go routine.
@@ -182,24 +221,83 @@ func (e *EmailRoutineService) SendEmailWithHeaders(to []string, subject, body st
 // Returns:
 //   - error: An error message if the email fails to send.
 func (e *EmailRoutineService) ScheduleEmail(to []string, subject, body string, sendAt time.Time, isHtml bool) error {
-	delay := time.Until(sendAt)
-	if delay <= 0 {
-		return fmt.Errorf("scheduled time is in the past")
+	return e.ScheduleEmailContext(context.Background(), to, subject, body, sendAt, isHtml)
+}
+
+// ScheduleEmailContext is like ScheduleEmail, but cancelling ctx before sendAt stops this process from
+// sending the email and cancelling it during delivery aborts the send. The email stays in the schedule
+// store, if any, for ResumeScheduled; use CancelScheduled to drop it. Pass a context that outlives
+// sendAt, not the context of an HTTP request.
+func (e *EmailRoutineService) ScheduleEmailContext(ctx context.Context, to []string, subject, body string, sendAt time.Time, isHtml bool) error {
+	return e.Send(ctx, EmailMessage{To: to, Subject: subject, Body: body, IsHTML: isHtml, SendAt: sendAt})
+}
+
+// ResumeScheduled replays the emails left in the schedule store by a previous run of the process.
+//
+// Emails whose send time has already passed are sent right away and the rest are scheduled again,
+// with results reported via Results(). Call it once at startup; it does nothing when the service has
+// no schedule store.
+//
+// Returns:
+//   - error: An error if the schedule store cannot be read.
+func (e *EmailRoutineService) ResumeScheduled() error {
+	return e.ResumeScheduledContext(context.Background())
+}
+
+// ResumeScheduledContext is like ResumeScheduled; cancelling ctx, e.g. on shutdown, stops the resumed
+// emails that have not been sent yet while keeping them in the schedule store for the next start.
+func (e *EmailRoutineService) ResumeScheduledContext(ctx context.Context) error {
+	if e.mailer.scheduleStore == nil {
+		return nil
 	}
 
-	// Schedule the email using a Go routine
-	go func() {
-		time.Sleep(delay)
-		err := e.SendEmail(to, subject, body, isHtml)
-		EmailResultsChan <- EmailResult{
-			Recipient: strings.Join(to, ", "),
-			Error:     err,
+	emails, err := e.mailer.scheduleStore.List()
+	if err != nil {
+		return fmt.Errorf("failed to load scheduled emails: %w", err)
+	}
+	for _, email := range emails {
+		if err := e.goScheduled(ctx, email); err != nil {
+			return err
 		}
-	}()
-
+	}
 	return nil
 }
 
+// goScheduled sends the email in a Go routine once it is due.
+//
+// An email cancelled by ctx or by Close before it is sent, including while a transient failure is
+// being retried, stays in the schedule store so that ResumeScheduled can send it after a restart; only
+// CancelScheduled drops it.
+func (e *EmailRoutineService) goScheduled(ctx context.Context, email ScheduledEmail) error {
+	ctx, untrack := e.mailer.trackScheduled(ctx, email)
+	err := e.goTracked(func() {
+		defer untrack()
+
+		if !waitUntil(ctx, email.SendAt, e.done) {
+			return
+		}
+
+		err := e.mailer.deliverScheduled(ctx, email, e.done)
+		e.report(ctx, strings.Join(email.To, ", "), err)
+	})
+	if err != nil {
+		untrack()
+	}
+	return err
+}
+
+// CancelScheduled drops the scheduled email with the given ID: it is removed from the schedule store
+// and, if it is waiting in this process, will not be sent. The IDs are those listed by the store.
+//
+// Params:
+//   - id: The ID of the scheduled email, as found in ScheduledEmail.ID.
+//
+// Returns:
+//   - error: An error if the service has no schedule store or the entry cannot be removed.
+func (e *EmailRoutineService) CancelScheduled(id string) error {
+	return e.mailer.CancelScheduled(id)
+}
+
 // SendEmailWithCCAndBCC sends an email with CC and BCC recipients using a Go routine.
 //
 // This function sends an email to the specified recipients, including CC and BCC recipients.
@@ -215,28 +313,12 @@ func (e *EmailRoutineService) ScheduleEmail(to []string, subject, body string, s
 // Returns:
 //   - error: An error message if the email fails to send.
 func (e *EmailRoutineService) SendEmailWithCCAndBCC(to, cc, bcc []string, subject, body string, isHtml bool) error {
-	mime := "text/plain"
-	if isHtml {
-		mime = "text/html"
-	}
-
-	allRecipients := append(to, cc...)
-	allRecipients = append(allRecipients, bcc...)
-
-	ccHeader := strings.Join(cc, ",")
-	bccHeader := strings.Join(bcc, ",")
-	headers := fmt.Sprintf("Subject: %s\r\nCC: %s\r\nBCC: %s\r\nMIME-version: 1.0;\r\nContent-Type: %s; charset=\"UTF-8\";\r\n\r\n%s", subject, ccHeader, bccHeader, mime, body)
-
-	// Go routine to send email asynchronously
-	go func() {
-		err := smtp.SendMail(e.smtpHost+":"+e.smtpPort, smtp.PlainAuth("", e.username, e.password, e.smtpHost), e.username, allRecipients, []byte(headers))
-		EmailResultsChan <- EmailResult{
-			Recipient: strings.Join(allRecipients, ", "),
-			Error:     err,
-		}
-	}()
+	return e.SendEmailWithCCAndBCCContext(context.Background(), to, cc, bcc, subject, body, isHtml)
+}
 
-	return nil
+// SendEmailWithCCAndBCCContext is like SendEmailWithCCAndBCC but honours ctx cancellation.
+func (e *EmailRoutineService) SendEmailWithCCAndBCCContext(ctx context.Context, to, cc, bcc []string, subject, body string, isHtml bool) error {
+	return e.Send(ctx, EmailMessage{To: to, CC: cc, BCC: bcc, Subject: subject, Body: body, IsHTML: isHtml})
 }
 
 // SendBulkEmail sends bulk emails using Go routines for each email.
@@ -252,15 +334,28 @@ func (e *EmailRoutineService) SendEmailWithCCAndBCC(to, cc, bcc []string, subjec
 // Returns:
 //   - error: An error message if the email fails to send.
 func (e *EmailRoutineService) SendBulkEmail(to []string, subject, body string, isHtml bool) error {
+	return e.SendBulkEmailContext(context.Background(), to, subject, body, isHtml)
+}
+
+// SendBulkEmailContext is like SendBulkEmail but honours ctx cancellation for every recipient.
+func (e *EmailRoutineService) SendBulkEmailContext(ctx context.Context, to []string, subject, body string, isHtml bool) error {
+	// Reject malformed addresses before queueing any send
+	to, _, _, err := e.mailer.checkRecipients(to, nil, nil)
+	if err != nil {
+		return err
+	}
+
 	for _, recipient := range to {
+		// Each recipient gets a copy addressed to them in its To header
+		_, msg, err := e.mailer.composeMessage(EmailMessage{To: []string{recipient}, Subject: subject, Body: body, IsHTML: isHtml})
+		if err != nil {
+			return err
+		}
+
 		// Send each email in a Go routine
-		go func(recipient string) {
-			err := e.SendEmail([]string{recipient}, subject, body, isHtml)
-			EmailResultsChan <- EmailResult{
-				Recipient: recipient,
-				Error:     err,
-			}
-		}(recipient)
+		if err := e.dispatch(ctx, e.mailer.envelopeFrom("", []string{recipient}), []string{recipient}, recipient, msg); err != nil {
+			return err
+		}
 	}
 	return nil
 }
@@ -278,194 +373,142 @@ func (e *EmailRoutineService) SendBulkEmail(to []string, subject, body string, i
 // Returns:
 //   - error: An error message if the email fails to send.
 func (e *EmailRoutineService) SendEmailWithInLineImages(to []string, subject, body string, imagePaths []string) error {
-	mime := "text/html"
-
-	var buffer bytes.Buffer
-	writer := multipart.NewWriter(&buffer)
-
-	headers := fmt.Sprintf("Subject: %s\r\nMIME-version: 1.0;\r\nContent-Type: multipart/related; boundary=%s\r\n", subject, writer.Boundary())
-	buffer.Write([]byte(headers))
-
-	// Create body part of the email
-	bodyPart, err := writer.CreatePart(map[string][]string{
-		"Content-Type": {mime + "; charset=\"UTF-8\""},
-	})
-	if err != nil {
-		return err
-	}
-	bodyPart.Write([]byte(body))
-
-	// Attach inline images
-	for _, path := range imagePaths {
-		err := e.attachInlineImage(writer, path)
-		if err != nil {
-			return err
-		}
-	}
-	writer.Close()
-
-	// Go routine to send email asynchronously
-	go func() {
-		err := smtp.SendMail(e.smtpHost+":"+e.smtpPort, smtp.PlainAuth("", e.username, e.password, e.smtpHost), e.username, to, buffer.Bytes())
-		EmailResultsChan <- EmailResult{
-			Recipient: strings.Join(to, ", "),
-			Error:     err,
-		}
-	}()
+	return e.SendEmailWithInLineImagesContext(context.Background(), to, subject, body, imagePaths)
+}
 
-	return nil
+// SendEmailWithInLineImagesContext is like SendEmailWithInLineImages but honours ctx cancellation.
+func (e *EmailRoutineService) SendEmailWithInLineImagesContext(ctx context.Context, to []string, subject, body string, imagePaths []string) error {
+	return e.Send(ctx, EmailMessage{To: to, Subject: subject, Body: body, IsHTML: true, InlineImages: imagePaths})
 }
 
 // SendEmailWithCCAndBCCAndAttachments sends an email with CC, BCC recipients, and attachments using a Go routine.
 // The isHtml flag determines whether it's text or HTML, and the result is reported via a channel.
 func (e *EmailRoutineService) SendEmailWithCCAndBCCAndAttachments(to, cc, bcc []string, subject, body string, attachmentPaths []string, isHtml bool) error {
-	mime := "text/plain"
-	if isHtml {
-		mime = "text/html"
-	}
+	return e.SendEmailWithCCAndBCCAndAttachmentsContext(context.Background(), to, cc, bcc, subject, body, attachmentPaths, isHtml)
+}
 
-	var buffer bytes.Buffer
-	writer := multipart.NewWriter(&buffer)
+// SendEmailWithCCAndBCCAndAttachmentsContext is like SendEmailWithCCAndBCCAndAttachments but honours ctx cancellation.
+func (e *EmailRoutineService) SendEmailWithCCAndBCCAndAttachmentsContext(ctx context.Context, to, cc, bcc []string, subject, body string, attachmentPaths []string, isHtml bool) error {
+	return e.Send(ctx, EmailMessage{To: to, CC: cc, BCC: bcc, Subject: subject, Body: body, IsHTML: isHtml, Attachments: attachmentPaths})
+}
 
-	// Set headers
-	ccHeader := strings.Join(cc, ",")
-	bccHeader := strings.Join(bcc, ",")
-	headers := fmt.Sprintf("Subject: %s\r\nCC: %s\r\nBCC: %s\r\nMIME-version: 1.0;\r\nContent-Type: multipart/mixed; boundary=%s\r\n", subject, ccHeader, bccHeader, writer.Boundary())
-	buffer.Write([]byte(headers))
+// SendEmailWithAttachmentsAndInLineImages sends an email with both attachments and inline images using a Go routine.
+func (e *EmailRoutineService) SendEmailWithAttachmentsAndInLineImages(to []string, subject, body string, attachmentPaths, imagePaths []string) error {
+	return e.SendEmailWithAttachmentsAndInLineImagesContext(context.Background(), to, subject, body, attachmentPaths, imagePaths)
+}
 
-	// Add body part
-	bodyPart, err := writer.CreatePart(map[string][]string{
-		"Content-Type": {mime + "; charset=\"UTF-8\""},
-	})
-	if err != nil {
-		return err
-	}
-	bodyPart.Write([]byte(body))
+// SendEmailWithAttachmentsAndInLineImagesContext is like SendEmailWithAttachmentsAndInLineImages but honours ctx cancellation.
+func (e *EmailRoutineService) SendEmailWithAttachmentsAndInLineImagesContext(ctx context.Context, to []string, subject, body string, attachmentPaths, imagePaths []string) error {
+	return e.Send(ctx, EmailMessage{To: to, Subject: subject, Body: body, IsHTML: true, Attachments: attachmentPaths, InlineImages: imagePaths})
+}
 
-	// Attach files
-	for _, path := range attachmentPaths {
-		err := e.attachFile(writer, path)
+// Send composes msg and delivers it in a Go routine, or schedules it when msg.SendAt is set. Composition
+// errors such as a missing attachment are returned immediately; the delivery result is reported via Results().
+//
+// Params:
+//   - ctx: Context controlling cancellation; for scheduled emails, cancelling it before SendAt stops this
+//     process from sending the email but keeps it in the schedule store (see CancelScheduled).
+//   - msg: The email to send.
+//
+// Returns:
+//   - error: An error if a recipient is invalid, a file cannot be read, SendAt is in the past, or the service is closed.
+func (e *EmailRoutineService) Send(ctx context.Context, msg EmailMessage) error {
+	if !msg.SendAt.IsZero() {
+		email, err := e.mailer.scheduleMessage(msg)
 		if err != nil {
 			return err
 		}
+		// Schedule the email using a Go routine; Close cancels it if it has not been sent yet
+		return e.goScheduled(ctx, email)
 	}
-	writer.Close()
 
-	// Merge recipients
-	allRecipients := append(to, cc...)
-	allRecipients = append(allRecipients, bcc...)
+	recipients, data, err := e.mailer.composeMessage(msg)
+	if err != nil {
+		return err
+	}
 
 	// Go routine to send email asynchronously
-	go func() {
-		err := smtp.SendMail(e.smtpHost+":"+e.smtpPort, smtp.PlainAuth("", e.username, e.password, e.smtpHost), e.username, allRecipients, buffer.Bytes())
-		// Send the result to the channel
-		EmailResultsChan <- EmailResult{
-			Recipient: strings.Join(allRecipients, ", "),
-			Error:     err,
-		}
-	}()
-
-	return nil
+	return e.dispatch(ctx, e.mailer.envelopeFrom(msg.EnvelopeFrom, recipients), recipients, strings.Join(recipients, ", "), data)
 }
 
-// SendEmailWithAttachmentsAndInLineImages sends an email with both attachments and inline images using a Go routine.
-func (e *EmailRoutineService) SendEmailWithAttachmentsAndInLineImages(to []string, subject, body string, attachmentPaths, imagePaths []string) error {
-	mime := "text/html"
-
-	var buffer bytes.Buffer
-	writer := multipart.NewWriter(&buffer)
-
-	headers := fmt.Sprintf("Subject: %s\r\nMIME-version: 1.0;\r\nContent-Type: multipart/mixed; boundary=%s\r\n", subject, writer.Boundary())
-	buffer.Write([]byte(headers))
-
-	bodyPart, err := writer.CreatePart(map[string][]string{
-		"Content-Type": {mime + "; charset=\"UTF-8\""},
+// dispatch delivers msg from the envelope sender from in a Go routine and reports the result via the results channel.
+func (e *EmailRoutineService) dispatch(ctx context.Context, from string, to []string, recipient string, msg []byte) error {
+	return e.goTracked(func() {
+		err := e.mailer.sendMail(ctx, from, mergeRecipients(to, nil, nil), msg)
+		e.report(ctx, recipient, err)
 	})
-	if err != nil {
-		return err
-	}
-	bodyPart.Write([]byte(body))
+}
 
-	for _, path := range imagePaths {
-		err := e.attachInlineImage(writer, path)
-		if err != nil {
-			return err
-		}
-	}
+// goTracked runs fn in a Go routine that Close waits for, or returns ErrServiceClosed after Close.
+func (e *EmailRoutineService) goTracked(fn func()) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
 
-	for _, path := range attachmentPaths {
-		err := e.attachFile(writer, path)
-		if err != nil {
-			return err
-		}
+	if e.closed {
+		return ErrServiceClosed
 	}
-	writer.Close()
 
-	// Go routine to send email asynchronously
+	e.pending.Add(1)
 	go func() {
-		err := smtp.SendMail(e.smtpHost+":"+e.smtpPort, smtp.PlainAuth("", e.username, e.password, e.smtpHost), e.username, to, buffer.Bytes())
-		// Send the result to the channel
-		EmailResultsChan <- EmailResult{
-			Recipient: strings.Join(to, ", "),
-			Error:     err,
-		}
+		defer e.pending.Done()
+		fn()
 	}()
-
 	return nil
 }
 
-// Attach file helper function
-func (e *EmailRoutineService) attachFile(writer *multipart.Writer, path string) error {
-	file, err := os.Open(path)
-	if err != nil {
-		return err
+// Close stops the service and the Go routine that processes its results.
+//
+// Sends that are already being delivered are allowed to finish and their results are still logged
+// and published. Scheduled emails whose send time has not yet arrived are cancelled; they are never sent
+// unless a schedule store keeps them for ResumeScheduled.
+// After Close returns, the Results() channel is closed and every send method returns ErrServiceClosed.
+// Calling Close more than once is safe.
+//
+// Example usage:
+//
+//	service := NewEmailRoutineService("smtp.example.com", "587", "user", "password")
+//	defer service.Close()
+func (e *EmailRoutineService) Close() error {
+	e.mu.Lock()
+	if e.closed {
+		e.mu.Unlock()
+		return nil
 	}
-	defer file.Close()
+	e.closed = true
+	close(e.done)
+	e.mu.Unlock()
 
-	filePart, err := writer.CreateFormFile("attachment", filepath.Base(path))
-	if err != nil {
-		return err
-	}
+	// Wait for in-flight sends to report, then let the consumer drain and exit
+	e.pending.Wait()
+	close(e.results)
+	<-e.consumerDone
 
-	_, err = io.Copy(filePart, file)
-	return err
+	return nil
 }
 
-// Helper function to attach an inline image to the email.
-func (e *EmailRoutineService) attachInlineImage(writer *multipart.Writer, imagePath string) error {
-	file, err := os.Open(imagePath)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-
-	// Get the file's MIME type
-	mimeType := "image/" + strings.TrimPrefix(filepath.Ext(imagePath), ".")
-	partHeader := make(map[string][]string)
-	partHeader["Content-Type"] = []string{mimeType}
-	partHeader["Content-Transfer-Encoding"] = []string{"base64"}
-	partHeader["Content-Disposition"] = []string{`inline; filename="` + filepath.Base(imagePath) + `";`}
-	partHeader["Content-ID"] = []string{`<` + filepath.Base(imagePath) + `>`}
-
-	part, err := writer.CreatePart(partHeader)
-	if err != nil {
-		return err
+// report publishes a send result, giving up once ctx is done so no Go routine is left blocked on the channel.
+func (e *EmailRoutineService) report(ctx context.Context, recipient string, err error) {
+	select {
+	case e.results <- EmailResult{Recipient: recipient, Error: err}:
+	case <-ctx.Done():
 	}
-
-	// Read the image and encode it in base64
-	imageData := make([]byte, base64.StdEncoding.EncodedLen(len(imagePath)))
-	base64.StdEncoding.Encode(imageData, []byte(imagePath))
-
-	_, err = part.Write(imageData)
-	return err
 }
 
+// processEmailResults logs every result of this service and forwards it to Results().
 func (e *EmailRoutineService) processEmailResults() {
-	for result := range EmailResultsChan {
+	defer close(e.consumerDone)
+	defer close(e.out)
+
+	for result := range e.results {
 		if result.Error != nil {
 			log.Printf("Failed to send email to %s: %v\n", result.Recipient, result.Error)
 		} else {
 			log.Printf("Email sent successfully to %s!\n", result.Recipient)
 		}
+
+		select {
+		case e.out <- result:
+		default:
+		}
 	}
 }
@@ -0,0 +1,66 @@
+package gophersmtp
+
+import (
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestRoutineSendBulkEmailAddressesEachRecipient(t *testing.T) {
+	sink := NewMemorySink()
+	service := NewEmailRoutineService("smtp.example.com", "587", "sender@example.com", "password", WithDryRun(sink))
+
+	recipients := []string{"a@example.com", "b@example.com"}
+	if err := service.SendBulkEmail(recipients, "News", "Hello", false); err != nil {
+		t.Fatalf("SendBulkEmail failed: %v", err)
+	}
+	for range recipients {
+		select {
+		case result := <-service.Results():
+			if result.Error != nil {
+				t.Fatalf("send to %s failed: %v", result.Recipient, result.Error)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for a result")
+		}
+	}
+	service.Close()
+
+	got := make(map[string]string)
+	for _, msg := range sink.Messages() {
+		got[msg.To[0]] = header(t, msg.Data, "To")
+	}
+	for _, recipient := range recipients {
+		if got[recipient] != recipient {
+			t.Errorf("message to %s has To header %q, want %q", recipient, got[recipient], recipient)
+		}
+	}
+}
+
+func TestRoutineCloseLeavesNoGoroutines(t *testing.T) {
+	before := runtime.NumGoroutine()
+
+	sink := NewMemorySink()
+	service := NewEmailRoutineService("smtp.example.com", "587", "sender@example.com", "password", WithDryRun(sink))
+	for i := 0; i < 5; i++ {
+		if err := service.SendEmail([]string{"a@example.com"}, "Now", "Hello", false); err != nil {
+			t.Fatalf("SendEmail failed: %v", err)
+		}
+		if err := service.ScheduleEmail([]string{"a@example.com"}, "Later", "Hello", time.Now().Add(time.Hour), false); err != nil {
+			t.Fatalf("ScheduleEmail failed: %v", err)
+		}
+	}
+	if err := service.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	// Results is closed once the consumer has stopped
+	for range service.Results() {
+	}
+	waitFor(t, "the service's Go routines to exit", func() bool {
+		return runtime.NumGoroutine() <= before
+	})
+	if n := len(sink.Messages()); n != 5 {
+		t.Errorf("%d emails sent, want the 5 immediate ones and none of the scheduled ones", n)
+	}
+}
@@ -1,39 +1,79 @@
 package gophersmtp
 
 import (
-	"bytes"
-	"encoding/base64"
+	"context"
+	"crypto/tls"
 	"fmt"
-	"mime/multipart"
-	"net/smtp"
-	"os"
-	"path/filepath"
+	"html/template"
 	"strings"
+	"sync"
 	"time"
 )
 
 // EmailService is responsible for handling email sending with various functionalities
 // such as sending plain text, HTML, attachments, and more.
 type EmailService struct {
-	smtpHost string
-	smtpPort string
-	username string
-	password string
+	smtpHost      string
+	smtpPort      string
+	username      string
+	password      string
+	tlsMode       TLSMode
+	tlsConfig     *tls.Config
+	authMechanism AuthMechanism
+	maxRetries    int
+	retryBackoff  time.Duration
+	timeout       time.Duration
+
+	fromName    string
+	fromAddress string
+	replyTo     []string
+	verpAddress string
+
+	scheduleStore ScheduleStore
+	sink          MessageSink
+	sender        SendFunc
+
+	// scheduledCancels stops the scheduled emails waiting in this process, by ID, for CancelScheduled
+	scheduledMu      sync.Mutex
+	scheduledCancels map[string]context.CancelFunc
+
+	maxMessageBytes int64
+	maxAttachments  int
+	bodyEncoding    BodyEncoding
+	dkim            *DKIMConfig
+
+	allowEmptyRecipients bool
 }
 
 // NewEmailService creates a new instance of EmailService with the given SMTP configurations.
 // Parameters:
 // - smtpHost: The host of the SMTP server.
 // - smtpPort: The port of the SMTP server.
-// - username: The sender's email address.
+// - username: The login used for authentication, and the sender address unless WithFrom overrides it.
 // - password: The sender's email account password (used for authentication).
-func NewEmailService(smtpHost, smtpPort, username, password string) GopherSmtpInterface {
-	return &EmailService{
-		smtpHost: smtpHost,
-		smtpPort: smtpPort,
-		username: username,
-		password: password,
-	}
+// - opts: Optional settings such as WithTLS or WithAuthMechanism.
+func NewEmailService(smtpHost, smtpPort, username, password string, opts ...EmailOption) GopherSmtpInterface {
+	return newEmailService(smtpHost, smtpPort, username, password, opts)
+}
+
+// NewEmailServiceWithTLS creates a new instance of EmailService that secures the SMTP connection
+// according to the given TLS mode.
+// Parameters:
+// - smtpHost: The host of the SMTP server.
+// - smtpPort: The port of the SMTP server.
+// - username: The login used for authentication, and the sender address unless WithFrom overrides it.
+// - password: The sender's email account password (used for authentication).
+// - tlsMode: TLSModeImplicit for port 465, TLSModeStartTLS for port 587, or TLSModeNone.
+// - tlsConfig: Optional TLS settings such as ServerName or InsecureSkipVerify (for development only).
+// - opts: Further optional settings such as WithAuthMechanism.
+//
+// Example usage:
+//
+//	service := NewEmailServiceWithTLS("smtp.gmail.com", "465", "me@gmail.com", "app-password",
+//	    TLSModeImplicit, &tls.Config{ServerName: "smtp.gmail.com"})
+func NewEmailServiceWithTLS(smtpHost, smtpPort, username, password string, tlsMode TLSMode, tlsConfig *tls.Config, opts ...EmailOption) GopherSmtpInterface {
+	opts = append([]EmailOption{WithTLS(tlsMode, tlsConfig)}, opts...)
+	return newEmailService(smtpHost, smtpPort, username, password, opts)
 }
 
 // SendEmail sends an email to the recipients. The isHtml flag determines whether it's text or HTML.
@@ -50,13 +90,71 @@ func NewEmailService(smtpHost, smtpPort, username, password string) GopherSmtpIn
 // Returns:
 //   - error: An error message if the email fails to send.
 func (e *EmailService) SendEmail(to []string, subject, body string, isHtml bool) error {
-	mime := "text/plain"
-	if isHtml {
-		mime = "text/html"
-	}
-	msg := fmt.Sprintf("Subject: %s\r\nMIME-version: 1.0;\r\nContent-Type: %s; charset=\"UTF-8\";\r\n\r\n%s", subject, mime, body)
+	return e.SendEmailContext(context.Background(), to, subject, body, isHtml)
+}
+
+// SendEmailContext is like SendEmail but aborts the SMTP exchange when ctx is cancelled or its deadline passes.
+//
+// Returns:
+//   - error: ctx.Err() if the context ended first, otherwise an error message if the email fails to send.
+func (e *EmailService) SendEmailContext(ctx context.Context, to []string, subject, body string, isHtml bool) error {
+	return e.Send(ctx, EmailMessage{To: to, Subject: subject, Body: body, IsHTML: isHtml})
+}
 
-	return smtp.SendMail(e.smtpHost+":"+e.smtpPort, smtp.PlainAuth("", e.username, e.password, e.smtpHost), e.username, to, []byte(msg))
+// SendTextEmail sends a plain text email to the recipients.
+//
+// This is a convenience wrapper around SendEmail with the `isHtml` flag set to false.
+//
+// Params:
+//   - to: A list of recipient email addresses.
+//   - subject: The subject of the email.
+//   - body: The plain text content of the email.
+//
+// Returns:
+//   - error: An error message if the email fails to send.
+func (e *EmailService) SendTextEmail(to []string, subject, body string) error {
+	return e.SendEmail(to, subject, body, false)
+}
+
+// SendHTMLEmail sends an HTML email to the recipients.
+//
+// This is a convenience wrapper around SendEmail with the `isHtml` flag set to true.
+//
+// Params:
+//   - to: A list of recipient email addresses.
+//   - subject: The subject of the email.
+//   - body: The HTML content of the email.
+//
+// Returns:
+//   - error: An error message if the email fails to send.
+func (e *EmailService) SendHTMLEmail(to []string, subject, body string) error {
+	return e.SendEmail(to, subject, body, true)
+}
+
+// SendTemplateEmail renders an HTML template with the given data and sends the result as an HTML email.
+//
+// The template is executed before anything is sent, so rendering errors are returned without
+// contacting the SMTP server.
+//
+// Params:
+//   - to: A list of recipient email addresses.
+//   - subject: The subject of the email.
+//   - tmpl: The parsed HTML template, e.g. from ParseTemplateFiles.
+//   - data: The data the template is executed with.
+//
+// Returns:
+//   - error: An error if the template fails to render or the email fails to send.
+//
+// Example usage:
+//
+//	tmpl, _ := ParseTemplateFiles("templates/welcome.html")
+//	err := service.SendTemplateEmail([]string{"user@example.com"}, "Welcome", tmpl, struct{ Name string }{"Ada"})
+func (e *EmailService) SendTemplateEmail(to []string, subject string, tmpl *template.Template, data any) error {
+	body, err := renderTemplate(tmpl, data)
+	if err != nil {
+		return err
+	}
+	return e.SendHTMLEmail(to, subject, body)
 }
 
 // SendEmailWithAttachments sends an email with attachments. The isHtml flag determines text or HTML format.
@@ -74,38 +172,29 @@ func (e *EmailService) SendEmail(to []string, subject, body string, isHtml bool)
 // Returns:
 //   - error: An error message if the email fails to send.
 func (e *EmailService) SendEmailWithAttachments(to []string, subject, body string, attachmentPaths []string, isHtml bool) error {
-	mime := "text/plain"
-	if isHtml {
-		mime = "text/html"
-	}
-
-	var buffer bytes.Buffer
-	writer := multipart.NewWriter(&buffer)
-
-	// Set headers
-	headers := fmt.Sprintf("Subject: %s\r\nMIME-version: 1.0;\r\nContent-Type: multipart/mixed; boundary=%s\r\n", subject, writer.Boundary())
-	buffer.Write([]byte(headers))
-
-	// Add body part
-	bodyPart, err := writer.CreatePart(map[string][]string{
-		"Content-Type": {mime + "; charset=\"UTF-8\""},
-	})
-	if err != nil {
-		return err
-	}
-	bodyPart.Write([]byte(body))
+	return e.SendEmailWithAttachmentsContext(context.Background(), to, subject, body, attachmentPaths, isHtml)
+}
 
-	// Attach files
-	for _, path := range attachmentPaths {
-		err := e.attachFile(writer, path)
-		if err != nil {
-			return err
-		}
-	}
-	writer.Close()
+// SendEmailWithAttachmentsContext is like SendEmailWithAttachments but honours ctx cancellation.
+func (e *EmailService) SendEmailWithAttachmentsContext(ctx context.Context, to []string, subject, body string, attachmentPaths []string, isHtml bool) error {
+	return e.Send(ctx, EmailMessage{To: to, Subject: subject, Body: body, IsHTML: isHtml, Attachments: attachmentPaths})
+}
 
-	// Send the email
-	return smtp.SendMail(e.smtpHost+":"+e.smtpPort, smtp.PlainAuth("", e.username, e.password, e.smtpHost), e.username, to, buffer.Bytes())
+// SendEmailWithAttachment sends an email with a single attachment. The isHtml flag determines text or HTML format.
+//
+// This is a convenience wrapper around SendEmailWithAttachments for the common single-file case.
+//
+// Params:
+//   - to: A list of recipient email addresses.
+//   - subject: The subject of the email.
+//   - body: The content of the email.
+//   - attachmentPath: The file path of the attachment.
+//   - isHtml: A flag indicating whether the email should be sent in HTML format.
+//
+// Returns:
+//   - error: An error message if the email fails to send.
+func (e *EmailService) SendEmailWithAttachment(to []string, subject, body string, attachmentPath string, isHtml bool) error {
+	return e.SendEmailWithAttachments(to, subject, body, []string{attachmentPath}, isHtml)
 }
 
 // SendEmailWithInLineImages sends an email with inline images only.
@@ -122,36 +211,12 @@ func (e *EmailService) SendEmailWithAttachments(to []string, subject, body strin
 // Returns:
 //   - error: An error message if the email fails to send.
 func (e *EmailService) SendEmailWithInLineImages(to []string, subject, body string, inlineImagePaths []string) error {
-	mime := "text/html" // If you want to send HTML, else set to "text/plain"
-
-	// Create email body
-	var buffer bytes.Buffer
-	writer := multipart.NewWriter(&buffer)
-
-	// Set headers
-	headers := fmt.Sprintf("Subject: %s\r\nMIME-version: 1.0;\r\nContent-Type: multipart/related; boundary=%s\r\n", subject, writer.Boundary())
-	buffer.Write([]byte(headers))
-
-	// Add body part
-	bodyPart, err := writer.CreatePart(map[string][]string{
-		"Content-Type": {mime + "; charset=\"UTF-8\""},
-	})
-	if err != nil {
-		return err
-	}
-	bodyPart.Write([]byte(body))
-
-	// Attach inline images
-	for _, path := range inlineImagePaths {
-		err := e.attachInlineImage(writer, path)
-		if err != nil {
-			return err
-		}
-	}
-	writer.Close()
+	return e.SendEmailWithInLineImagesContext(context.Background(), to, subject, body, inlineImagePaths)
+}
 
-	// Send the email
-	return smtp.SendMail(e.smtpHost+":"+e.smtpPort, smtp.PlainAuth("", e.username, e.password, e.smtpHost), e.username, to, buffer.Bytes())
+// SendEmailWithInLineImagesContext is like SendEmailWithInLineImages but honours ctx cancellation.
+func (e *EmailService) SendEmailWithInLineImagesContext(ctx context.Context, to []string, subject, body string, inlineImagePaths []string) error {
+	return e.Send(ctx, EmailMessage{To: to, Subject: subject, Body: body, IsHTML: true, InlineImages: inlineImagePaths})
 }
 
 // SendEmailWithHeaders sends an email with custom headers. The isHtml flag determines text or HTML format.
@@ -169,28 +234,19 @@ func (e *EmailService) SendEmailWithInLineImages(to []string, subject, body stri
 // Returns:
 //   - error: An error message if the email fails to send.
 func (e *EmailService) SendEmailWithHeaders(to []string, subject, body string, headers map[string]string, isHtml bool) error {
-	mime := "text/plain"
-	if isHtml {
-		mime = "text/html"
-	}
-
-	// Compose custom headers
-	headerText := ""
-	for key, value := range headers {
-		headerText += fmt.Sprintf("%s: %s\r\n", key, value)
-	}
-
-	// Complete message
-	msg := fmt.Sprintf("%sSubject: %s\r\nMIME-version: 1.0;\r\nContent-Type: %s; charset=\"UTF-8\";\r\n\r\n%s", headerText, subject, mime, body)
+	return e.SendEmailWithHeadersContext(context.Background(), to, subject, body, headers, isHtml)
+}
 
-	// Send email
-	return smtp.SendMail(e.smtpHost+":"+e.smtpPort, smtp.PlainAuth("", e.username, e.password, e.smtpHost), e.username, to, []byte(msg))
+// SendEmailWithHeadersContext is like SendEmailWithHeaders but honours ctx cancellation.
+func (e *EmailService) SendEmailWithHeadersContext(ctx context.Context, to []string, subject, body string, headers map[string]string, isHtml bool) error {
+	return e.Send(ctx, EmailMessage{To: to, Subject: subject, Body: body, IsHTML: isHtml, Headers: headers})
 }
 
 // ScheduleEmail schedules an email to be sent at a specific time. The isHtml flag determines text or HTML format.
 //
 // This function schedules the email to be sent at a specific time using a goroutine and timer to delay
-// execution.
+// execution. When the service was configured WithScheduleStore, the email is also persisted so that
+// ResumeScheduled can send it after a restart.
 //
 // Params:
 //   - to: A list of recipient email addresses.
@@ -202,26 +258,54 @@ func (e *EmailService) SendEmailWithHeaders(to []string, subject, body string, h
 // Returns:
 //   - error: An error message if the scheduling fails.
 func (e *EmailService) ScheduleEmail(to []string, subject, body string, sendAt time.Time, isHtml bool) error {
-	delay := time.Until(sendAt)
-	if delay <= 0 {
-		return fmt.Errorf("scheduled time is in the past")
-	}
+	return e.ScheduleEmailContext(context.Background(), to, subject, body, sendAt, isHtml)
+}
 
-	go func() {
-		time.Sleep(delay)
-		e.SendEmail(to, subject, body, isHtml)
-	}()
+// ScheduleEmailContext is like ScheduleEmail, but cancelling ctx before sendAt stops this process from
+// sending the email and cancelling it during delivery aborts the send. The email stays in the schedule
+// store, if any, for ResumeScheduled; use CancelScheduled to drop it. Pass a context that outlives
+// sendAt, not the context of an HTTP request.
+func (e *EmailService) ScheduleEmailContext(ctx context.Context, to []string, subject, body string, sendAt time.Time, isHtml bool) error {
+	return e.Send(ctx, EmailMessage{To: to, Subject: subject, Body: body, IsHTML: isHtml, SendAt: sendAt})
+}
 
+// ResumeScheduled replays the emails left in the schedule store by a previous run of the process.
+//
+// Emails whose send time has already passed are sent right away and the rest are scheduled again.
+// Call it once at startup; it does nothing when the service has no schedule store.
+//
+// Returns:
+//   - error: An error if the schedule store cannot be read.
+func (e *EmailService) ResumeScheduled() error {
+	return e.ResumeScheduledContext(context.Background())
+}
+
+// ResumeScheduledContext is like ResumeScheduled; cancelling ctx, e.g. on shutdown, stops the resumed
+// emails that have not been sent yet while keeping them in the schedule store for the next start.
+func (e *EmailService) ResumeScheduledContext(ctx context.Context) error {
+	if e.scheduleStore == nil {
+		return nil
+	}
+
+	emails, err := e.scheduleStore.List()
+	if err != nil {
+		return fmt.Errorf("failed to load scheduled emails: %w", err)
+	}
+	for _, email := range emails {
+		e.goScheduled(ctx, email)
+	}
 	return nil
 }
 
 // SendEmailWithCCAndBCC sends an email with CC and BCC recipients. The isHtml flag determines text or HTML format.
 //
-// This function sends an email with additional CC and BCC recipients. Both CC and BCC lists are supported
-// to allow visibility and hidden recipients.
+// This function sends an email with additional CC and BCC recipients. CC recipients are listed in the
+// message headers, while BCC recipients only appear in the SMTP envelope and stay hidden from everyone else.
+// The to list may be empty as long as there is a CC or BCC recipient; the To header then reads
+// "undisclosed-recipients:;".
 //
 // Params:
-//   - to: A list of recipient email addresses.
+//   - to: A list of recipient email addresses, possibly empty.
 //   - cc: A list of CC recipient email addresses.
 //   - bcc: A list of BCC recipient email addresses.
 //   - subject: The subject of the email.
@@ -231,28 +315,23 @@ func (e *EmailService) ScheduleEmail(to []string, subject, body string, sendAt t
 // Returns:
 //   - error: An error message if the email fails to send.
 func (e *EmailService) SendEmailWithCCAndBCC(to, cc, bcc []string, subject, body string, isHtml bool) error {
-	mime := "text/plain"
-	if isHtml {
-		mime = "text/html"
-	}
-
-	// Merge recipients
-	allRecipients := append(to, cc...)
-	allRecipients = append(allRecipients, bcc...)
-
-	// Construct headers
-	ccHeader := strings.Join(cc, ",")
-	bccHeader := strings.Join(bcc, ",")
-	headers := fmt.Sprintf("Subject: %s\r\nCC: %s\r\nBCC: %s\r\nMIME-version: 1.0;\r\nContent-Type: %s; charset=\"UTF-8\";\r\n\r\n%s", subject, ccHeader, bccHeader, mime, body)
+	return e.SendEmailWithCCAndBCCContext(context.Background(), to, cc, bcc, subject, body, isHtml)
+}
 
-	// Send email
-	return smtp.SendMail(e.smtpHost+":"+e.smtpPort, smtp.PlainAuth("", e.username, e.password, e.smtpHost), e.username, allRecipients, []byte(headers))
+// SendEmailWithCCAndBCCContext is like SendEmailWithCCAndBCC but honours ctx cancellation.
+func (e *EmailService) SendEmailWithCCAndBCCContext(ctx context.Context, to, cc, bcc []string, subject, body string, isHtml bool) error {
+	return e.Send(ctx, EmailMessage{To: to, CC: cc, BCC: bcc, Subject: subject, Body: body, IsHTML: isHtml})
 }
 
 // SendBulkEmail sends bulk emails. The isHtml flag determines text or HTML format.
 //
 // This function is designed for sending the same email to multiple recipients in bulk.
-// It can handle plain text and HTML emails based on the `isHtml` flag.
+// It can handle plain text and HTML emails based on the `isHtml` flag. Every recipient is
+// attempted even if earlier ones fail.
+//
+// All recipients are sent to over a single SMTP connection, authenticated once, with a separate
+// MAIL/RCPT/DATA transaction per recipient. If the server drops the connection mid-batch, the
+// service reconnects and carries on.
 //
 // Params:
 //   - to: A list of recipient email addresses.
@@ -261,13 +340,87 @@ func (e *EmailService) SendEmailWithCCAndBCC(to, cc, bcc []string, subject, body
 //   - isHtml: A flag indicating whether the email should be sent in HTML format.
 //
 // Returns:
-//   - error: An error message if the bulk email fails to send.
+//   - error: A *BulkSendError listing the recipients that failed, or nil if all were sent.
 func (e *EmailService) SendBulkEmail(to []string, subject, body string, isHtml bool) error {
+	return e.SendBulkEmailContext(context.Background(), to, subject, body, isHtml)
+}
+
+// SendBulkEmailContext is like SendBulkEmail but stops sending once ctx is cancelled. Recipients that were
+// not attempted are reported in the *BulkSendError with ctx.Err().
+func (e *EmailService) SendBulkEmailContext(ctx context.Context, to []string, subject, body string, isHtml bool) error {
+	// Reject malformed addresses before sending to anyone
+	to, _, _, err := e.checkRecipients(to, nil, nil)
+	if err != nil {
+		return err
+	}
+
+	// Dry runs and custom senders deliver every message on their own
+	if e.sink != nil || e.sender != nil {
+		return e.sendBulkSeparately(ctx, to, subject, body, isHtml)
+	}
+
+	if err := validateEnvelope(e.envelopeFrom("", nil), to); err != nil {
+		return err
+	}
+
+	session := e.newSession(ctx)
+	defer session.Close()
+
+	failures := make(map[string]error)
 	for _, recipient := range to {
-		if err := e.SendEmail([]string{recipient}, subject, body, isHtml); err != nil {
-			return err
+		if err := ctx.Err(); err != nil {
+			failures[recipient] = err
+			continue
+		}
+		// Each recipient gets their own To header and, when VERP is configured, envelope sender
+		msg, err := e.bulkMessage(recipient, subject, body, isHtml)
+		if err == nil {
+			err = session.send(e.envelopeFrom("", []string{recipient}), mergeRecipients([]string{recipient}, nil, nil), msg)
+		}
+		if err != nil {
+			failures[recipient] = err
 		}
 	}
+
+	if len(failures) > 0 {
+		return &BulkSendError{Failures: failures}
+	}
+	return nil
+}
+
+// bulkMessage composes the copy of a bulk email addressed to recipient, signed and checked against the
+// size limit the way sendMail does for a single email.
+func (e *EmailService) bulkMessage(recipient, subject, body string, isHtml bool) ([]byte, error) {
+	_, msg, err := e.composeMessage(EmailMessage{To: []string{recipient}, Subject: subject, Body: body, IsHTML: isHtml})
+	if err != nil {
+		return nil, err
+	}
+	msg, err = e.signDKIM(msg)
+	if err != nil {
+		return nil, err
+	}
+	if e.maxMessageBytes > 0 && int64(len(msg)) > e.maxMessageBytes {
+		return nil, &MessageTooLargeError{Size: int64(len(msg)), Limit: e.maxMessageBytes}
+	}
+	return msg, nil
+}
+
+// sendBulkSeparately sends the email to every recipient with a separate SendEmailContext call.
+func (e *EmailService) sendBulkSeparately(ctx context.Context, to []string, subject, body string, isHtml bool) error {
+	failures := make(map[string]error)
+	for _, recipient := range to {
+		if err := ctx.Err(); err != nil {
+			failures[recipient] = err
+			continue
+		}
+		if err := e.SendEmailContext(ctx, []string{recipient}, subject, body, isHtml); err != nil {
+			failures[recipient] = err
+		}
+	}
+
+	if len(failures) > 0 {
+		return &BulkSendError{Failures: failures}
+	}
 	return nil
 }
 
@@ -289,44 +442,12 @@ func (e *EmailService) SendBulkEmail(to []string, subject, body string, isHtml b
 // Returns:
 //   - error: An error message if the email fails to send.
 func (e *EmailService) SendEmailWithCCAndBCCAndAttachments(to, cc, bcc []string, subject, body string, attachmentPaths []string, isHtml bool) error {
-	mime := "text/plain"
-	if isHtml {
-		mime = "text/html"
-	}
-
-	var buffer bytes.Buffer
-	writer := multipart.NewWriter(&buffer)
-
-	// Set headers
-	ccHeader := strings.Join(cc, ",")
-	bccHeader := strings.Join(bcc, ",")
-	headers := fmt.Sprintf("Subject: %s\r\nCC: %s\r\nBCC: %s\r\nMIME-version: 1.0;\r\nContent-Type: multipart/mixed; boundary=%s\r\n", subject, ccHeader, bccHeader, writer.Boundary())
-	buffer.Write([]byte(headers))
-
-	// Add body part
-	bodyPart, err := writer.CreatePart(map[string][]string{
-		"Content-Type": {mime + "; charset=\"UTF-8\""},
-	})
-	if err != nil {
-		return err
-	}
-	bodyPart.Write([]byte(body))
-
-	// Attach files
-	for _, path := range attachmentPaths {
-		err := e.attachFile(writer, path)
-		if err != nil {
-			return err
-		}
-	}
-	writer.Close()
-
-	// Merge recipients
-	allRecipients := append(to, cc...)
-	allRecipients = append(allRecipients, bcc...)
+	return e.SendEmailWithCCAndBCCAndAttachmentsContext(context.Background(), to, cc, bcc, subject, body, attachmentPaths, isHtml)
+}
 
-	// Send the email
-	return smtp.SendMail(e.smtpHost+":"+e.smtpPort, smtp.PlainAuth("", e.username, e.password, e.smtpHost), e.username, allRecipients, buffer.Bytes())
+// SendEmailWithCCAndBCCAndAttachmentsContext is like SendEmailWithCCAndBCCAndAttachments but honours ctx cancellation.
+func (e *EmailService) SendEmailWithCCAndBCCAndAttachmentsContext(ctx context.Context, to, cc, bcc []string, subject, body string, attachmentPaths []string, isHtml bool) error {
+	return e.Send(ctx, EmailMessage{To: to, CC: cc, BCC: bcc, Subject: subject, Body: body, IsHTML: isHtml, Attachments: attachmentPaths})
 }
 
 // SendEmailWithAttachmentsAndInLineImages sends an email with both attachments and inline images.
@@ -344,87 +465,52 @@ func (e *EmailService) SendEmailWithCCAndBCCAndAttachments(to, cc, bcc []string,
 // Returns:
 //   - error: An error message if the email fails to send.
 func (e *EmailService) SendEmailWithAttachmentsAndInLineImages(to []string, subject, body string, attachmentPaths []string, inlineImagePaths []string) error {
-	mime := "text/html"
-
-	var buffer bytes.Buffer
-	writer := multipart.NewWriter(&buffer)
-
-	// Set headers
-	headers := fmt.Sprintf("Subject: %s\r\nMIME-version: 1.0;\r\nContent-Type: multipart/mixed; boundary=%s\r\n", subject, writer.Boundary())
-	buffer.Write([]byte(headers))
-
-	// Add body part
-	bodyPart, err := writer.CreatePart(map[string][]string{
-		"Content-Type": {mime + "; charset=\"UTF-8\""},
-	})
-	if err != nil {
-		return err
-	}
-	bodyPart.Write([]byte(body))
+	return e.SendEmailWithAttachmentsAndInLineImagesContext(context.Background(), to, subject, body, attachmentPaths, inlineImagePaths)
+}
 
-	// Attach inline images
-	for _, path := range inlineImagePaths {
-		err := e.attachInlineImage(writer, path)
-		if err != nil {
-			return err
-		}
-	}
+// SendEmailWithAttachmentsAndInLineImagesContext is like SendEmailWithAttachmentsAndInLineImages but honours ctx cancellation.
+func (e *EmailService) SendEmailWithAttachmentsAndInLineImagesContext(ctx context.Context, to []string, subject, body string, attachmentPaths []string, inlineImagePaths []string) error {
+	return e.Send(ctx, EmailMessage{To: to, Subject: subject, Body: body, IsHTML: true, Attachments: attachmentPaths, InlineImages: inlineImagePaths})
+}
 
-	// Attach other files
-	for _, path := range attachmentPaths {
-		err := e.attachFile(writer, path)
-		if err != nil {
-			return err
-		}
+// formatHeaders renders custom headers as CRLF-terminated header lines.
+func formatHeaders(headers map[string]string) string {
+	headerText := ""
+	for key, value := range headers {
+		headerText += fmt.Sprintf("%s: %s\r\n", key, value)
 	}
-	writer.Close()
-
-	// Send the email
-	return smtp.SendMail(e.smtpHost+":"+e.smtpPort, smtp.PlainAuth("", e.username, e.password, e.smtpHost), e.username, to, buffer.Bytes())
+	return headerText
 }
 
-// Helper function to attach a file to the email.
-func (e *EmailService) attachFile(writer *multipart.Writer, filePath string) error {
-	file, err := os.Open(filePath)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
+// undisclosedRecipients is the To header of an email that only has CC or BCC recipients, an empty
+// RFC 5322 group that tells mail clients the recipients were left out on purpose.
+const undisclosedRecipients = "undisclosed-recipients:;"
 
-	part, err := writer.CreateFormFile("attachment", filepath.Base(filePath))
-	if err != nil {
-		return err
+// toHeader renders the visible To header line, falling back to undisclosedRecipients when the email is
+// only addressed through CC or BCC, e.g. an announcement sent to everyone as BCC.
+func toHeader(to []string) string {
+	if len(to) == 0 {
+		return fmt.Sprintf("To: %s\r\n", undisclosedRecipients)
 	}
-
-	_, err = part.Write([]byte(filePath))
-	return err
+	return fmt.Sprintf("To: %s\r\n", strings.Join(to, ", "))
 }
 
-// Helper function to attach an inline image to the email.
-func (e *EmailService) attachInlineImage(writer *multipart.Writer, imagePath string) error {
-	file, err := os.Open(imagePath)
-	if err != nil {
-		return err
+// ccHeader renders the visible CC header line. BCC recipients are deliberately never written to the
+// message, they only receive it through the SMTP envelope.
+func ccHeader(cc []string) string {
+	if len(cc) == 0 {
+		return ""
 	}
-	defer file.Close()
-
-	// Get the file's MIME type
-	mimeType := "image/" + strings.TrimPrefix(filepath.Ext(imagePath), ".")
-	partHeader := make(map[string][]string)
-	partHeader["Content-Type"] = []string{mimeType}
-	partHeader["Content-Transfer-Encoding"] = []string{"base64"}
-	partHeader["Content-Disposition"] = []string{`inline; filename="` + filepath.Base(imagePath) + `";`}
-	partHeader["Content-ID"] = []string{`<` + filepath.Base(imagePath) + `>`}
+	return fmt.Sprintf("CC: %s\r\n", strings.Join(cc, ", "))
+}
 
-	part, err := writer.CreatePart(partHeader)
-	if err != nil {
-		return err
+// mergeRecipients combines the To, CC and BCC lists into the bare SMTP envelope recipient addresses.
+func mergeRecipients(to, cc, bcc []string) []string {
+	allRecipients := make([]string, 0, len(to)+len(cc)+len(bcc))
+	for _, list := range [][]string{to, cc, bcc} {
+		for _, address := range list {
+			allRecipients = append(allRecipients, envelopeAddress(address))
+		}
 	}
-
-	// Read the image and encode it in base64
-	imageData := make([]byte, base64.StdEncoding.EncodedLen(len(imagePath)))
-	base64.StdEncoding.Encode(imageData, []byte(imagePath))
-
-	_, err = part.Write(imageData)
-	return err
+	return allRecipients
 }
@@ -0,0 +1,118 @@
+package gophersmtp
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"path/filepath"
+)
+
+// Attachment is a file attached from memory rather than from a path, e.g. a report generated on the fly.
+//
+// Fields:
+//   - Filename: The name shown to the recipient, e.g. "report.pdf".
+//   - ContentType: The MIME type of the data; empty derives it from the extension of Filename.
+//   - Data: The content, read once when the email is composed.
+//
+// Example usage:
+//
+//	var csv bytes.Buffer
+//	writeReport(&csv)
+//
+//	err := service.Send(ctx, EmailMessage{
+//	    To:             []string{"finance@example.com"},
+//	    Subject:        "Monthly report",
+//	    Body:           "The report is attached.",
+//	    AttachmentData: []Attachment{{Filename: "report.csv", Data: &csv}},
+//	})
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Data        io.Reader
+}
+
+// ScheduledAttachment is an Attachment as recorded in a ScheduleStore, with its content read into memory.
+type ScheduledAttachment struct {
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type,omitempty"`
+	Data        []byte `json:"data"`
+}
+
+// readAttachments reads the content of in-memory attachments, so that a scheduled email can be
+// stored and composed again when it is due.
+func readAttachments(attachments []Attachment) ([]ScheduledAttachment, error) {
+	if len(attachments) == 0 {
+		return nil, nil
+	}
+
+	scheduled := make([]ScheduledAttachment, 0, len(attachments))
+	for _, attachment := range attachments {
+		if attachment.Data == nil {
+			return nil, fmt.Errorf("attachment %s has no data", attachment.Filename)
+		}
+		data, err := io.ReadAll(attachment.Data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read attachment %s: %w", attachment.Filename, err)
+		}
+		scheduled = append(scheduled, ScheduledAttachment{
+			Filename:    attachment.Filename,
+			ContentType: attachment.ContentType,
+			Data:        data,
+		})
+	}
+	return scheduled, nil
+}
+
+// scheduledAttachments turns recorded attachments back into ones that can be composed.
+func scheduledAttachments(scheduled []ScheduledAttachment) []Attachment {
+	if len(scheduled) == 0 {
+		return nil
+	}
+
+	attachments := make([]Attachment, 0, len(scheduled))
+	for _, attachment := range scheduled {
+		attachments = append(attachments, Attachment{
+			Filename:    attachment.Filename,
+			ContentType: attachment.ContentType,
+			Data:        bytes.NewReader(attachment.Data),
+		})
+	}
+	return attachments
+}
+
+// attachData adds an in-memory attachment to the email as a base64 encoded part.
+//
+// Params:
+//   - writer: The multipart writer of the email being composed.
+//   - attachment: The attachment to add.
+//
+// Returns:
+//   - error: An error naming the attachment if it has no name or data, or its data cannot be read.
+func attachData(writer *multipart.Writer, attachment Attachment) error {
+	if attachment.Filename == "" {
+		return fmt.Errorf("attachment has no file name")
+	}
+	if attachment.Data == nil {
+		return fmt.Errorf("attachment %s has no data", attachment.Filename)
+	}
+
+	contentType := attachment.ContentType
+	if contentType == "" {
+		contentType = contentTypeByExtension(attachment.Filename)
+	}
+
+	part, err := writer.CreatePart(map[string][]string{
+		"Content-Type":              {contentType},
+		"Content-Transfer-Encoding": {"base64"},
+		"Content-Disposition":       {fmt.Sprintf("attachment; filename=%q", filepath.Base(attachment.Filename))},
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := writeBase64(part, attachment.Data); err != nil {
+		return fmt.Errorf("failed to read attachment %s: %w", attachment.Filename, err)
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package gophersmtp
+
+import (
+	"errors"
+	"fmt"
+	"net/smtp"
+	"strings"
+)
+
+// AuthMechanism selects how the service authenticates with the SMTP server.
+type AuthMechanism string
+
+const (
+	// AuthNone skips authentication, for local relays that accept unauthenticated mail.
+	AuthNone AuthMechanism = ""
+	// AuthPlain uses the PLAIN mechanism. This is the default.
+	AuthPlain AuthMechanism = "plain"
+	// AuthLogin uses the LOGIN mechanism, common on older corporate relays.
+	AuthLogin AuthMechanism = "login"
+	// AuthCRAMMD5 uses the CRAM-MD5 challenge-response mechanism.
+	AuthCRAMMD5 AuthMechanism = "crammd5"
+)
+
+// auth returns the SMTP authentication for the configured mechanism, or nil for AuthNone.
+func (e *EmailService) auth() (smtp.Auth, error) {
+	switch e.authMechanism {
+	case AuthNone:
+		return nil, nil
+	case AuthPlain:
+		return smtp.PlainAuth("", e.username, e.password, e.smtpHost), nil
+	case AuthLogin:
+		return &loginAuth{username: e.username, password: e.password, host: e.smtpHost}, nil
+	case AuthCRAMMD5:
+		return smtp.CRAMMD5Auth(e.username, e.password), nil
+	default:
+		return nil, fmt.Errorf("unsupported SMTP auth mechanism %q", e.authMechanism)
+	}
+}
+
+// loginAuth implements the LOGIN authentication mechanism, which net/smtp does not provide.
+type loginAuth struct {
+	username string
+	password string
+	host     string
+}
+
+// Start begins the LOGIN exchange. Like smtp.PlainAuth, it refuses to send credentials
+// over an unencrypted connection unless the server is on localhost.
+func (a *loginAuth) Start(server *smtp.ServerInfo) (string, []byte, error) {
+	if !server.TLS && !isLocalhost(server.Name) {
+		return "", nil, errors.New("unencrypted connection")
+	}
+	if server.Name != a.host {
+		return "", nil, errors.New("wrong host name")
+	}
+	return "LOGIN", nil, nil
+}
+
+// Next answers the server's username and password prompts.
+func (a *loginAuth) Next(fromServer []byte, more bool) ([]byte, error) {
+	if !more {
+		return nil, nil
+	}
+
+	prompt := strings.ToLower(strings.TrimSpace(string(fromServer)))
+	switch {
+	case strings.HasPrefix(prompt, "user"):
+		return []byte(a.username), nil
+	case strings.HasPrefix(prompt, "pass"):
+		return []byte(a.password), nil
+	default:
+		return nil, fmt.Errorf("unexpected LOGIN challenge %q", fromServer)
+	}
+}
+
+// isLocalhost reports whether name refers to the local machine.
+func isLocalhost(name string) bool {
+	return name == "localhost" || name == "127.0.0.1" || name == "::1"
+}
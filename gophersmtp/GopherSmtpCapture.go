@@ -0,0 +1,76 @@
+package gophersmtp
+
+import "sync"
+
+// CapturedMessage is an email that was handed to a MessageSink instead of an SMTP server.
+type CapturedMessage struct {
+	// From is the envelope sender address.
+	From string
+	// To holds the envelope recipient addresses, BCC recipients included.
+	To []string
+	// Data is the full MIME message, headers included, exactly as it would have been sent.
+	Data []byte
+}
+
+// MessageSink receives composed emails when the service runs in dry-run mode.
+type MessageSink interface {
+	// Capture is called once per delivery in place of the SMTP exchange. A returned error is
+	// reported to the caller as a failed send.
+	Capture(msg CapturedMessage) error
+}
+
+// MemorySink is a MessageSink that keeps captured emails in memory so tests can inspect them.
+// It is safe for concurrent use, which makes it suitable for EmailRoutineService as well.
+//
+// Example usage:
+//
+//	sink := NewMemorySink()
+//	service := NewEmailService("smtp.example.com", "587", "user", "password", WithDryRun(sink))
+//	service.SendEmailWithAttachments([]string{"a@example.com"}, "Report", "See attached", []string{"report.pdf"}, false)
+//	msg, _ := sink.LastMessage()
+//	fmt.Println(string(msg.Data))
+type MemorySink struct {
+	mu       sync.Mutex
+	messages []CapturedMessage
+}
+
+// NewMemorySink creates an empty MemorySink.
+func NewMemorySink() *MemorySink {
+	return &MemorySink{}
+}
+
+// Capture records the message.
+func (s *MemorySink) Capture(msg CapturedMessage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.messages = append(s.messages, msg)
+	return nil
+}
+
+// Messages returns every captured message in the order it was sent.
+func (s *MemorySink) Messages() []CapturedMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]CapturedMessage(nil), s.messages...)
+}
+
+// LastMessage returns the most recently captured message, or false if nothing has been sent yet.
+func (s *MemorySink) LastMessage() (CapturedMessage, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.messages) == 0 {
+		return CapturedMessage{}, false
+	}
+	return s.messages[len(s.messages)-1], true
+}
+
+// Reset discards every captured message.
+func (s *MemorySink) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.messages = nil
+}
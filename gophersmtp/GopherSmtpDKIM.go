@@ -0,0 +1,144 @@
+package gophersmtp
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+
+	"github.com/emersion/go-msgauth/dkim"
+)
+
+// DKIMCanonicalization selects how headers or the body are normalised before they are signed.
+type DKIMCanonicalization string
+
+const (
+	// DKIMCanonicalizationRelaxed tolerates whitespace and header case changes made by relays. This is the default.
+	DKIMCanonicalizationRelaxed DKIMCanonicalization = "relaxed"
+	// DKIMCanonicalizationSimple tolerates almost no modification of the message in transit.
+	DKIMCanonicalizationSimple DKIMCanonicalization = "simple"
+)
+
+// DefaultDKIMHeaderKeys are the header fields signed when DKIMConfig.HeaderKeys is empty, following the
+// recommendation of RFC 6376 section 5.4.1. Listed fields a message lacks are signed as empty, which
+// keeps them from being added in transit.
+var DefaultDKIMHeaderKeys = []string{
+	"From", "Reply-To", "Subject", "Date", "To", "Cc",
+	"Message-ID", "In-Reply-To", "References",
+	"MIME-Version", "Content-Type", "Content-Transfer-Encoding",
+}
+
+// DKIMConfig holds the settings used to add a DKIM-Signature header to outgoing emails.
+//
+// Fields:
+//   - Domain: The signing domain (d=), under which the public key is published in DNS.
+//   - Selector: The selector (s=); the public key is looked up at <selector>._domainkey.<domain>.
+//   - PrivateKey: The signing key, an *rsa.PrivateKey or an ed25519.PrivateKey.
+//   - HeaderCanonicalization, BodyCanonicalization: Empty uses DKIMCanonicalizationRelaxed.
+//   - HeaderKeys: The header fields to sign, which must include "From"; empty uses DefaultDKIMHeaderKeys.
+type DKIMConfig struct {
+	Domain     string
+	Selector   string
+	PrivateKey crypto.Signer
+
+	HeaderCanonicalization DKIMCanonicalization
+	BodyCanonicalization   DKIMCanonicalization
+	HeaderKeys             []string
+}
+
+// WithDKIM signs every outgoing email with DKIM, so receiving servers can verify it was sent on behalf
+// of config.Domain. Emails captured WithDryRun are signed as well. An incomplete configuration makes
+// every send fail with an error.
+//
+// Example usage:
+//
+//	key, err := ParseDKIMPrivateKey(pemBytes)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	service := NewEmailService("smtp.example.com", "587", "user", "password",
+//	    WithDKIM(DKIMConfig{Domain: "example.com", Selector: "mail", PrivateKey: key}))
+func WithDKIM(config DKIMConfig) EmailOption {
+	return func(e *EmailService) {
+		e.dkim = &config
+	}
+}
+
+// ParseDKIMPrivateKey parses a PEM encoded RSA or Ed25519 private key in PKCS #1 or PKCS #8 form,
+// as generated for DKIM with e.g. `openssl genrsa -out dkim.pem 2048`.
+//
+// Params:
+//   - pemBytes: The contents of the PEM file.
+//
+// Returns:
+//   - crypto.Signer: The key, ready for DKIMConfig.PrivateKey.
+//   - error: An error if no PEM block is found or the key type is not supported.
+func ParseDKIMPrivateKey(pemBytes []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(pemBytes)
+	if block == nil {
+		return nil, errors.New("failed to parse DKIM private key: no PEM block found")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse DKIM private key: %w", err)
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported DKIM private key type %T", key)
+	}
+	return signer, nil
+}
+
+// signDKIM returns msg with a DKIM-Signature header prepended, or msg unchanged when DKIM is not configured.
+//
+// Line endings are normalised to CRLF first, so the signed bytes are exactly those sent in the DATA command.
+func (e *EmailService) signDKIM(msg []byte) ([]byte, error) {
+	if e.dkim == nil {
+		return msg, nil
+	}
+
+	config := e.dkim
+	if config.Domain == "" || config.Selector == "" || config.PrivateKey == nil {
+		return nil, errors.New("DKIM signing requires a domain, a selector and a private key")
+	}
+
+	headerKeys := config.HeaderKeys
+	if len(headerKeys) == 0 {
+		headerKeys = DefaultDKIMHeaderKeys
+	}
+
+	options := &dkim.SignOptions{
+		Domain:                 config.Domain,
+		Selector:               config.Selector,
+		Signer:                 config.PrivateKey,
+		HeaderCanonicalization: dkimCanonicalization(config.HeaderCanonicalization),
+		BodyCanonicalization:   dkimCanonicalization(config.BodyCanonicalization),
+		HeaderKeys:             headerKeys,
+	}
+
+	var signed bytes.Buffer
+	if err := dkim.Sign(&signed, bytes.NewReader(normalizeCRLF(msg)), options); err != nil {
+		return nil, fmt.Errorf("failed to sign email with DKIM: %w", err)
+	}
+	return signed.Bytes(), nil
+}
+
+// dkimCanonicalization converts a canonicalization setting, defaulting to relaxed.
+func dkimCanonicalization(canonicalization DKIMCanonicalization) dkim.Canonicalization {
+	if canonicalization == "" {
+		return dkim.CanonicalizationRelaxed
+	}
+	return dkim.Canonicalization(canonicalization)
+}
+
+// normalizeCRLF turns every bare LF into CRLF, as the SMTP DATA command does on the wire.
+func normalizeCRLF(msg []byte) []byte {
+	normalized := bytes.ReplaceAll(msg, []byte("\r\n"), []byte("\n"))
+	return bytes.ReplaceAll(normalized, []byte("\n"), []byte("\r\n"))
+}
@@ -0,0 +1,83 @@
+package gophersmtp
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"testing"
+
+	"github.com/emersion/go-msgauth/dkim"
+)
+
+func TestSendEmailWithDKIMVerifies(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate RSA key: %v", err)
+	}
+	rsaPublic, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	if err != nil {
+		t.Fatalf("failed to marshal public key: %v", err)
+	}
+	edPublic, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate Ed25519 key: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		key    crypto.Signer
+		record string
+	}{
+		{"rsa", rsaKey, "v=DKIM1; k=rsa; p=" + base64.StdEncoding.EncodeToString(rsaPublic)},
+		{"ed25519", edKey, "v=DKIM1; k=ed25519; p=" + base64.StdEncoding.EncodeToString(edPublic)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newFakeSMTPServer(t)
+			service := server.service(WithDKIM(DKIMConfig{Domain: "example.com", Selector: "mail", PrivateKey: tt.key}))
+			if err := service.SendEmailContext(context.Background(), []string{"a@example.com"}, "Hello", "Hi there\nSee you", false); err != nil {
+				t.Fatalf("SendEmail failed: %v", err)
+			}
+			messages := server.Messages()
+			if len(messages) != 1 {
+				t.Fatalf("server received %d messages, want 1", len(messages))
+			}
+			// The server reads the message with LF line endings, restore those sent on the wire
+			data := normalizeCRLF(messages[0].Data)
+
+			// lookup serves the public key where a verifier finds it in DNS
+			lookup := func(domain string) ([]string, error) {
+				if domain != "mail._domainkey.example.com" {
+					return nil, fmt.Errorf("no TXT record for %s", domain)
+				}
+				return []string{tt.record}, nil
+			}
+			verifications, err := dkim.VerifyWithOptions(bytes.NewReader(data), &dkim.VerifyOptions{LookupTXT: lookup})
+			if err != nil {
+				t.Fatalf("Verify failed: %v", err)
+			}
+			if len(verifications) != 1 {
+				t.Fatalf("message has %d signatures, want 1", len(verifications))
+			}
+			if v := verifications[0]; v.Err != nil || v.Domain != "example.com" {
+				t.Fatalf("signature for %q failed verification: %v", v.Domain, v.Err)
+			}
+
+			// A relay changing the body breaks the signature
+			tampered := bytes.Replace(data, []byte("See you"), []byte("Pay now"), 1)
+			verifications, err = dkim.VerifyWithOptions(bytes.NewReader(tampered), &dkim.VerifyOptions{LookupTXT: lookup})
+			if err != nil {
+				t.Fatalf("Verify failed: %v", err)
+			}
+			if len(verifications) != 1 || verifications[0].Err == nil {
+				t.Fatal("signature of the tampered message verified")
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package gophersmtp
+
+import "strings"
+
+// WithVERP sends every email addressed to a single recipient, including each email of SendBulkEmail,
+// with a variable envelope return path (VERP) derived from bounceAddress, see VERPAddress. Bounces
+// then arrive at an address that names the recipient they are about, even when the bounce message
+// itself does not. Emails to several recipients use bounceAddress unchanged as envelope sender.
+//
+// The From header is not affected. The mail server of bounceAddress must deliver the tagged addresses
+// to the bounce mailbox, which most do with the "+" subaddress separator.
+//
+// Example usage:
+//
+//	service := NewEmailService("smtp.example.com", "587", "user", "password",
+//	    WithFrom("Acme", "news@acme.com"), WithVERP("bounces@acme.com"))
+func WithVERP(bounceAddress string) EmailOption {
+	return func(e *EmailService) {
+		e.verpAddress = bounceAddress
+	}
+}
+
+// VERPAddress encodes recipient into bounceAddress as a variable envelope return path, so that
+// VERPAddress("bounces@acme.com", "ada@example.com") returns "bounces+ada=example.com@acme.com".
+//
+// Params:
+//   - bounceAddress: The address bounces are collected at.
+//   - recipient: The recipient to tag the address with, bare or in `Name <address>` form.
+//
+// Returns:
+//   - string: The tagged address, or bounceAddress unchanged when either address has no domain.
+func VERPAddress(bounceAddress, recipient string) string {
+	local, domain, found := strings.Cut(bounceAddress, "@")
+	if !found {
+		return bounceAddress
+	}
+	recipientLocal, recipientDomain, found := strings.Cut(envelopeAddress(recipient), "@")
+	if !found {
+		return bounceAddress
+	}
+	return local + "+" + recipientLocal + "=" + recipientDomain + "@" + domain
+}
+
+// envelopeFrom returns the MAIL FROM address for an email to recipients: the override given with the
+// message, the VERP address configured WithVERP, or the sender address.
+func (e *EmailService) envelopeFrom(override string, recipients []string) string {
+	switch {
+	case override != "":
+		return override
+	case e.verpAddress != "" && len(recipients) == 1:
+		return VERPAddress(e.verpAddress, recipients[0])
+	case e.verpAddress != "":
+		return e.verpAddress
+	default:
+		return e.senderAddress()
+	}
+}
@@ -0,0 +1,107 @@
+package gophersmtp
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrServiceClosed is returned by EmailRoutineService send methods after Close has been called.
+var ErrServiceClosed = errors.New("email service is closed")
+
+// ErrNoRecipients is returned when a send is attempted without a single recipient address.
+var ErrNoRecipients = errors.New("no recipients given")
+
+// InvalidRecipientsError is returned before anything is sent when one or more recipient addresses
+// cannot be parsed as RFC 5322 addresses.
+//
+// Example usage:
+//
+//	err := service.SendEmail([]string{"ada@example.com", "not-an-address"}, "Hello", "Hi", false)
+//	var invalidErr *InvalidRecipientsError
+//	if errors.As(err, &invalidErr) {
+//	    log.Printf("fix these addresses: %v", invalidErr.Addresses)
+//	}
+type InvalidRecipientsError struct {
+	Addresses []string
+}
+
+// Error lists every invalid address in the order it was given.
+func (e *InvalidRecipientsError) Error() string {
+	quoted := make([]string, 0, len(e.Addresses))
+	for _, address := range e.Addresses {
+		quoted = append(quoted, fmt.Sprintf("%q", address))
+	}
+	return fmt.Sprintf("invalid recipient address(es): %s", strings.Join(quoted, ", "))
+}
+
+// BulkSendError is returned by SendBulkEmail when one or more recipients could not be sent to.
+//
+// Every recipient is attempted, so the addresses missing from Failures were sent successfully
+// and callers can retry only the failed ones.
+//
+// Example usage:
+//
+//	err := service.SendBulkEmail(recipients, "Newsletter", body, true)
+//	var bulkErr *BulkSendError
+//	if errors.As(err, &bulkErr) {
+//	    for _, recipient := range bulkErr.Recipients() {
+//	        log.Printf("retrying %s: %v", recipient, bulkErr.Failures[recipient])
+//	    }
+//	}
+type BulkSendError struct {
+	Failures map[string]error
+}
+
+// Error lists every failed recipient together with the reason it failed.
+func (e *BulkSendError) Error() string {
+	details := make([]string, 0, len(e.Failures))
+	for _, recipient := range e.Recipients() {
+		details = append(details, fmt.Sprintf("%s: %v", recipient, e.Failures[recipient]))
+	}
+	return fmt.Sprintf("failed to send email to %d recipient(s): %s", len(e.Failures), strings.Join(details, "; "))
+}
+
+// Recipients returns the failed recipient addresses in sorted order.
+func (e *BulkSendError) Recipients() []string {
+	recipients := make([]string, 0, len(e.Failures))
+	for recipient := range e.Failures {
+		recipients = append(recipients, recipient)
+	}
+	sort.Strings(recipients)
+	return recipients
+}
+
+// Unwrap returns the individual send errors so errors.Is and errors.As can inspect them.
+func (e *BulkSendError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Failures))
+	for _, recipient := range e.Recipients() {
+		errs = append(errs, e.Failures[recipient])
+	}
+	return errs
+}
+
+// MessageTooLargeError is returned before anything is sent when a message exceeds the limit set with
+// WithMaxMessageBytes. Size accounts for the base64 encoding of attachments and inline images.
+type MessageTooLargeError struct {
+	Size  int64
+	Limit int64
+}
+
+// Error reports the message size together with the configured limit.
+func (e *MessageTooLargeError) Error() string {
+	return fmt.Sprintf("message size of %d bytes exceeds the limit of %d bytes", e.Size, e.Limit)
+}
+
+// TooManyAttachmentsError is returned before anything is read or sent when an email has more
+// attachments than the limit set with WithMaxAttachments.
+type TooManyAttachmentsError struct {
+	Count int
+	Limit int
+}
+
+// Error reports the number of attachments together with the configured limit.
+func (e *TooManyAttachmentsError) Error() string {
+	return fmt.Sprintf("%d attachments exceed the limit of %d attachments", e.Count, e.Limit)
+}
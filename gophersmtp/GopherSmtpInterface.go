@@ -1,36 +1,121 @@
 package gophersmtp
 
 import (
+	"context"
+	"html/template"
 	"time"
 )
 
 type GopherSmtpInterface interface {
+	// Send composes and sends msg, or schedules it when msg.SendAt is set. The other methods are shorthands for it.
+	Send(ctx context.Context, msg EmailMessage) error
+
 	// SendEmail sends an email to the recipients. The isHtml flag determines whether it's text or HTML.
 	SendEmail(to []string, subject, body string, isHtml bool) error
 
+	// SendEmailContext is like SendEmail but honours ctx cancellation and deadlines.
+	SendEmailContext(ctx context.Context, to []string, subject, body string, isHtml bool) error
+
+	// SendTextEmail sends a plain text email to the recipients.
+	SendTextEmail(to []string, subject, body string) error
+
+	// SendHTMLEmail sends an HTML email to the recipients.
+	SendHTMLEmail(to []string, subject, body string) error
+
+	// SendTemplateEmail renders an HTML template with data and sends the result as an HTML email.
+	SendTemplateEmail(to []string, subject string, tmpl *template.Template, data any) error
+
 	// SendEmailWithAttachments sends an email with attachments. The isHtml flag determines text or HTML format.
 	SendEmailWithAttachments(to []string, subject, body string, attachmentPaths []string, isHtml bool) error
 
+	// SendEmailWithAttachmentsContext is like SendEmailWithAttachments but honours ctx cancellation and deadlines.
+	SendEmailWithAttachmentsContext(ctx context.Context, to []string, subject, body string, attachmentPaths []string, isHtml bool) error
+
+	// SendEmailWithAttachment sends an email with a single attachment. The isHtml flag determines text or HTML format.
+	SendEmailWithAttachment(to []string, subject, body string, attachmentPath string, isHtml bool) error
+
 	// SendEmailWithHeaders sends an email with custom headers. The isHtml flag determines text or HTML format.
 	SendEmailWithHeaders(to []string, subject, body string, headers map[string]string, isHtml bool) error
 
+	// SendEmailWithHeadersContext is like SendEmailWithHeaders but honours ctx cancellation and deadlines.
+	SendEmailWithHeadersContext(ctx context.Context, to []string, subject, body string, headers map[string]string, isHtml bool) error
+
 	// ScheduleEmail schedules an email to be sent at a specific time. The isHtml flag determines text or HTML format.
 	ScheduleEmail(to []string, subject, body string, sendAt time.Time, isHtml bool) error
 
+	// ScheduleEmailContext is like ScheduleEmail; cancelling ctx stops the email from being sent by this
+	// process but keeps it in the schedule store.
+	ScheduleEmailContext(ctx context.Context, to []string, subject, body string, sendAt time.Time, isHtml bool) error
+
+	// ResumeScheduled sends or reschedules the emails left in the schedule store by a previous run.
+	ResumeScheduled() error
+
+	// ResumeScheduledContext is like ResumeScheduled; cancelling ctx stops the resumed emails not yet sent
+	// but keeps them in the schedule store.
+
+	// CancelScheduled drops the scheduled email with the given ID from the schedule store and this process.
+	CancelScheduled(id string) error
+	ResumeScheduledContext(ctx context.Context) error
+
 	// SendEmailWithCCAndBCC sends an email with CC and BCC recipients. The isHtml flag determines text or HTML format.
 	SendEmailWithCCAndBCC(to []string, cc []string, bcc []string, subject, body string, isHtml bool) error
 
+	// SendEmailWithCCAndBCCContext is like SendEmailWithCCAndBCC but honours ctx cancellation and deadlines.
+	SendEmailWithCCAndBCCContext(ctx context.Context, to []string, cc []string, bcc []string, subject, body string, isHtml bool) error
+
 	// SendBulkEmail sends bulk emails. The isHtml flag determines text or HTML format.
 	SendBulkEmail(to []string, subject, body string, isHtml bool) error
 
+	// SendBulkEmailContext is like SendBulkEmail but honours ctx cancellation and deadlines.
+	SendBulkEmailContext(ctx context.Context, to []string, subject, body string, isHtml bool) error
+
 	// SendEmailWithInLineImages sends an email with inline images.
 	// Only applicable for HTML emails.
 	SendEmailWithInLineImages(to []string, subject, body string, imagePaths []string) error
 
+	// SendEmailWithInLineImagesContext is like SendEmailWithInLineImages but honours ctx cancellation and deadlines.
+	SendEmailWithInLineImagesContext(ctx context.Context, to []string, subject, body string, imagePaths []string) error
+
 	// SendEmailWithCCAndBCCAndAttachments sends an email with CC, BCC, and attachments. The isHtml flag determines text or HTML format.
 	SendEmailWithCCAndBCCAndAttachments(to []string, cc []string, bcc []string, subject, body string, attachmentPaths []string, isHtml bool) error
 
+	// SendEmailWithCCAndBCCAndAttachmentsContext is like SendEmailWithCCAndBCCAndAttachments but honours ctx cancellation and deadlines.
+	SendEmailWithCCAndBCCAndAttachmentsContext(ctx context.Context, to []string, cc []string, bcc []string, subject, body string, attachmentPaths []string, isHtml bool) error
+
 	// SendEmailWithAttachmentsAndInLineImages sends an email with both attachments and inline images.
 	// Only applicable for HTML emails.
 	SendEmailWithAttachmentsAndInLineImages(to []string, subject, body string, attachmentPaths, imagePaths []string) error
+
+	// SendEmailWithAttachmentsAndInLineImagesContext is like SendEmailWithAttachmentsAndInLineImages but honours ctx cancellation and deadlines.
+	SendEmailWithAttachmentsAndInLineImagesContext(ctx context.Context, to []string, subject, body string, attachmentPaths, imagePaths []string) error
+}
+
+// GopherSmtpRoutineInterface is implemented by services that send asynchronously and report
+// the outcome of each send on a results channel.
+type GopherSmtpRoutineInterface interface {
+	GopherSmtpInterface
+
+	// Results returns the channel on which the outcome of every send is published.
+	Results() <-chan EmailResult
+
+	// Close stops the service, cancelling scheduled emails and waiting for in-flight sends.
+	Close() error
+}
+
+// GopherMailboxInterface reads incoming email from an IMAP mailbox.
+type GopherMailboxInterface interface {
+	// Connect dials the IMAP server, logs in and selects the INBOX.
+	Connect(ctx context.Context) error
+
+	// ListUnread fetches every unread message without marking it as read.
+	ListUnread(ctx context.Context) ([]ReceivedEmail, error)
+
+	// FetchMessage fetches the message with the given UID without marking it as read.
+	FetchMessage(ctx context.Context, uid uint32) (*ReceivedEmail, error)
+
+	// MarkRead flags the message with the given UID as seen.
+	MarkRead(ctx context.Context, uid uint32) error
+
+	// Close logs out and closes the connection.
+	Close() error
 }
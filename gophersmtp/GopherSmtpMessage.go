@@ -0,0 +1,142 @@
+package gophersmtp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// EmailMessage describes one email with every option Send supports, as an alternative to picking the
+// SendEmailWith... method that matches a combination of options.
+//
+// Fields:
+//   - To, CC, BCC: The recipients. CC recipients are listed in the headers, BCC recipients only in the envelope.
+//     To may be empty when there are CC or BCC recipients; the To header then reads "undisclosed-recipients:;".
+//   - Subject: The subject of the email.
+//   - Body: The content of the email.
+//   - IsHTML: Whether the body is HTML rather than plain text.
+//   - Attachments: File paths to attach.
+//   - AttachmentData: Attachments from memory, e.g. a generated PDF, attached after the files.
+//   - InlineImages: Image paths to embed, referenced from an HTML body as `<img src="cid:logo.png">`.
+//   - Headers: Custom headers, e.g. "X-Priority".
+//   - ReplyTo: Addresses replies go to; overrides WithReplyTo for this email.
+//   - SendAt: When set, the email is scheduled like ScheduleEmail instead of being sent right away.
+//   - EnvelopeFrom: The SMTP envelope sender (MAIL FROM) bounces are returned to, e.g. an address from
+//     VERPAddress; overrides WithVERP for this email. The From header is not affected.
+//
+// Example usage:
+//
+//	err := service.Send(ctx, EmailMessage{
+//	    To:          []string{"user@example.com"},
+//	    BCC:         []string{"archive@example.com"},
+//	    Subject:     "Your invoice",
+//	    Body:        "<p>Please find your invoice attached.</p>",
+//	    IsHTML:      true,
+//	    Attachments: []string{"invoices/2024-09.pdf"},
+//	})
+type EmailMessage struct {
+	To             []string
+	CC             []string
+	BCC            []string
+	Subject        string
+	Body           string
+	IsHTML         bool
+	Attachments    []string
+	AttachmentData []Attachment
+	InlineImages   []string
+	Headers        map[string]string
+	ReplyTo        []string
+	SendAt         time.Time
+	EnvelopeFrom   string
+}
+
+// Send composes msg and sends it, or schedules it when msg.SendAt is set.
+//
+// A single-part email is sent when there are no attachments or inline images. Inline images alone
+// produce a multipart/related email and attachments a multipart/mixed one.
+//
+// Params:
+//   - ctx: Context controlling cancellation; for scheduled emails, cancelling it before SendAt stops this
+//     process from sending the email but keeps it in the schedule store (see CancelScheduled).
+//   - msg: The email to send.
+//
+// Returns:
+//   - error: An error if a recipient is invalid, a file cannot be read, SendAt is in the past, or the email fails to send.
+func (e *EmailService) Send(ctx context.Context, msg EmailMessage) error {
+	if !msg.SendAt.IsZero() {
+		email, err := e.scheduleMessage(msg)
+		if err != nil {
+			return err
+		}
+		e.goScheduled(ctx, email)
+		return nil
+	}
+
+	recipients, data, err := e.composeMessage(msg)
+	if err != nil {
+		return err
+	}
+	return e.sendMail(ctx, e.envelopeFrom(msg.EnvelopeFrom, recipients), mergeRecipients(recipients, nil, nil), data)
+}
+
+// composeMessage checks the recipients of msg and composes it.
+//
+// Returns:
+//   - []string: The To, CC and BCC recipients, in that order, for the SMTP envelope.
+//   - []byte: The composed message.
+//   - error: An error if a recipient is invalid or the message cannot be composed.
+func (e *EmailService) composeMessage(msg EmailMessage) ([]string, []byte, error) {
+	to, cc, bcc, err := e.checkRecipients(msg.To, msg.CC, msg.BCC)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	customHeaders := formatHeaders(msg.Headers)
+	headers := ""
+	if !hasHeader(customHeaders, "To") {
+		headers = toHeader(to)
+	}
+	headers += ccHeader(cc)
+	if len(msg.ReplyTo) > 0 {
+		headers += fmt.Sprintf("Reply-To: %s\r\n", strings.Join(msg.ReplyTo, ", "))
+	}
+	headers += customHeaders
+
+	var data []byte
+	switch {
+	case len(msg.Attachments) > 0 || len(msg.AttachmentData) > 0:
+		data, err = e.buildMultipartMessage("multipart/mixed", msg.Subject, msg.Body, msg.IsHTML, headers, msg.Attachments, msg.InlineImages, msg.AttachmentData)
+	case len(msg.InlineImages) > 0:
+		data, err = e.buildMultipartMessage("multipart/related", msg.Subject, msg.Body, msg.IsHTML, headers, nil, msg.InlineImages, nil)
+	default:
+		data = e.buildMessage(msg.Subject, msg.Body, msg.IsHTML, headers)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	recipients := make([]string, 0, len(to)+len(cc)+len(bcc))
+	recipients = append(append(append(recipients, to...), cc...), bcc...)
+	return recipients, data, nil
+}
+
+// scheduleMessage checks that msg is due in the future and can be composed, then records it in the
+// schedule store, if any. In-memory attachments are read at this point.
+func (e *EmailService) scheduleMessage(msg EmailMessage) (ScheduledEmail, error) {
+	if time.Until(msg.SendAt) <= 0 {
+		return ScheduledEmail{}, fmt.Errorf("scheduled time is in the past")
+	}
+
+	email, err := newScheduledEmail(msg)
+	if err != nil {
+		return ScheduledEmail{}, err
+	}
+	if _, _, err := e.composeMessage(email.message()); err != nil {
+		return ScheduledEmail{}, err
+	}
+	if err := e.persistScheduled(&email); err != nil {
+		return ScheduledEmail{}, err
+	}
+	return email, nil
+}
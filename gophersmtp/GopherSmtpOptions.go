@@ -0,0 +1,186 @@
+package gophersmtp
+
+import (
+	"crypto/tls"
+	"time"
+)
+
+// Delays between the attempts of WithRetry: the first when it is given no backoff, and the most the
+// doubling delay grows to.
+const (
+	defaultRetryBackoff = time.Second
+	maxRetryBackoff     = time.Minute
+)
+
+// EmailOption configures optional behaviour of an EmailService or EmailRoutineService.
+//
+// Example usage:
+//
+//	service := NewEmailService("smtp.example.com", "587", "user", "password",
+//	    WithAuthMechanism(AuthLogin))
+type EmailOption func(*EmailService)
+
+// WithTLS secures the SMTP connection according to mode, using tlsConfig when it is not nil.
+func WithTLS(mode TLSMode, tlsConfig *tls.Config) EmailOption {
+	return func(e *EmailService) {
+		e.tlsMode = mode
+		e.tlsConfig = tlsConfig
+	}
+}
+
+// WithAuthMechanism selects the SMTP authentication mechanism. AuthNone disables authentication.
+func WithAuthMechanism(mechanism AuthMechanism) EmailOption {
+	return func(e *EmailService) {
+		e.authMechanism = mechanism
+	}
+}
+
+// WithRetry retries transient SMTP failures (timeouts, dropped connections and 4xx replies such as
+// 421, 450 and 451) up to maxRetries times, doubling the delay after each attempt starting from
+// backoff, up to a minute. Permanent 5xx failures and errors such as an unknown host or a failed TLS
+// handshake are returned immediately. A scheduled email is then tried again from backoff until it is
+// sent, waiting at most 15 minutes between attempts.
+func WithRetry(maxRetries int, backoff time.Duration) EmailOption {
+	return func(e *EmailService) {
+		if backoff <= 0 {
+			backoff = defaultRetryBackoff
+		}
+		e.maxRetries = maxRetries
+		e.retryBackoff = backoff
+	}
+}
+
+// WithTimeout limits every delivery attempt to timeout: connecting, and then each exchange with the
+// server, fail with a timeout error once it passes instead of waiting for the operating system to
+// give up on an unreachable host, which can take minutes. Over the shared connection of SendBulkEmail
+// the limit applies to each message. Attempts timing out count as transient failures for WithRetry.
+// It does not apply to a SendFunc given WithSender. A timeout of zero or less disables the limit; use
+// the Context variants of the send methods to bound a whole call including retries.
+//
+// Example usage:
+//
+//	service := NewEmailService("smtp.example.com", "587", "user", "password",
+//	    WithTimeout(10*time.Second))
+func WithTimeout(timeout time.Duration) EmailOption {
+	return func(e *EmailService) {
+		e.timeout = timeout
+	}
+}
+
+// WithAllowEmptyRecipients drops blank entries from the To, CC and BCC lists instead of rejecting
+// them as invalid addresses. It is meant for callers whose lists come from configuration, such as an
+// optional BCC setting split on commas. A send with no recipients at all is still rejected.
+func WithAllowEmptyRecipients(allow bool) EmailOption {
+	return func(e *EmailService) {
+		e.allowEmptyRecipients = allow
+	}
+}
+
+// WithFrom sets the sender shown to recipients, so the From header reads `"Acme Support" <noreply@acme.com>`
+// instead of the bare login. An empty address keeps the username as the sending address. Authentication
+// always uses the username and password given to the constructor, which matters for providers where the
+// login differs from the sending address.
+//
+// Example usage:
+//
+//	service := NewEmailService("smtp.sendgrid.net", "587", "apikey", key,
+//	    WithFrom("Acme Support", "noreply@acme.com"))
+func WithFrom(name, address string) EmailOption {
+	return func(e *EmailService) {
+		e.fromName = name
+		e.fromAddress = address
+	}
+}
+
+// WithReplyTo adds a Reply-To header to every email, so replies are routed to the given addresses
+// (for example a ticket system) rather than the sender. A Reply-To passed to SendEmailWithHeaders
+// takes precedence.
+//
+// Example usage:
+//
+//	service := NewEmailService("smtp.example.com", "587", "user", "password",
+//	    WithReplyTo("support@acme.com", "tickets@acme.com"))
+func WithReplyTo(addresses ...string) EmailOption {
+	return func(e *EmailService) {
+		e.replyTo = append([]string(nil), addresses...)
+	}
+}
+
+// WithScheduleStore persists emails passed to ScheduleEmail in store, so that ResumeScheduled can send
+// the ones still pending after the process restarts.
+func WithScheduleStore(store ScheduleStore) EmailOption {
+	return func(e *EmailService) {
+		e.scheduleStore = store
+	}
+}
+
+// WithDryRun hands every composed email to sink instead of connecting to the SMTP server, so tests can
+// assert on the full MIME output without a live server. Recipient validation still runs as usual.
+func WithDryRun(sink MessageSink) EmailOption {
+	return func(e *EmailService) {
+		e.sink = sink
+	}
+}
+
+// WithSender replaces the built-in SMTP transport with send, which is called with the server address,
+// the configured authentication and the envelope for every delivery attempt. Passing smtp.SendMail
+// uses the standard library client; tests can pass a fake that records what would have been sent.
+// TLS settings are not applied, since the connection is entirely up to send.
+//
+// Example usage:
+//
+//	var sent []byte
+//	service := NewEmailService("smtp.example.com", "587", "user", "password",
+//	    WithSender(func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+//	        sent = msg
+//	        return nil
+//	    }))
+func WithSender(send SendFunc) EmailOption {
+	return func(e *EmailService) {
+		e.sender = send
+	}
+}
+
+// WithMaxMessageBytes rejects messages larger than limit bytes with a *MessageTooLargeError instead
+// of letting the SMTP server refuse them after the upload. Providers commonly cap messages at 25MB.
+// Attachments are measured by their base64 encoded size, which is about a third larger than the files.
+// A limit of zero or less disables the check.
+func WithMaxMessageBytes(limit int64) EmailOption {
+	return func(e *EmailService) {
+		e.maxMessageBytes = limit
+	}
+}
+
+// WithMaxAttachments rejects emails with more than limit attachments, counting both files and
+// in-memory attachments but not inline images, with a *TooManyAttachmentsError before any file is read.
+// It guards against accidentally attaching a whole directory. A limit of zero or less disables the check.
+func WithMaxAttachments(limit int) EmailOption {
+	return func(e *EmailService) {
+		e.maxAttachments = limit
+	}
+}
+
+// WithBodyEncoding sets the Content-Transfer-Encoding of text and HTML bodies. Use
+// BodyEncodingQuotedPrintable or BodyEncodingBase64 when bodies contain non-ASCII text.
+// Subjects with non-ASCII characters are always RFC 2047 encoded.
+func WithBodyEncoding(encoding BodyEncoding) EmailOption {
+	return func(e *EmailService) {
+		e.bodyEncoding = encoding
+	}
+}
+
+// newEmailService builds an EmailService with the defaults applied before the given options.
+func newEmailService(smtpHost, smtpPort, username, password string, opts []EmailOption) *EmailService {
+	service := &EmailService{
+		smtpHost:      smtpHost,
+		smtpPort:      smtpPort,
+		username:      username,
+		password:      password,
+		authMechanism: AuthPlain,
+	}
+
+	for _, opt := range opts {
+		opt(service)
+	}
+	return service
+}
@@ -0,0 +1,385 @@
+package gophersmtp
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// maxScheduledRetryBackoff is the longest a scheduled email that keeps failing with transient errors
+// waits before its next attempt.
+const maxScheduledRetryBackoff = 15 * time.Minute
+
+// ScheduledEmail is a pending scheduled send as recorded in a ScheduleStore.
+//
+// Attachments and inline images are recorded by path and read when the email is sent. In-memory
+// attachments are recorded with their content in AttachmentData.
+type ScheduledEmail struct {
+	ID             string                `json:"id"`
+	To             []string              `json:"to"`
+	CC             []string              `json:"cc,omitempty"`
+	BCC            []string              `json:"bcc,omitempty"`
+	Subject        string                `json:"subject"`
+	Body           string                `json:"body"`
+	IsHtml         bool                  `json:"is_html"`
+	Attachments    []string              `json:"attachments,omitempty"`
+	AttachmentData []ScheduledAttachment `json:"attachment_data,omitempty"`
+	InlineImages   []string              `json:"inline_images,omitempty"`
+	Headers        map[string]string     `json:"headers,omitempty"`
+	ReplyTo        []string              `json:"reply_to,omitempty"`
+	EnvelopeFrom   string                `json:"envelope_from,omitempty"`
+	SendAt         time.Time             `json:"send_at"`
+}
+
+// newScheduledEmail records msg for the schedule store, reading its in-memory attachments.
+func newScheduledEmail(msg EmailMessage) (ScheduledEmail, error) {
+	attachmentData, err := readAttachments(msg.AttachmentData)
+	if err != nil {
+		return ScheduledEmail{}, err
+	}
+
+	return ScheduledEmail{
+		To:             msg.To,
+		CC:             msg.CC,
+		BCC:            msg.BCC,
+		Subject:        msg.Subject,
+		Body:           msg.Body,
+		IsHtml:         msg.IsHTML,
+		Attachments:    msg.Attachments,
+		AttachmentData: attachmentData,
+		InlineImages:   msg.InlineImages,
+		Headers:        msg.Headers,
+		ReplyTo:        msg.ReplyTo,
+		EnvelopeFrom:   msg.EnvelopeFrom,
+		SendAt:         msg.SendAt,
+	}, nil
+}
+
+// message returns the email to send once it is due.
+func (email ScheduledEmail) message() EmailMessage {
+	return EmailMessage{
+		To:             email.To,
+		CC:             email.CC,
+		BCC:            email.BCC,
+		Subject:        email.Subject,
+		Body:           email.Body,
+		IsHTML:         email.IsHtml,
+		Attachments:    email.Attachments,
+		AttachmentData: scheduledAttachments(email.AttachmentData),
+		InlineImages:   email.InlineImages,
+		Headers:        email.Headers,
+		ReplyTo:        email.ReplyTo,
+		EnvelopeFrom:   email.EnvelopeFrom,
+	}
+}
+
+// ScheduleStore persists scheduled emails so they survive a process restart.
+//
+// An email is saved when it is scheduled and deleted once it has been sent, has failed for good or is
+// dropped with CancelScheduled. A send failing with a transient error is retried in-process with a
+// growing delay. Emails whose context was cancelled, or that are still being retried when the process
+// stops, stay in the store and are replayed by ResumeScheduled when the process restarts.
+type ScheduleStore interface {
+	// Save records a pending scheduled email, replacing any entry with the same ID.
+	Save(email ScheduledEmail) error
+
+	// Delete removes the entry with the given ID. Deleting an unknown ID is not an error.
+	Delete(id string) error
+
+	// List returns every pending scheduled email.
+	List() ([]ScheduledEmail, error)
+}
+
+// FileScheduleStore is a ScheduleStore that keeps one JSON file per scheduled email in a directory.
+type FileScheduleStore struct {
+	dir string
+}
+
+// NewFileScheduleStore creates a FileScheduleStore under dir, creating the directory if needed.
+//
+// Params:
+//   - dir: The directory the scheduled emails are written to.
+//
+// Returns:
+//   - *FileScheduleStore: The store, ready to be passed to WithScheduleStore.
+//   - error: An error if the directory cannot be created.
+//
+// Example usage:
+//
+//	store, err := NewFileScheduleStore("/var/lib/myapp/scheduled-mail")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	service := NewEmailService("smtp.example.com", "587", "user", "password", WithScheduleStore(store))
+//	service.ResumeScheduled()
+func NewFileScheduleStore(dir string) (*FileScheduleStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create schedule store directory %s: %w", dir, err)
+	}
+	return &FileScheduleStore{dir: dir}, nil
+}
+
+// Save writes the email to its own file, replacing it atomically if it already exists.
+func (s *FileScheduleStore) Save(email ScheduledEmail) error {
+	path, err := s.path(email.ID)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(email)
+	if err != nil {
+		return fmt.Errorf("failed to encode scheduled email %s: %w", email.ID, err)
+	}
+
+	// Write to a temporary file first so a crash never leaves a truncated entry behind
+	tmp, err := os.CreateTemp(s.dir, ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to save scheduled email %s: %w", email.ID, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to save scheduled email %s: %w", email.ID, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to save scheduled email %s: %w", email.ID, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to save scheduled email %s: %w", email.ID, err)
+	}
+	return nil
+}
+
+// Delete removes the file of the email with the given ID.
+func (s *FileScheduleStore) Delete(id string) error {
+	path, err := s.path(id)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete scheduled email %s: %w", id, err)
+	}
+	return nil
+}
+
+// List reads every stored email, ordered by send time.
+func (s *FileScheduleStore) List() ([]ScheduledEmail, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schedule store directory %s: %w", s.dir, err)
+	}
+
+	var emails []ScheduledEmail
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(s.dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read scheduled email %s: %w", entry.Name(), err)
+		}
+		var email ScheduledEmail
+		if err := json.Unmarshal(data, &email); err != nil {
+			return nil, fmt.Errorf("failed to decode scheduled email %s: %w", entry.Name(), err)
+		}
+		emails = append(emails, email)
+	}
+
+	sort.Slice(emails, func(i, j int) bool {
+		return emails[i].SendAt.Before(emails[j].SendAt)
+	})
+	return emails, nil
+}
+
+// path returns the file of the email with the given ID, rejecting IDs that would escape the directory.
+func (s *FileScheduleStore) path(id string) (string, error) {
+	if id == "" || id != filepath.Base(id) || strings.HasPrefix(id, ".") {
+		return "", fmt.Errorf("invalid scheduled email ID %q", id)
+	}
+	return filepath.Join(s.dir, id+".json"), nil
+}
+
+// newScheduleID returns a random identifier for a scheduled email.
+func newScheduleID() (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", fmt.Errorf("failed to generate scheduled email ID: %w", err)
+	}
+	return hex.EncodeToString(id), nil
+}
+
+// persistScheduled assigns the email an ID and saves it when the service has a schedule store.
+func (e *EmailService) persistScheduled(email *ScheduledEmail) error {
+	if e.scheduleStore == nil {
+		return nil
+	}
+
+	id, err := newScheduleID()
+	if err != nil {
+		return err
+	}
+	email.ID = id
+
+	if err := e.scheduleStore.Save(*email); err != nil {
+		return fmt.Errorf("failed to persist scheduled email: %w", err)
+	}
+	return nil
+}
+
+// forgetScheduled removes the email from the schedule store, logging rather than failing since the
+// send itself has already been decided.
+func (e *EmailService) forgetScheduled(email ScheduledEmail) {
+	if e.scheduleStore == nil || email.ID == "" {
+		return
+	}
+	if err := e.scheduleStore.Delete(email.ID); err != nil {
+		log.Printf("Failed to remove scheduled email %s from the store: %v\n", email.ID, err)
+	}
+}
+
+// sendScheduled delivers a due scheduled email. The email is removed from the schedule store once it
+// was sent or failed for good, and kept there for ResumeScheduled when the send was interrupted by ctx
+// or failed with a transient error.
+func (e *EmailService) sendScheduled(ctx context.Context, email ScheduledEmail) error {
+	recipients, msg, err := e.composeMessage(email.message())
+	if err == nil {
+		err = e.sendMail(ctx, e.envelopeFrom(email.EnvelopeFrom, recipients), mergeRecipients(recipients, nil, nil), msg)
+	}
+	if err != nil && (ctx.Err() != nil || isTransientSMTPError(err)) {
+		return err
+	}
+	e.forgetScheduled(email)
+	return err
+}
+
+// deliverScheduled sends a due scheduled email, retrying transient failures in-process. The delay
+// between attempts starts at the WithRetry backoff and doubles up to maxScheduledRetryBackoff. Retrying
+// stops once the email is sent or fails for good, or when ctx ends or stop is closed; the email then
+// stays in the schedule store for ResumeScheduled.
+//
+// Returns:
+//   - error: nil once sent, otherwise the error of the last attempt.
+func (e *EmailService) deliverScheduled(ctx context.Context, email ScheduledEmail, stop <-chan struct{}) error {
+	delay := e.retryBackoff
+	if delay <= 0 {
+		delay = defaultRetryBackoff
+	}
+
+	for {
+		err := e.sendScheduled(ctx, email)
+		if err == nil || ctx.Err() != nil || !isTransientSMTPError(err) {
+			return err
+		}
+
+		log.Printf("Scheduled email to %s failed, retrying in %s: %v\n", strings.Join(email.To, ", "), delay, err)
+		if !waitUntil(ctx, time.Now().Add(delay), stop) {
+			return err
+		}
+		delay = min(delay*2, maxScheduledRetryBackoff)
+	}
+}
+
+// goScheduled sends the email in a Go routine once it is due, logging a failed send. An email whose
+// context is cancelled first stays in the schedule store, so that ResumeScheduled sends it after a
+// restart; only CancelScheduled drops it.
+func (e *EmailService) goScheduled(ctx context.Context, email ScheduledEmail) {
+	ctx, untrack := e.trackScheduled(ctx, email)
+	go func() {
+		defer untrack()
+		e.runScheduled(ctx, email)
+	}()
+}
+
+// runScheduled waits until the email is due and delivers it, logging a failed send.
+func (e *EmailService) runScheduled(ctx context.Context, email ScheduledEmail) {
+	if !waitUntil(ctx, email.SendAt, nil) {
+		return
+	}
+	if err := e.deliverScheduled(ctx, email, nil); err != nil {
+		log.Printf("Failed to send scheduled email to %s: %v\n", strings.Join(email.To, ", "), err)
+	}
+}
+
+// trackScheduled registers a scheduled email waiting in this process so that CancelScheduled can stop
+// it. It returns the context to wait and send with, and a function to call once the email is done.
+func (e *EmailService) trackScheduled(ctx context.Context, email ScheduledEmail) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	if email.ID == "" {
+		return ctx, cancel
+	}
+
+	e.scheduledMu.Lock()
+	if e.scheduledCancels == nil {
+		e.scheduledCancels = make(map[string]context.CancelFunc)
+	}
+	e.scheduledCancels[email.ID] = cancel
+	e.scheduledMu.Unlock()
+
+	return ctx, func() {
+		e.scheduledMu.Lock()
+		delete(e.scheduledCancels, email.ID)
+		e.scheduledMu.Unlock()
+		cancel()
+	}
+}
+
+// CancelScheduled drops the scheduled email with the given ID: it is removed from the schedule store
+// and, if it is waiting in this process, will not be sent. The IDs are those listed by the store.
+//
+// Params:
+//   - id: The ID of the scheduled email, as found in ScheduledEmail.ID.
+//
+// Returns:
+//   - error: An error if the service has no schedule store or the entry cannot be removed.
+//
+// Example usage:
+//
+//	pending, _ := store.List()
+//	for _, email := range pending {
+//	    if email.Subject == "Trial ending" {
+//	        service.CancelScheduled(email.ID)
+//	    }
+//	}
+func (e *EmailService) CancelScheduled(id string) error {
+	if e.scheduleStore == nil {
+		return fmt.Errorf("failed to cancel scheduled email %s: the service has no schedule store", id)
+	}
+	if err := e.scheduleStore.Delete(id); err != nil {
+		return err
+	}
+
+	e.scheduledMu.Lock()
+	cancel := e.scheduledCancels[id]
+	e.scheduledMu.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+	return nil
+}
+
+// waitUntil blocks until sendAt, returning false if ctx ends or stop is closed first.
+// A nil stop channel never fires.
+func waitUntil(ctx context.Context, sendAt time.Time, stop <-chan struct{}) bool {
+	timer := time.NewTimer(time.Until(sendAt))
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-stop:
+		return false
+	case <-timer.C:
+		return true
+	}
+}
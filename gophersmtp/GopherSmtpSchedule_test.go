@@ -0,0 +1,183 @@
+package gophersmtp
+
+import (
+	"context"
+	"net/smtp"
+	"net/textproto"
+	"sync"
+	"testing"
+	"time"
+)
+
+// waitFor polls cond until it holds, failing the test after a second.
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// pending returns the IDs of the emails left in store.
+func pending(t *testing.T, store ScheduleStore) []string {
+	t.Helper()
+	emails, err := store.List()
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	ids := make([]string, 0, len(emails))
+	for _, email := range emails {
+		ids = append(ids, email.ID)
+	}
+	return ids
+}
+
+// idle reports whether no scheduled email is waiting in service.
+func idle(service *EmailService) func() bool {
+	return func() bool {
+		service.scheduledMu.Lock()
+		defer service.scheduledMu.Unlock()
+		return len(service.scheduledCancels) == 0
+	}
+}
+
+func newScheduleTestService(t *testing.T, opts ...EmailOption) (*EmailService, *FileScheduleStore) {
+	t.Helper()
+	store, err := NewFileScheduleStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileScheduleStore failed: %v", err)
+	}
+	opts = append([]EmailOption{WithScheduleStore(store)}, opts...)
+	return newEmailService("smtp.example.com", "587", "sender@example.com", "password", opts), store
+}
+
+func TestScheduledEmailKeptWhenContextCancelled(t *testing.T) {
+	sink := NewMemorySink()
+	service, store := newScheduleTestService(t, WithDryRun(sink))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	msg := EmailMessage{To: []string{"a@example.com"}, Subject: "Later", Body: "Hi", SendAt: time.Now().Add(time.Hour)}
+	if err := service.Send(ctx, msg); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	cancel()
+	waitFor(t, "the scheduled send to stop", idle(service))
+
+	if ids := pending(t, store); len(ids) != 1 {
+		t.Fatalf("store holds %d emails after cancelling ctx, want 1", len(ids))
+	}
+	if n := len(sink.Messages()); n != 0 {
+		t.Fatalf("%d emails sent after cancelling ctx, want 0", n)
+	}
+}
+
+func TestCancelScheduledDropsEmail(t *testing.T) {
+	sink := NewMemorySink()
+	service, store := newScheduleTestService(t, WithDryRun(sink))
+
+	msg := EmailMessage{To: []string{"a@example.com"}, Subject: "Later", Body: "Hi", SendAt: time.Now().Add(50 * time.Millisecond)}
+	if err := service.Send(context.Background(), msg); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	ids := pending(t, store)
+	if len(ids) != 1 {
+		t.Fatalf("store holds %d emails, want 1", len(ids))
+	}
+	if err := service.CancelScheduled(ids[0]); err != nil {
+		t.Fatalf("CancelScheduled failed: %v", err)
+	}
+	waitFor(t, "the scheduled send to stop", idle(service))
+	time.Sleep(100 * time.Millisecond)
+
+	if ids := pending(t, store); len(ids) != 0 {
+		t.Fatalf("store holds %d emails after CancelScheduled, want 0", len(ids))
+	}
+	if n := len(sink.Messages()); n != 0 {
+		t.Fatalf("%d emails sent after CancelScheduled, want 0", n)
+	}
+}
+
+func TestCancelScheduledWithoutStore(t *testing.T) {
+	service := newEmailService("smtp.example.com", "587", "sender@example.com", "password", nil)
+	if err := service.CancelScheduled("abc"); err == nil {
+		t.Fatal("CancelScheduled without a schedule store succeeded, want an error")
+	}
+}
+
+func TestScheduledEmailStoreAfterSend(t *testing.T) {
+	greylisted := &textproto.Error{Code: 451, Msg: "try again later"}
+	tests := []struct {
+		name      string
+		errs      []error
+		wantSends int
+	}{
+		{"delivered", []error{nil}, 1},
+		{"greylisted then delivered", []error{greylisted, greylisted, nil}, 3},
+		{"rejected", []error{&textproto.Error{Code: 550, Msg: "no such user"}}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			sends := 0
+			service, store := newScheduleTestService(t, WithRetry(0, time.Millisecond), WithSender(func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+				mu.Lock()
+				defer mu.Unlock()
+				sends++
+				return tt.errs[min(sends, len(tt.errs))-1]
+			}))
+
+			msg := EmailMessage{To: []string{"a@example.com"}, Subject: "Soon", Body: "Hi", SendAt: time.Now().Add(10 * time.Millisecond)}
+			if err := service.Send(context.Background(), msg); err != nil {
+				t.Fatalf("Send failed: %v", err)
+			}
+			waitFor(t, "the scheduled send to finish", func() bool {
+				mu.Lock()
+				defer mu.Unlock()
+				return sends > 0 && idle(service)()
+			})
+
+			mu.Lock()
+			defer mu.Unlock()
+			if sends != tt.wantSends {
+				t.Errorf("sender called %d times, want %d", sends, tt.wantSends)
+			}
+			if ids := pending(t, store); len(ids) != 0 {
+				t.Fatalf("store holds %d emails, want 0", len(ids))
+			}
+		})
+	}
+}
+
+func TestScheduledEmailRetriedUntilCancelled(t *testing.T) {
+	attempts := make(chan struct{}, 100)
+	service, store := newScheduleTestService(t, WithRetry(0, time.Millisecond), WithSender(func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		attempts <- struct{}{}
+		return &textproto.Error{Code: 421, Msg: "service not available"}
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	msg := EmailMessage{To: []string{"a@example.com"}, Subject: "Soon", Body: "Hi", SendAt: time.Now().Add(10 * time.Millisecond)}
+	if err := service.Send(ctx, msg); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		select {
+		case <-attempts:
+		case <-time.After(time.Second):
+			t.Fatalf("scheduled email was tried %d times, want it retried in-process", i)
+		}
+	}
+	if ids := pending(t, store); len(ids) != 1 {
+		t.Fatalf("store holds %d emails while retrying, want 1", len(ids))
+	}
+
+	cancel()
+	waitFor(t, "the retries to stop", idle(service))
+	if ids := pending(t, store); len(ids) != 1 {
+		t.Fatalf("store holds %d emails after cancelling ctx, want 1 for ResumeScheduled", len(ids))
+	}
+}
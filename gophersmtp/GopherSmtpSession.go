@@ -0,0 +1,151 @@
+package gophersmtp
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"time"
+)
+
+// smtpSession keeps one authenticated SMTP connection open so that several messages can be delivered
+// over it, paying for the dial, TLS negotiation and authentication only once.
+//
+// A session is not safe for concurrent use.
+type smtpSession struct {
+	service *EmailService
+	ctx     context.Context
+	conn    net.Conn
+	client  *smtp.Client
+	stop    func() bool
+}
+
+// newSession creates a session that connects lazily on the first delivery. Cancelling ctx aborts the
+// exchange in progress and every later one.
+func (e *EmailService) newSession(ctx context.Context) *smtpSession {
+	return &smtpSession{service: e, ctx: ctx}
+}
+
+// connect opens and authenticates the connection if the session has none.
+func (s *smtpSession) connect() error {
+	if s.client != nil {
+		return nil
+	}
+
+	conn, err := s.service.dial(s.ctx)
+	if err != nil {
+		if s.ctx.Err() != nil {
+			return s.ctx.Err()
+		}
+		return err
+	}
+
+	// Interrupt any blocking read or write on the connection once the context ends.
+	stop := context.AfterFunc(s.ctx, func() {
+		conn.SetDeadline(time.Now())
+	})
+	s.service.armDeadline(s.ctx, conn)
+
+	client, err := s.service.handshake(conn)
+	if err != nil {
+		stop()
+		if s.ctx.Err() != nil {
+			return s.ctx.Err()
+		}
+		return err
+	}
+
+	s.conn, s.client, s.stop = conn, client, stop
+	return nil
+}
+
+// send delivers one message, reconnecting once if the connection turns out to have been dropped, e.g.
+// by a server closing idle or long-lived connections. Transient replies are retried as configured
+// WithRetry, on the same connection.
+//
+// Returns:
+//   - error: ctx.Err() if the context ended first, otherwise the error of the last attempt.
+func (s *smtpSession) send(from string, to []string, msg []byte) error {
+	delay := s.service.retryBackoff
+	reconnected := false
+	for attempt := 0; ; {
+		err := s.sendOnce(from, to, msg)
+		if err == nil {
+			return nil
+		}
+		if s.ctx.Err() != nil {
+			return s.ctx.Err()
+		}
+
+		// A dropped connection is replaced right away, without using up a retry
+		if s.client == nil && !reconnected && isDroppedConnection(err) {
+			reconnected = true
+			continue
+		}
+		if attempt >= s.service.maxRetries || !isTransientSMTPError(err) {
+			return err
+		}
+		attempt++
+
+		// Wait before the next attempt, giving up early if the context ends
+		select {
+		case <-s.ctx.Done():
+			return s.ctx.Err()
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, maxRetryBackoff)
+	}
+}
+
+// sendOnce makes a single delivery attempt. After a rejected message the transaction is reset so the
+// connection can carry the next one; a connection that failed otherwise is closed.
+func (s *smtpSession) sendOnce(from string, to []string, msg []byte) error {
+	if err := s.connect(); err != nil {
+		return err
+	}
+
+	s.service.armDeadline(s.ctx, s.conn)
+	err := sendMessage(s.client, from, to, msg)
+	if err == nil {
+		return nil
+	}
+
+	var protoErr *textproto.Error
+	if errors.As(err, &protoErr) && s.client.Reset() == nil {
+		return err
+	}
+	s.drop()
+	return err
+}
+
+// drop closes the connection without saying goodbye, so the next delivery reconnects.
+func (s *smtpSession) drop() {
+	if s.client == nil {
+		return
+	}
+	s.stop()
+	s.client.Close()
+	s.conn, s.client, s.stop = nil, nil, nil
+}
+
+// Close ends the session with QUIT and closes the connection.
+func (s *smtpSession) Close() error {
+	if s.client == nil {
+		return nil
+	}
+	s.service.armDeadline(s.ctx, s.conn)
+	err := s.client.Quit()
+	s.drop()
+	return err
+}
+
+// isDroppedConnection reports whether err means the connection was lost, as opposed to the server
+// rejecting the message with a reply.
+func isDroppedConnection(err error) bool {
+	var protoErr *textproto.Error
+	if errors.As(err, &protoErr) {
+		return false
+	}
+	return isTransientSMTPError(err)
+}
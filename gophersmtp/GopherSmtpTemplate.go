@@ -0,0 +1,47 @@
+package gophersmtp
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+)
+
+// ParseTemplateFiles parses the given HTML template files for use with SendTemplateEmail.
+//
+// Params:
+//   - paths: One or more template file paths. The first file's name becomes the template name.
+//
+// Returns:
+//   - *template.Template: The parsed template.
+//   - error: An error if no paths are given or a file cannot be parsed.
+//
+// Example usage:
+//
+//	tmpl, err := ParseTemplateFiles("templates/welcome.html", "templates/footer.html")
+//	if err != nil {
+//	    log.Fatalf("Failed to parse email templates: %v", err)
+//	}
+func ParseTemplateFiles(paths ...string) (*template.Template, error) {
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no template files given")
+	}
+
+	tmpl, err := template.ParseFiles(paths...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse email templates: %w", err)
+	}
+	return tmpl, nil
+}
+
+// renderTemplate executes tmpl with data and returns the rendered HTML.
+func renderTemplate(tmpl *template.Template, data any) (string, error) {
+	if tmpl == nil {
+		return "", fmt.Errorf("email template is nil")
+	}
+
+	var buffer bytes.Buffer
+	if err := tmpl.Execute(&buffer, data); err != nil {
+		return "", fmt.Errorf("failed to render email template %s: %w", tmpl.Name(), err)
+	}
+	return buffer.String(), nil
+}
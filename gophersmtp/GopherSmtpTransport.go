@@ -0,0 +1,296 @@
+package gophersmtp
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// TLSMode controls how the connection to the SMTP server is secured.
+type TLSMode string
+
+const (
+	// TLSModeNone does not require TLS. STARTTLS is still used when the server advertises it,
+	// matching the behaviour of net/smtp.SendMail.
+	TLSModeNone TLSMode = ""
+	// TLSModeStartTLS connects in plain text and requires the server to upgrade via STARTTLS (usually port 587).
+	TLSModeStartTLS TLSMode = "starttls"
+	// TLSModeImplicit negotiates TLS immediately after connecting (usually port 465).
+	TLSModeImplicit TLSMode = "implicit"
+)
+
+// SendFunc delivers one message, with the same signature as net/smtp.SendMail.
+//
+// It is the seam used by WithSender, letting tests swap in a fake that records the exact bytes and
+// recipients instead of talking to a server.
+type SendFunc func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+
+// clientTLSConfig returns the TLS configuration used for STARTTLS or implicit TLS.
+//
+// When no configuration was supplied, the server certificate is verified against smtpHost.
+// A supplied configuration without a ServerName gets smtpHost filled in on a copy.
+func (e *EmailService) clientTLSConfig() *tls.Config {
+	if e.tlsConfig == nil {
+		return &tls.Config{ServerName: e.smtpHost}
+	}
+
+	tlsConfig := e.tlsConfig.Clone()
+	if tlsConfig.ServerName == "" {
+		tlsConfig.ServerName = e.smtpHost
+	}
+	return tlsConfig
+}
+
+// sendMail delivers msg to the recipients, securing the connection according to the service's TLS mode.
+//
+// The dial honours ctx, and cancelling ctx mid-exchange closes the connection so the send returns promptly.
+// Transient failures are retried with exponential backoff when the service was configured WithRetry.
+// A service configured WithDKIM signs the message first. A service configured WithDryRun hands the
+// message to its sink instead.
+//
+// Params:
+//   - ctx: Context controlling cancellation and deadline of the whole SMTP exchange.
+//   - from: The envelope sender address.
+//   - to: The envelope recipient addresses.
+//   - msg: The fully composed message, headers included.
+//
+// Returns:
+//   - error: ctx.Err() if the context ended first, otherwise an error if connecting, negotiating TLS,
+//     authenticating or delivering fails.
+func (e *EmailService) sendMail(ctx context.Context, from string, to []string, msg []byte) error {
+	if err := validateEnvelope(from, to); err != nil {
+		return err
+	}
+	msg, err := e.signDKIM(msg)
+	if err != nil {
+		return err
+	}
+	if e.maxMessageBytes > 0 && int64(len(msg)) > e.maxMessageBytes {
+		return &MessageTooLargeError{Size: int64(len(msg)), Limit: e.maxMessageBytes}
+	}
+
+	// In dry-run mode the message goes to the sink and the network is never touched
+	if e.sink != nil {
+		return e.sink.Capture(CapturedMessage{
+			From: from,
+			To:   append([]string(nil), to...),
+			Data: append([]byte(nil), msg...),
+		})
+	}
+
+	delay := e.retryBackoff
+	for attempt := 0; ; attempt++ {
+		err := e.sendOnce(ctx, from, to, msg)
+		if err == nil || attempt >= e.maxRetries || ctx.Err() != nil || !isTransientSMTPError(err) {
+			return err
+		}
+
+		// Wait before the next attempt, giving up early if the context ends
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, maxRetryBackoff)
+	}
+}
+
+// sendOnce makes a single delivery attempt, over a new connection or through the configured SendFunc.
+func (e *EmailService) sendOnce(ctx context.Context, from string, to []string, msg []byte) error {
+	if e.sender != nil {
+		return e.sendWithSender(ctx, from, to, msg)
+	}
+
+	conn, err := e.dial(ctx)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return err
+	}
+
+	// Interrupt any blocking read or write on the connection once the context ends.
+	stop := context.AfterFunc(ctx, func() {
+		conn.SetDeadline(time.Now())
+	})
+	defer stop()
+	e.armDeadline(ctx, conn)
+
+	if err := e.deliver(conn, from, to, msg); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return err
+	}
+	return nil
+}
+
+// sendWithSender hands the message to the SendFunc given WithSender. The function cannot be interrupted,
+// so ctx is only checked before and after the call.
+func (e *EmailService) sendWithSender(ctx context.Context, from string, to []string, msg []byte) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	auth, err := e.auth()
+	if err != nil {
+		return err
+	}
+	if err := e.sender(net.JoinHostPort(e.smtpHost, e.smtpPort), auth, from, to, msg); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return err
+	}
+	return nil
+}
+
+// dial opens the connection to the SMTP server, negotiating TLS up front in implicit mode.
+func (e *EmailService) dial(ctx context.Context) (net.Conn, error) {
+	addr := net.JoinHostPort(e.smtpHost, e.smtpPort)
+	dialer := &net.Dialer{Timeout: e.timeout}
+
+	var conn net.Conn
+	var err error
+	if e.tlsMode == TLSModeImplicit {
+		tlsDialer := &tls.Dialer{NetDialer: dialer, Config: e.clientTLSConfig()}
+		conn, err = tlsDialer.DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to SMTP server %s: %w", addr, err)
+	}
+	return conn, nil
+}
+
+// armDeadline limits the next exchange on conn to the timeout set WithTimeout. Once ctx has ended the
+// deadline is left in the past, as the context has already interrupted the connection.
+func (e *EmailService) armDeadline(ctx context.Context, conn net.Conn) {
+	if e.timeout <= 0 {
+		return
+	}
+	conn.SetDeadline(time.Now().Add(e.timeout))
+	if ctx.Err() != nil {
+		conn.SetDeadline(time.Now())
+	}
+}
+
+// deliver runs the SMTP exchange for one message over an established connection and closes it.
+func (e *EmailService) deliver(conn net.Conn, from string, to []string, msg []byte) error {
+	client, err := e.handshake(conn)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if err := sendMessage(client, from, to, msg); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// handshake greets the server over an established connection, upgrades it to TLS when possible or
+// required, and authenticates unless the mechanism is AuthNone. A server that does not advertise AUTH
+// is an error then, as with smtp.SendMail. The connection is closed if any step fails.
+func (e *EmailService) handshake(conn net.Conn) (*smtp.Client, error) {
+	addr := conn.RemoteAddr().String()
+
+	client, err := smtp.NewClient(conn, e.smtpHost)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to create SMTP client for %s: %w", addr, err)
+	}
+
+	// Upgrade plain connections when the server supports it, or when the mode requires it.
+	if e.tlsMode != TLSModeImplicit {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(e.clientTLSConfig()); err != nil {
+				client.Close()
+				return nil, fmt.Errorf("failed to negotiate STARTTLS with %s: %w", addr, err)
+			}
+		} else if e.tlsMode == TLSModeStartTLS {
+			client.Close()
+			return nil, fmt.Errorf("SMTP server %s does not support STARTTLS", addr)
+		}
+	}
+
+	auth, err := e.auth()
+	if err != nil {
+		client.Close()
+		return nil, err
+	}
+	if auth != nil {
+		// Like smtp.SendMail, refuse to send without the authentication the service was configured for
+		if ok, _ := client.Extension("AUTH"); !ok {
+			client.Close()
+			return nil, fmt.Errorf("SMTP server %s does not support AUTH", addr)
+		}
+		if err := client.Auth(auth); err != nil {
+			client.Close()
+			return nil, fmt.Errorf("SMTP authentication failed: %w", err)
+		}
+	}
+	return client, nil
+}
+
+// sendMessage issues the MAIL, RCPT and DATA commands for one message on an authenticated client.
+func sendMessage(client *smtp.Client, from string, to []string, msg []byte) error {
+	if err := client.Mail(from); err != nil {
+		return err
+	}
+	for _, recipient := range to {
+		if err := client.Rcpt(recipient); err != nil {
+			return err
+		}
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := writer.Write(msg); err != nil {
+		return err
+	}
+	return writer.Close()
+}
+
+// validateEnvelope rejects envelope addresses containing line breaks, which could be used to inject SMTP commands.
+func validateEnvelope(from string, to []string) error {
+	if strings.ContainsAny(from, "\r\n") {
+		return fmt.Errorf("sender address contains a line break")
+	}
+	for _, recipient := range to {
+		if strings.ContainsAny(recipient, "\r\n") {
+			return fmt.Errorf("recipient address %q contains a line break", recipient)
+		}
+	}
+	return nil
+}
+
+// isTransientSMTPError reports whether a failed send is worth retrying.
+//
+// SMTP 4xx replies (such as 421, 450 and 451 greylisting), timeouts and connections reset or closed
+// by the server are transient. 5xx replies and every other error, such as an unknown host, a refused
+// connection or a failed TLS handshake, are permanent, as retrying would not change the outcome.
+func isTransientSMTPError(err error) bool {
+	var protoErr *textproto.Error
+	if errors.As(err, &protoErr) {
+		return protoErr.Code >= 400 && protoErr.Code < 500
+	}
+
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, syscall.ECONNRESET) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
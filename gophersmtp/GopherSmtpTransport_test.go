@@ -0,0 +1,183 @@
+package gophersmtp
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"slices"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestIsTransientSMTPError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"421 service unavailable", &textproto.Error{Code: 421, Msg: "closing connection"}, true},
+		{"450 mailbox busy", &textproto.Error{Code: 450, Msg: "try again"}, true},
+		{"451 greylisted", fmt.Errorf("send failed: %w", &textproto.Error{Code: 451, Msg: "greylisted"}), true},
+		{"550 no such user", &textproto.Error{Code: 550, Msg: "no such user"}, false},
+		{"EOF", io.EOF, true},
+		{"unexpected EOF", io.ErrUnexpectedEOF, true},
+		{"connection reset", &net.OpError{Op: "read", Net: "tcp", Err: os.NewSyscallError("read", syscall.ECONNRESET)}, true},
+		{"dial timeout", &net.OpError{Op: "dial", Net: "tcp", Err: os.ErrDeadlineExceeded}, true},
+		{"DNS timeout", &net.DNSError{Err: "i/o timeout", Name: "smtp.example.com", IsTimeout: true}, true},
+		{"no such host", fmt.Errorf("failed to connect: %w", &net.OpError{Op: "dial", Net: "tcp", Err: &net.DNSError{Err: "no such host", Name: "smtp.example.invalid", IsNotFound: true}}), false},
+		{"connection refused", &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}, false},
+		{"TLS handshake", &net.OpError{Op: "remote error", Err: tls.AlertError(40)}, false},
+		{"local error", errors.New("recipient address contains a line break"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isTransientSMTPError(tt.err); got != tt.want {
+				t.Errorf("isTransientSMTPError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSendMailRetries(t *testing.T) {
+	tests := []struct {
+		name         string
+		err          error
+		wantAttempts int
+	}{
+		{"greylisted", &textproto.Error{Code: 451, Msg: "greylisted"}, 3},
+		{"rejected", &textproto.Error{Code: 550, Msg: "no such user"}, 1},
+		{"no such host", &net.DNSError{Err: "no such host", Name: "smtp.example.invalid", IsNotFound: true}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := 0
+			service := newEmailService("smtp.example.com", "587", "sender@example.com", "password", []EmailOption{
+				WithRetry(2, time.Millisecond),
+				WithSender(func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+					attempts++
+					return tt.err
+				}),
+			})
+
+			err := service.SendEmailContext(context.Background(), []string{"a@example.com"}, "Hi", "Hello", false)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("SendEmail returned %v, want %v", err, tt.err)
+			}
+			if attempts != tt.wantAttempts {
+				t.Errorf("made %d attempts, want %d", attempts, tt.wantAttempts)
+			}
+		})
+	}
+}
+
+func TestSendMailTLSModes(t *testing.T) {
+	serverTLS, clientTLS := newTestTLSConfigs(t)
+
+	tests := []struct {
+		name         string
+		server       fakeSMTPConfig
+		mode         TLSMode
+		wantStartTLS bool
+		wantSecure   bool
+	}{
+		{"none against a plain server", fakeSMTPConfig{}, TLSModeNone, false, false},
+		{"none upgrades when STARTTLS is advertised", fakeSMTPConfig{tlsConfig: serverTLS}, TLSModeNone, true, true},
+		{"starttls", fakeSMTPConfig{tlsConfig: serverTLS}, TLSModeStartTLS, true, true},
+		{"implicit", fakeSMTPConfig{tlsConfig: serverTLS, implicitTLS: true}, TLSModeImplicit, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := startFakeSMTPServer(t, tt.server)
+			service := server.service(WithTLS(tt.mode, clientTLS), WithAuthMechanism(AuthNone))
+
+			if err := service.SendEmail([]string{"a@example.com"}, "Hi", "Hello", false); err != nil {
+				t.Fatalf("SendEmail failed: %v", err)
+			}
+			secure := server.Secure()
+			if len(secure) != 1 || secure[0] != tt.wantSecure {
+				t.Errorf("messages delivered over TLS: %v, want [%v]", secure, tt.wantSecure)
+			}
+			if got := slices.Contains(server.Commands(), "STARTTLS"); got != tt.wantStartTLS {
+				t.Errorf("STARTTLS issued: %v, want %v (commands %v)", got, tt.wantStartTLS, server.Commands())
+			}
+		})
+	}
+}
+
+func TestSendMailStartTLSRefused(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	service := server.service(WithTLS(TLSModeStartTLS, nil), WithAuthMechanism(AuthNone))
+
+	err := service.SendEmail([]string{"a@example.com"}, "Hi", "Hello", false)
+	if err == nil || !strings.Contains(err.Error(), "does not support STARTTLS") {
+		t.Fatalf("SendEmail returned %v, want an error about STARTTLS", err)
+	}
+	if commands := server.Commands(); slices.Contains(commands, "MAIL") {
+		t.Errorf("message sent in plain text after STARTTLS was refused: commands %v", commands)
+	}
+}
+
+func TestSendMailAuthMechanisms(t *testing.T) {
+	tests := []struct {
+		mechanism AuthMechanism
+		wantAuth  string
+	}{
+		{AuthPlain, "AUTH PLAIN"},
+		{AuthLogin, "AUTH LOGIN"},
+		{AuthCRAMMD5, "AUTH CRAM-MD5"},
+		{AuthNone, ""},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.mechanism), func(t *testing.T) {
+			server := newFakeSMTPServer(t)
+			service := server.service(WithAuthMechanism(tt.mechanism))
+
+			if err := service.SendEmail([]string{"a@example.com"}, "Hi", "Hello", false); err != nil {
+				t.Fatalf("SendEmail failed: %v", err)
+			}
+			want := []string{"EHLO", "MAIL", "RCPT", "DATA", "QUIT"}
+			if tt.wantAuth != "" {
+				want = slices.Insert(want, 1, tt.wantAuth)
+			}
+			if got := server.Commands(); !slices.Equal(got, want) {
+				t.Errorf("commands %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestSendMailAuthFailures(t *testing.T) {
+	t.Run("wrong password", func(t *testing.T) {
+		server := newFakeSMTPServer(t)
+		host, port, _ := net.SplitHostPort(server.listener.Addr().String())
+		service := newEmailService(host, port, fakeUsername, "wrong", nil)
+
+		err := service.SendEmail([]string{"a@example.com"}, "Hi", "Hello", false)
+		if err == nil || !strings.Contains(err.Error(), "authentication failed") {
+			t.Fatalf("SendEmail returned %v, want an authentication error", err)
+		}
+		if len(server.Messages()) != 0 {
+			t.Error("message delivered without authenticating")
+		}
+	})
+
+	t.Run("AUTH not advertised", func(t *testing.T) {
+		server := startFakeSMTPServer(t, fakeSMTPConfig{})
+
+		err := server.service().SendEmail([]string{"a@example.com"}, "Hi", "Hello", false)
+		if err == nil || !strings.Contains(err.Error(), "does not support AUTH") {
+			t.Fatalf("SendEmail returned %v, want an error about AUTH", err)
+		}
+		if commands := server.Commands(); slices.Contains(commands, "MAIL") {
+			t.Errorf("message sent without the configured authentication: commands %v", commands)
+		}
+	})
+}
@@ -0,0 +1,60 @@
+package gophersmtp
+
+import (
+	"net/mail"
+	"strings"
+)
+
+// checkRecipients validates every address in the To, CC and BCC lists before anything is sent, so a
+// malformed address is reported up front instead of as an SMTP error halfway through delivery.
+//
+// Blank entries, such as those produced by splitting an unset BCC setting, are dropped when the
+// service was configured WithAllowEmptyRecipients and reported as invalid otherwise.
+//
+// Params:
+//   - to: The To recipient addresses.
+//   - cc: The CC recipient addresses.
+//   - bcc: The BCC recipient addresses.
+//
+// Returns:
+//   - []string: The To, CC and BCC lists with blank entries removed.
+//   - error: An *InvalidRecipientsError listing every malformed address, or ErrNoRecipients if no
+//     recipient is left to send to.
+func (e *EmailService) checkRecipients(to, cc, bcc []string) ([]string, []string, []string, error) {
+	var invalid []string
+	clean := func(addresses []string) []string {
+		cleaned := make([]string, 0, len(addresses))
+		for _, address := range addresses {
+			if strings.TrimSpace(address) == "" {
+				if !e.allowEmptyRecipients {
+					invalid = append(invalid, address)
+				}
+				continue
+			}
+			if _, err := mail.ParseAddress(address); err != nil {
+				invalid = append(invalid, address)
+				continue
+			}
+			cleaned = append(cleaned, address)
+		}
+		return cleaned
+	}
+
+	to, cc, bcc = clean(to), clean(cc), clean(bcc)
+	if len(invalid) > 0 {
+		return nil, nil, nil, &InvalidRecipientsError{Addresses: invalid}
+	}
+	if len(to)+len(cc)+len(bcc) == 0 {
+		return nil, nil, nil, ErrNoRecipients
+	}
+	return to, cc, bcc, nil
+}
+
+// envelopeAddress returns the bare address of a validated recipient, so that an entry such as
+// `Ada Lovelace <ada@example.com>` becomes `ada@example.com` in the SMTP envelope.
+func envelopeAddress(address string) string {
+	if parsed, err := mail.ParseAddress(address); err == nil {
+		return parsed.Address
+	}
+	return address
+}
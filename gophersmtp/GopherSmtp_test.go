@@ -0,0 +1,565 @@
+package gophersmtp
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/hex"
+	"io"
+	"math/big"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeSMTPConfig selects the extensions a fakeSMTPServer offers.
+type fakeSMTPConfig struct {
+	// tlsConfig, when set, makes the server advertise STARTTLS and upgrade with it.
+	tlsConfig *tls.Config
+	// implicitTLS makes the server negotiate TLS with tlsConfig as soon as a client connects.
+	implicitTLS bool
+	// authMechanisms are advertised with AUTH and accept fakeUsername and fakePassword.
+	authMechanisms []string
+	// dropAfter, when positive, closes a connection without a word once it has carried that many messages.
+	dropAfter int
+}
+
+// Credentials accepted by a fakeSMTPServer, which its service method configures.
+const (
+	fakeUsername = "sender@example.com"
+	fakePassword = "password"
+)
+
+// fakeSMTPServer accepts SMTP connections on localhost and records every command and every message
+// delivered to it.
+type fakeSMTPServer struct {
+	listener net.Listener
+	config   fakeSMTPConfig
+
+	mu          sync.Mutex
+	messages    []CapturedMessage
+	commands    []string
+	secure      []bool
+	connections int
+}
+
+// newFakeSMTPServer starts a fakeSMTPServer without TLS that accepts every supported AUTH mechanism
+// and is stopped when the test ends.
+func newFakeSMTPServer(t *testing.T) *fakeSMTPServer {
+	t.Helper()
+	return startFakeSMTPServer(t, fakeSMTPConfig{authMechanisms: []string{"PLAIN", "LOGIN", "CRAM-MD5"}})
+}
+
+// startFakeSMTPServer starts a fakeSMTPServer with the given extensions that is stopped when the test ends.
+func startFakeSMTPServer(t *testing.T, config fakeSMTPConfig) *fakeSMTPServer {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	if config.implicitTLS {
+		listener = tls.NewListener(listener, config.tlsConfig)
+	}
+	server := &fakeSMTPServer{listener: listener, config: config}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			server.mu.Lock()
+			server.connections++
+			server.mu.Unlock()
+			go server.serve(conn)
+		}
+	}()
+	return server
+}
+
+// service returns an EmailService that delivers to the server.
+func (s *fakeSMTPServer) service(opts ...EmailOption) *EmailService {
+	host, port, _ := net.SplitHostPort(s.listener.Addr().String())
+	return newEmailService(host, port, fakeUsername, fakePassword, opts)
+}
+
+// Messages returns the messages delivered so far.
+func (s *fakeSMTPServer) Messages() []CapturedMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]CapturedMessage(nil), s.messages...)
+}
+
+// Commands returns the verbs of the commands received so far, e.g. "EHLO" or "STARTTLS", with the
+// mechanism for AUTH, e.g. "AUTH PLAIN".
+func (s *fakeSMTPServer) Commands() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.commands...)
+}
+
+// Connections returns the number of connections accepted so far.
+func (s *fakeSMTPServer) Connections() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.connections
+}
+
+// Secure reports, for each message delivered so far, whether it arrived over TLS.
+func (s *fakeSMTPServer) Secure() []bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]bool(nil), s.secure...)
+}
+
+// serve runs the SMTP exchange of one connection.
+func (s *fakeSMTPServer) serve(conn net.Conn) {
+	defer func() { conn.Close() }()
+	text := textproto.NewConn(conn)
+	_, secure := conn.(*tls.Conn)
+
+	var current CapturedMessage
+	delivered := 0
+	text.PrintfLine("220 localhost ESMTP fake")
+	for {
+		line, err := text.ReadLine()
+		if err != nil {
+			return
+		}
+		command, arg, _ := strings.Cut(line, " ")
+		command = strings.ToUpper(command)
+		recorded := command
+		if command == "AUTH" {
+			mechanism, _, _ := strings.Cut(arg, " ")
+			recorded += " " + strings.ToUpper(mechanism)
+		}
+		s.mu.Lock()
+		s.commands = append(s.commands, recorded)
+		s.mu.Unlock()
+
+		switch command {
+		case "EHLO", "HELO":
+			extensions := []string{"localhost"}
+			if s.config.tlsConfig != nil && !secure {
+				extensions = append(extensions, "STARTTLS")
+			}
+			if len(s.config.authMechanisms) > 0 {
+				extensions = append(extensions, "AUTH "+strings.Join(s.config.authMechanisms, " "))
+			}
+			for i, extension := range extensions {
+				separator := "-"
+				if i == len(extensions)-1 {
+					separator = " "
+				}
+				text.PrintfLine("250%s%s", separator, extension)
+			}
+		case "AUTH":
+			mechanism, initial, _ := strings.Cut(arg, " ")
+			switch {
+			case !slices.Contains(s.config.authMechanisms, strings.ToUpper(mechanism)):
+				text.PrintfLine("504 Unrecognized authentication type")
+			case s.authenticate(text, strings.ToUpper(mechanism), initial):
+				text.PrintfLine("235 Authentication successful")
+			default:
+				text.PrintfLine("535 Authentication failed")
+			}
+		case "STARTTLS":
+			if s.config.tlsConfig == nil || secure {
+				text.PrintfLine("502 Command not implemented")
+				continue
+			}
+			text.PrintfLine("220 Ready to start TLS")
+			tlsConn := tls.Server(conn, s.config.tlsConfig)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			conn, text, secure = tlsConn, textproto.NewConn(tlsConn), true
+		case "MAIL":
+			current = CapturedMessage{From: strings.Trim(strings.TrimPrefix(arg, "FROM:"), "<>")}
+			text.PrintfLine("250 OK")
+		case "RCPT":
+			current.To = append(current.To, strings.Trim(strings.TrimPrefix(arg, "TO:"), "<>"))
+			text.PrintfLine("250 OK")
+		case "DATA":
+			text.PrintfLine("354 Go ahead")
+			data, err := text.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			current.Data = data
+			s.mu.Lock()
+			s.messages = append(s.messages, current)
+			s.secure = append(s.secure, secure)
+			s.mu.Unlock()
+			text.PrintfLine("250 OK")
+
+			delivered++
+			if s.config.dropAfter > 0 && delivered == s.config.dropAfter {
+				return
+			}
+		case "RSET", "NOOP":
+			text.PrintfLine("250 OK")
+		case "QUIT":
+			text.PrintfLine("221 Bye")
+			return
+		default:
+			text.PrintfLine("502 Command not implemented")
+		}
+	}
+}
+
+// authenticate runs the exchange of an AUTH command for mechanism and reports whether the client
+// presented fakeUsername and fakePassword.
+func (s *fakeSMTPServer) authenticate(text *textproto.Conn, mechanism, initial string) bool {
+	// challenge sends a base64 encoded prompt and returns the decoded answer
+	challenge := func(prompt string) (string, bool) {
+		text.PrintfLine("334 %s", base64.StdEncoding.EncodeToString([]byte(prompt)))
+		line, err := text.ReadLine()
+		if err != nil {
+			return "", false
+		}
+		answer, err := base64.StdEncoding.DecodeString(line)
+		return string(answer), err == nil
+	}
+
+	switch mechanism {
+	case "PLAIN":
+		// The response comes with the command, or after an empty challenge
+		response, ok := "", true
+		if initial == "" {
+			response, ok = challenge("")
+		} else {
+			decoded, err := base64.StdEncoding.DecodeString(initial)
+			response, ok = string(decoded), err == nil
+		}
+		if !ok {
+			return false
+		}
+		fields := strings.Split(response, "\x00")
+		return len(fields) == 3 && fields[1] == fakeUsername && fields[2] == fakePassword
+	case "LOGIN":
+		username, ok := challenge("Username:")
+		if !ok {
+			return false
+		}
+		password, ok := challenge("Password:")
+		return ok && username == fakeUsername && password == fakePassword
+	case "CRAM-MD5":
+		nonce := "<1896.697170952@localhost>"
+		answer, ok := challenge(nonce)
+		if !ok {
+			return false
+		}
+		mac := hmac.New(md5.New, []byte(fakePassword))
+		mac.Write([]byte(nonce))
+		return answer == fakeUsername+" "+hex.EncodeToString(mac.Sum(nil))
+	default:
+		return false
+	}
+}
+
+// newTestTLSConfigs returns a server configuration with a self-signed certificate for 127.0.0.1 and
+// a client configuration that trusts it.
+func newTestTLSConfigs(t *testing.T) (server, client *tls.Config) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "fake SMTP server"},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	certificate, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("failed to parse certificate: %v", err)
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(certificate)
+	server = &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}}}
+	return server, &tls.Config{RootCAs: roots}
+}
+
+// header returns the value of the named header of a captured message, or "" if it is missing. Line
+// endings may be CRLF, as captured by a MessageSink, or LF, as read back by the fake server.
+func header(t *testing.T, data []byte, name string) string {
+	t.Helper()
+	data = bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))
+	head, _, _ := bytes.Cut(data, []byte("\n\n"))
+	for _, line := range strings.Split(string(head), "\n") {
+		if key, value, ok := strings.Cut(line, ": "); ok && strings.EqualFold(key, name) {
+			return value
+		}
+	}
+	return ""
+}
+
+// mimePart is one part of a multipart message, with its body decoded from base64 when it was encoded.
+type mimePart struct {
+	header textproto.MIMEHeader
+	body   []byte
+}
+
+// mimeParts parses a captured multipart message and returns its media type and parts.
+func mimeParts(t *testing.T, data []byte) (string, []mimePart) {
+	t.Helper()
+	msg, err := mail.ReadMessage(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("failed to parse message: %v", err)
+	}
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil {
+		t.Fatalf("failed to parse Content-Type: %v", err)
+	}
+
+	var parts []mimePart
+	reader := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := reader.NextRawPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("failed to read part: %v", err)
+		}
+		var body io.Reader = part
+		if strings.EqualFold(part.Header.Get("Content-Transfer-Encoding"), "base64") {
+			body = base64.NewDecoder(base64.StdEncoding, part)
+		}
+		decoded, err := io.ReadAll(body)
+		if err != nil {
+			t.Fatalf("failed to decode part: %v", err)
+		}
+		parts = append(parts, mimePart{header: part.Header, body: decoded})
+	}
+	return mediaType, parts
+}
+
+// writeRandomFile writes size random bytes to a file with the given name in a temporary directory
+// and returns its path and contents.
+func writeRandomFile(t *testing.T, name string, size int) (string, []byte) {
+	t.Helper()
+	content := make([]byte, size)
+	if _, err := rand.Read(content); err != nil {
+		t.Fatalf("failed to generate content: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, content, 0o600); err != nil {
+		t.Fatalf("failed to write %s: %v", path, err)
+	}
+	return path, content
+}
+
+func TestSendEmailWithAttachmentsRoundTrip(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	path, content := writeRandomFile(t, "report.pdf", 10000)
+
+	err := server.service().SendEmailWithAttachmentsContext(context.Background(), []string{"a@example.com"}, "Report", "See attached", []string{path}, false)
+	if err != nil {
+		t.Fatalf("SendEmailWithAttachments failed: %v", err)
+	}
+	messages := server.Messages()
+	if len(messages) != 1 {
+		t.Fatalf("server received %d messages, want 1", len(messages))
+	}
+
+	mediaType, parts := mimeParts(t, messages[0].Data)
+	if mediaType != "multipart/mixed" {
+		t.Errorf("message is %s, want multipart/mixed", mediaType)
+	}
+	if len(parts) != 2 {
+		t.Fatalf("message has %d parts, want the body and the attachment", len(parts))
+	}
+	if string(parts[0].body) != "See attached" {
+		t.Errorf("body is %q, want \"See attached\"", parts[0].body)
+	}
+
+	attachment := parts[1]
+	disposition, params, err := mime.ParseMediaType(attachment.header.Get("Content-Disposition"))
+	if err != nil || disposition != "attachment" || params["filename"] != "report.pdf" {
+		t.Errorf("Content-Disposition is %q, want an attachment named report.pdf", attachment.header.Get("Content-Disposition"))
+	}
+	if got := attachment.header.Get("Content-Type"); got != "application/pdf" {
+		t.Errorf("Content-Type is %q, want application/pdf", got)
+	}
+	if !bytes.Equal(attachment.body, content) {
+		t.Errorf("decoded attachment differs from the file: got %d bytes, want %d", len(attachment.body), len(content))
+	}
+}
+
+func TestSendEmailWithInLineImagesRoundTrip(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	path, content := writeRandomFile(t, "logo.png", 5000)
+
+	body := `<p>Hello</p><img src="cid:logo.png">`
+	err := server.service().SendEmailWithInLineImagesContext(context.Background(), []string{"a@example.com"}, "Welcome", body, []string{path})
+	if err != nil {
+		t.Fatalf("SendEmailWithInLineImages failed: %v", err)
+	}
+	messages := server.Messages()
+	if len(messages) != 1 {
+		t.Fatalf("server received %d messages, want 1", len(messages))
+	}
+
+	mediaType, parts := mimeParts(t, messages[0].Data)
+	if mediaType != "multipart/related" {
+		t.Errorf("message is %s, want multipart/related", mediaType)
+	}
+	if len(parts) != 2 {
+		t.Fatalf("message has %d parts, want the body and the image", len(parts))
+	}
+
+	image := parts[1]
+	if got := image.header.Get("Content-ID"); got != "<logo.png>" {
+		t.Errorf("Content-ID is %q, want <logo.png>", got)
+	}
+	if got := image.header.Get("Content-Type"); got != "image/png" {
+		t.Errorf("Content-Type is %q, want image/png", got)
+	}
+	if disposition, _, _ := mime.ParseMediaType(image.header.Get("Content-Disposition")); disposition != "inline" {
+		t.Errorf("Content-Disposition is %q, want inline", image.header.Get("Content-Disposition"))
+	}
+	if !bytes.Equal(image.body, content) {
+		t.Errorf("decoded image differs from the file: got %d bytes, want %d", len(image.body), len(content))
+	}
+}
+
+func TestSendBulkEmailAddressesEachRecipient(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	service := server.service()
+
+	recipients := []string{"a@example.com", "b@example.com"}
+	if err := service.SendBulkEmailContext(context.Background(), recipients, "News", "Hello", false); err != nil {
+		t.Fatalf("SendBulkEmail failed: %v", err)
+	}
+
+	messages := server.Messages()
+	if len(messages) != len(recipients) {
+		t.Fatalf("server received %d messages, want %d", len(messages), len(recipients))
+	}
+	for i, msg := range messages {
+		if got := header(t, msg.Data, "To"); got != recipients[i] {
+			t.Errorf("message %d has To header %q, want %q", i, got, recipients[i])
+		}
+		if len(msg.To) != 1 || msg.To[0] != recipients[i] {
+			t.Errorf("message %d has envelope recipients %v, want [%s]", i, msg.To, recipients[i])
+		}
+	}
+}
+
+func TestSendBulkEmailSharesOneConnection(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	recipients := []string{"a@example.com", "b@example.com", "c@example.com", "d@example.com", "e@example.com"}
+
+	if err := server.service().SendBulkEmailContext(context.Background(), recipients, "News", "Hello", false); err != nil {
+		t.Fatalf("SendBulkEmail failed: %v", err)
+	}
+	if n := len(server.Messages()); n != len(recipients) {
+		t.Fatalf("server received %d messages, want %d", n, len(recipients))
+	}
+	if n := server.Connections(); n != 1 {
+		t.Errorf("batch used %d connections, want 1", n)
+	}
+	commands := server.Commands()
+	for _, command := range []string{"EHLO", "AUTH PLAIN", "QUIT"} {
+		if n := countOf(commands, command); n != 1 {
+			t.Errorf("%s sent %d times, want once for the batch (commands %v)", command, n, commands)
+		}
+	}
+}
+
+func TestSendBulkEmailReconnectsAfterDrop(t *testing.T) {
+	server := startFakeSMTPServer(t, fakeSMTPConfig{authMechanisms: []string{"PLAIN"}, dropAfter: 2})
+	recipients := []string{"a@example.com", "b@example.com", "c@example.com", "d@example.com", "e@example.com"}
+
+	if err := server.service().SendBulkEmailContext(context.Background(), recipients, "News", "Hello", false); err != nil {
+		t.Fatalf("SendBulkEmail failed: %v", err)
+	}
+	messages := server.Messages()
+	if len(messages) != len(recipients) {
+		t.Fatalf("server received %d messages, want %d", len(messages), len(recipients))
+	}
+	for i, msg := range messages {
+		if len(msg.To) != 1 || msg.To[0] != recipients[i] {
+			t.Errorf("message %d went to %v, want [%s]", i, msg.To, recipients[i])
+		}
+	}
+	if n := server.Connections(); n != 3 {
+		t.Errorf("batch used %d connections, want 3 with the server dropping every second message", n)
+	}
+}
+
+// countOf returns how many times value occurs in values.
+func countOf(values []string, value string) int {
+	n := 0
+	for _, v := range values {
+		if v == value {
+			n++
+		}
+	}
+	return n
+}
+
+func TestSendBulkEmailDryRunMatchesSMTP(t *testing.T) {
+	sink := NewMemorySink()
+	service := newEmailService("smtp.example.com", "587", "sender@example.com", "password", []EmailOption{WithDryRun(sink)})
+
+	if err := service.SendBulkEmail([]string{"a@example.com"}, "News", "Hello", false); err != nil {
+		t.Fatalf("SendBulkEmail failed: %v", err)
+	}
+	msg, ok := sink.LastMessage()
+	if !ok {
+		t.Fatal("nothing was captured")
+	}
+	if got := header(t, msg.Data, "To"); got != "a@example.com" {
+		t.Errorf("To header is %q, want a@example.com", got)
+	}
+}
+
+func TestSendOnlyBCCUsesUndisclosedRecipients(t *testing.T) {
+	sink := NewMemorySink()
+	service := newEmailService("smtp.example.com", "587", "sender@example.com", "password", []EmailOption{WithDryRun(sink)})
+
+	err := service.Send(context.Background(), EmailMessage{BCC: []string{"hidden@example.com"}, Subject: "Hi", Body: "Hello"})
+	if err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	msg, _ := sink.LastMessage()
+	if got := header(t, msg.Data, "To"); got != undisclosedRecipients {
+		t.Errorf("To header is %q, want %q", got, undisclosedRecipients)
+	}
+	if bytes.Contains(msg.Data, []byte("hidden@example.com")) {
+		t.Error("BCC recipient leaked into the message")
+	}
+	if len(msg.To) != 1 || msg.To[0] != "hidden@example.com" {
+		t.Errorf("envelope recipients are %v, want [hidden@example.com]", msg.To)
+	}
+}
@@ -0,0 +1,27 @@
+package gophertoken
+
+import (
+	"errors"
+	"strings"
+)
+
+// AuthPayloadKey is the key under which the auth middlewares store the validated *Payload.
+const AuthPayloadKey = "auth"
+
+// ErrMissingBearerToken is returned when a request has no "Authorization: Bearer <token>" header.
+var ErrMissingBearerToken = errors.New("authorization failed: missing bearer token")
+
+// bearerToken extracts the token from the value of an Authorization header.
+// The scheme is matched case-insensitively, as RFC 7235 requires.
+func bearerToken(header string) (string, error) {
+	scheme, token, found := strings.Cut(strings.TrimSpace(header), " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return "", ErrMissingBearerToken
+	}
+
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return "", ErrMissingBearerToken
+	}
+	return token, nil
+}
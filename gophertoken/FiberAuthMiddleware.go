@@ -0,0 +1,44 @@
+package gophertoken
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+// FiberAuthMiddleware returns a Fiber middleware that authenticates requests with a bearer token.
+//
+// It behaves like GinAuthMiddleware: the token is read from the "Authorization: Bearer <token>" header
+// and validated with the manager. On success the *Payload is stored in c.Locals under AuthPayloadKey.
+// Requests with a missing, malformed, invalid or expired token are rejected with 401 Unauthorized
+// and a JSON error.
+//
+// Example usage:
+//
+//	api := app.Group("/api", FiberAuthMiddleware(manager))
+//	api.Get("/me", func(c *fiber.Ctx) error {
+//	  payload, _ := PayloadFromFiber(c)
+//	  return c.JSON(fiber.Map{"username": payload.Username})
+//	})
+func FiberAuthMiddleware(manager TokenManager) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		token, err := bearerToken(c.Get(fiber.HeaderAuthorization))
+		if err != nil {
+			c.Set(fiber.HeaderWWWAuthenticate, "Bearer")
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": err.Error()})
+		}
+
+		payload, err := manager.ValidateToken(token)
+		if err != nil {
+			c.Set(fiber.HeaderWWWAuthenticate, `Bearer error="invalid_token"`)
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": err.Error()})
+		}
+
+		c.Locals(AuthPayloadKey, payload)
+		return c.Next()
+	}
+}
+
+// PayloadFromFiber returns the payload stored by FiberAuthMiddleware, and false if the request was not authenticated.
+func PayloadFromFiber(c *fiber.Ctx) (*Payload, bool) {
+	payload, ok := c.Locals(AuthPayloadKey).(*Payload)
+	return payload, ok
+}
@@ -0,0 +1,74 @@
+package gophertoken
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+func TestFiberAuthMiddleware(t *testing.T) {
+	maker := newTestJWTMaker(t)
+	userID := uuid.New()
+	valid := mustGenerateFor(t, maker, userID, time.Hour)
+	expired := mustGenerateFor(t, maker, userID, -time.Minute)
+
+	app := fiber.New()
+	app.Get("/me", FiberAuthMiddleware(maker), func(c *fiber.Ctx) error {
+		payload, ok := c.Locals(AuthPayloadKey).(*Payload)
+		if !ok {
+			return c.SendStatus(fiber.StatusInternalServerError)
+		}
+		return c.JSON(fiber.Map{"user_id": payload.UserID, "username": payload.Username})
+	})
+
+	tests := []struct {
+		name          string
+		authorization string
+		wantStatus    int
+	}{
+		{"missing token", "", http.StatusUnauthorized},
+		{"other scheme", "Basic " + valid, http.StatusUnauthorized},
+		{"malformed token", "Bearer not-a-token", http.StatusUnauthorized},
+		{"expired token", "Bearer " + expired, http.StatusUnauthorized},
+		{"valid token", "Bearer " + valid, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/me", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			resp, err := app.Test(req, -1)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusOK {
+				if resp.Header.Get("WWW-Authenticate") == "" {
+					t.Error("401 response has no WWW-Authenticate header")
+				}
+				return
+			}
+
+			var body struct {
+				UserID   uuid.UUID `json:"user_id"`
+				Username string    `json:"username"`
+			}
+			if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if body.UserID != userID || body.Username != "alice" {
+				t.Errorf("handler saw payload for %s %q, want %s \"alice\"", body.UserID, body.Username, userID)
+			}
+		})
+	}
+}
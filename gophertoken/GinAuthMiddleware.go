@@ -0,0 +1,51 @@
+package gophertoken
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GinAuthMiddleware returns a Gin middleware that authenticates requests with a bearer token.
+//
+// The token is read from the "Authorization: Bearer <token>" header and validated with the manager.
+// On success the *Payload is stored in the context under AuthPayloadKey. Requests with a missing,
+// malformed, invalid or expired token are aborted with 401 Unauthorized and a JSON error.
+//
+// Example usage:
+//
+//	api := router.Group("/api", GinAuthMiddleware(manager))
+//	api.GET("/me", func(c *gin.Context) {
+//	  payload, _ := PayloadFromGin(c)
+//	  c.JSON(http.StatusOK, gin.H{"username": payload.Username})
+//	})
+func GinAuthMiddleware(manager TokenManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, err := bearerToken(c.GetHeader("Authorization"))
+		if err != nil {
+			c.Header("WWW-Authenticate", "Bearer")
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+
+		payload, err := manager.ValidateToken(token)
+		if err != nil {
+			c.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.Set(AuthPayloadKey, payload)
+		c.Next()
+	}
+}
+
+// PayloadFromGin returns the payload stored by GinAuthMiddleware, and false if the request was not authenticated.
+func PayloadFromGin(c *gin.Context) (*Payload, bool) {
+	value, exists := c.Get(AuthPayloadKey)
+	if !exists {
+		return nil, false
+	}
+	payload, ok := value.(*Payload)
+	return payload, ok
+}
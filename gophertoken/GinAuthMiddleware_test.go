@@ -0,0 +1,77 @@
+package gophertoken
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func TestGinAuthMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	maker := newTestJWTMaker(t)
+	userID := uuid.New()
+	valid := mustGenerateFor(t, maker, userID, time.Hour)
+	expired := mustGenerateFor(t, maker, userID, -time.Minute)
+
+	tests := []struct {
+		name          string
+		authorization string
+		wantStatus    int
+	}{
+		{"missing token", "", http.StatusUnauthorized},
+		{"other scheme", "Basic " + valid, http.StatusUnauthorized},
+		{"empty bearer", "Bearer ", http.StatusUnauthorized},
+		{"malformed token", "Bearer not-a-token", http.StatusUnauthorized},
+		{"expired token", "Bearer " + expired, http.StatusUnauthorized},
+		{"valid token", "Bearer " + valid, http.StatusOK},
+		{"lowercase scheme", "bearer " + valid, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var payload *Payload
+			router := gin.New()
+			router.GET("/me", GinAuthMiddleware(maker), func(c *gin.Context) {
+				value, _ := c.Get(AuthPayloadKey)
+				payload, _ = value.(*Payload)
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/me", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			if recorder.Code != tt.wantStatus {
+				t.Fatalf("status %d, want %d", recorder.Code, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusOK {
+				if payload != nil {
+					t.Error("handler ran for an unauthenticated request")
+				}
+				if recorder.Header().Get("WWW-Authenticate") == "" {
+					t.Error("401 response has no WWW-Authenticate header")
+				}
+				return
+			}
+			if payload == nil || payload.UserID != userID || payload.Username != "alice" {
+				t.Errorf("context payload is %+v, want the token's payload for %s", payload, userID)
+			}
+		})
+	}
+}
+
+// mustGenerateFor returns a token for userID named "alice" from maker, valid for duration.
+func mustGenerateFor(t *testing.T, maker TokenManager, userID uuid.UUID, duration time.Duration) string {
+	t.Helper()
+	token, err := maker.GenerateToken(userID, "alice", duration)
+	if err != nil {
+		t.Fatalf("GenerateToken failed: %v", err)
+	}
+	return token
+}
@@ -1,7 +1,10 @@
 package gophertoken
 
 import (
+	"crypto/ecdsa"
+	"crypto/rsa"
 	"errors"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -9,8 +12,16 @@ import (
 )
 
 // JWTMaker is a struct for handling JWT token creation and validation.
+//
+// It signs either with a shared secret (HS256) or with a private key (RS256, ES256). In the asymmetric
+// modes the token is verified with the public key only, so services that merely check tokens never
+// need the key that mints them.
 type JWTMaker struct {
-	symmetricKey string
+	signingMethod jwt.SigningMethod
+	signingKey    interface{}
+	verifyingKey  interface{}
+	keyring       *Keyring
+	config        makerConfig
 }
 
 // NewJWTMaker creates a new JWTMaker with the given symmetric key.
@@ -21,11 +32,96 @@ type JWTMaker struct {
 //	if err != nil {
 //	  log.Fatal(err)
 //	}
-func NewJWTMaker(secretKey string) (TokenManager, error) {
+func NewJWTMaker(secretKey string, opts ...MakerOption) (TokenManager, error) {
 	if len(secretKey) == 0 {
 		return nil, errors.New("symmetric key must be set")
 	}
-	return &JWTMaker{symmetricKey: secretKey}, nil
+	return &JWTMaker{
+		signingMethod: jwt.SigningMethodHS256,
+		signingKey:    []byte(secretKey),
+		verifyingKey:  []byte(secretKey),
+		config:        newMakerConfig(opts),
+	}, nil
+}
+
+// NewJWTKeyringMaker creates a new JWTMaker that signs tokens with HS256 using the current key of the
+// keyring and validates them with whichever key their "kid" header names, allowing keys to be rotated
+// without invalidating outstanding tokens.
+//
+// Example usage:
+//
+//	keyring, err := NewKeyring("v1", "your-secret-key")
+//	if err != nil {
+//	  log.Fatal(err)
+//	}
+//	maker, err := NewJWTKeyringMaker(keyring)
+func NewJWTKeyringMaker(keyring *Keyring, opts ...MakerOption) (TokenManager, error) {
+	if keyring == nil {
+		return nil, errors.New("keyring must be set")
+	}
+	return &JWTMaker{
+		signingMethod: jwt.SigningMethodHS256,
+		keyring:       keyring,
+		config:        newMakerConfig(opts),
+	}, nil
+}
+
+// NewRSAJWTMaker creates a new JWTMaker that signs tokens with RS256.
+//
+// The private key may be nil for a maker that only validates tokens; GenerateToken then returns
+// ErrSigningKeyMissing. When the public key is nil, it is taken from the private key.
+//
+// Example usage:
+//
+//	privateKey, err := jwt.ParseRSAPrivateKeyFromPEM(privatePEM)
+//	if err != nil {
+//	  log.Fatal(err)
+//	}
+//	maker, err := NewRSAJWTMaker(privateKey, nil)
+func NewRSAJWTMaker(privateKey *rsa.PrivateKey, publicKey *rsa.PublicKey, opts ...MakerOption) (TokenManager, error) {
+	if publicKey == nil {
+		if privateKey == nil {
+			return nil, errors.New("RSA public or private key must be set")
+		}
+		publicKey = &privateKey.PublicKey
+	}
+
+	maker := &JWTMaker{signingMethod: jwt.SigningMethodRS256, verifyingKey: publicKey, config: newMakerConfig(opts)}
+	if privateKey != nil {
+		maker.signingKey = privateKey
+	}
+	return maker, nil
+}
+
+// NewECDSAJWTMaker creates a new JWTMaker that signs tokens with ES256, which requires keys on the P-256 curve.
+//
+// The private key may be nil for a maker that only validates tokens; GenerateToken then returns
+// ErrSigningKeyMissing. When the public key is nil, it is taken from the private key.
+//
+// Example usage:
+//
+//	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+//	if err != nil {
+//	  log.Fatal(err)
+//	}
+//	signer, err := NewECDSAJWTMaker(privateKey, nil)
+//	verifier, err := NewECDSAJWTMaker(nil, &privateKey.PublicKey)
+func NewECDSAJWTMaker(privateKey *ecdsa.PrivateKey, publicKey *ecdsa.PublicKey, opts ...MakerOption) (TokenManager, error) {
+	if publicKey == nil {
+		if privateKey == nil {
+			return nil, errors.New("ECDSA public or private key must be set")
+		}
+		publicKey = &privateKey.PublicKey
+	}
+	if publicKey.Curve.Params().BitSize != jwt.SigningMethodES256.CurveBits {
+		return nil, errors.New("ES256 requires an ECDSA key on the P-256 curve")
+	}
+
+	maker := &JWTMaker{signingMethod: jwt.SigningMethodES256, verifyingKey: publicKey, config: newMakerConfig(opts)}
+	if privateKey != nil {
+		maker.signingKey = privateKey
+	}
+	return maker, nil
 }
 
 // GenerateToken creates a new JWT token for a specific user with a given duration.
@@ -37,33 +133,78 @@ func NewJWTMaker(secretKey string) (TokenManager, error) {
 //	  log.Fatal(err)
 //	}
 func (j *JWTMaker) GenerateToken(userID uuid.UUID, username string, duration time.Duration) (string, error) {
-	// Create a new payload with the provided userID, username, and token duration
-	payload, err := NewPayload(userID, username, duration)
+	return j.GenerateTokenWithClaims(userID, username, duration, nil)
+}
+
+// GenerateTokenWithClaims creates a new JWT token like GenerateToken, adding the custom claims
+// as top-level claims of the token. Reserved claim names are rejected with ErrReservedClaim.
+//
+// Example usage:
+//
+//	token, err := maker.GenerateTokenWithClaims(userID, "username123", time.Hour, map[string]any{"roles": []string{"admin"}})
+//	if err != nil {
+//	  log.Fatal(err)
+//	}
+func (j *JWTMaker) GenerateTokenWithClaims(userID uuid.UUID, username string, duration time.Duration, customClaims map[string]any) (string, error) {
+	signingKey, keyID := j.signingKey, ""
+	if j.keyring != nil {
+		keyID, signingKey = j.keyring.current()
+	}
+	if signingKey == nil {
+		return "", ErrSigningKeyMissing
+	}
+
+	// Create a new payload with the provided userID, username, token duration and custom claims
+	payload, err := NewPayloadWithClaims(userID, username, duration, customClaims)
 	if err != nil {
 		return "", err
 	}
+	j.config.stamp(payload)
 
-	// Create JWT claims, including userID, username, and token expiration details
+	// Create JWT claims, including userID, username, and token expiration details.
+	// The standard exp and iat claims mirror the custom ones so other JWT libraries can check expiry too.
 	claims := jwt.MapClaims{
 		"id":         payload.ID.String(),
 		"user_id":    payload.UserID.String(),
 		"username":   payload.Username,
 		"issued_at":  payload.IssuedAt.Unix(),
 		"expired_at": payload.ExpiredAt.Unix(),
+		"iat":        payload.IssuedAt.Unix(),
+		"exp":        payload.ExpiredAt.Unix(),
+	}
+	if payload.Issuer != "" {
+		claims["iss"] = payload.Issuer
+	}
+	if payload.Audience != "" {
+		claims["aud"] = payload.Audience
+	}
+	if !payload.NotBefore.IsZero() {
+		claims["nbf"] = payload.NotBefore.Unix()
+	}
+	for name, value := range payload.Claims {
+		claims[name] = value
 	}
 
-	// Generate the token with the specified claims and sign it using the symmetric key
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString([]byte(j.symmetricKey))
+	// Generate the token with the specified claims and sign it with the maker's key
+	token := jwt.NewWithClaims(j.signingMethod, claims)
+	if keyID != "" {
+		token.Header["kid"] = keyID
+	}
+	tokenString, err := token.SignedString(signingKey)
 	if err != nil {
 		return "", err
 	}
 
+	j.config.observer.OnGenerate(payload)
 	return tokenString, nil
 }
 
 // ValidateToken checks if the given JWT token is valid.
 //
+// It returns ErrExpiredToken for a correctly signed token that has expired, so callers can ask for a
+// refresh, and ErrInvalidToken for anything else, such as a bad signature or malformed claims. Along
+// with ErrExpiredToken it returns the payload, e.g. to identify the user during a refresh.
+//
 // Example usage:
 //
 //	payload, err := maker.ValidateToken(tokenString)
@@ -71,38 +212,167 @@ func (j *JWTMaker) GenerateToken(userID uuid.UUID, username string, duration tim
 //	  log.Fatal("Invalid token")
 //	}
 func (j *JWTMaker) ValidateToken(tokenString string) (*Payload, error) {
-	// Parse the token with the correct symmetric key
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("unexpected signing method")
-		}
-		return []byte(j.symmetricKey), nil
-	})
+	return j.config.observeValidation(j.validate(tokenString))
+}
 
+// validate parses and verifies the token for ValidateToken.
+func (j *JWTMaker) validate(tokenString string) (*Payload, error) {
+	// Parse the token with the verifying key, accepting only the maker's own algorithm
+	token, err := jwt.Parse(tokenString, j.verifyingKeyFor, jwt.WithValidMethods([]string{j.signingMethod.Alg()}), jwt.WithLeeway(j.config.clockSkew))
+
+	expired := false
 	if err != nil {
-		return nil, ErrInvalidToken
+		switch {
+		case errors.Is(err, jwt.ErrTokenExpired):
+			// The signature is checked before the claims, so an expired token is otherwise genuine
+			expired = true
+		case errors.Is(err, jwt.ErrTokenNotValidYet):
+			return nil, ErrTokenNotYetValid
+		case errors.Is(err, ErrUnknownKeyID):
+			return nil, ErrUnknownKeyID
+		default:
+			return nil, ErrInvalidToken
+		}
 	}
 
 	// Extract and validate the claims from the token
 	claims, ok := token.Claims.(jwt.MapClaims)
-	if !ok || !token.Valid {
+	if !ok || (!token.Valid && !expired) {
 		return nil, ErrInvalidToken
 	}
 
 	// Parse the extracted claims into the Payload struct
-	payload := &Payload{
-		ID:        uuid.MustParse(claims["id"].(string)),
-		UserID:    uuid.MustParse(claims["user_id"].(string)),
-		Username:  claims["username"].(string),
-		IssuedAt:  time.Unix(int64(claims["issued_at"].(float64)), 0),
-		ExpiredAt: time.Unix(int64(claims["expired_at"].(float64)), 0),
+	payload, err := payloadFromClaims(claims)
+	if err != nil {
+		return nil, err
 	}
 
-	// Validate the payload's expiration
-	err = payload.Valid()
+	// Validate the payload's issuer, audience and validity period
+	payload, err = j.config.verifiedPayload(payload)
+	if err == nil && expired {
+		return payload, ErrExpiredToken
+	}
+	return payload, err
+}
+
+// verifyingKeyFor returns the key that verifies the token: the maker's fixed key, or with a keyring
+// the key named by the token's "kid" header.
+func (j *JWTMaker) verifyingKeyFor(token *jwt.Token) (interface{}, error) {
+	if j.keyring == nil {
+		return j.verifyingKey, nil
+	}
+
+	keyID, ok := token.Header["kid"].(string)
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+	secret, ok := j.keyring.lookup(keyID)
+	if !ok {
+		return nil, ErrUnknownKeyID
+	}
+	return secret, nil
+}
+
+// payloadFromClaims converts verified JWT claims into a Payload.
+//
+// Every claim is type-checked, so a token with a missing or mistyped claim yields ErrInvalidToken
+// instead of a panic.
+func payloadFromClaims(claims jwt.MapClaims) (*Payload, error) {
+	id, ok := uuidClaim(claims, "id")
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+	userID, ok := uuidClaim(claims, "user_id")
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+	username, ok := claims["username"].(string)
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+	issuedAt, ok := timeClaim(claims, "issued_at")
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+	expiredAt, ok := timeClaim(claims, "expired_at")
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+	issuer, err := claims.GetIssuer()
 	if err != nil {
-		return nil, err
+		return nil, ErrInvalidToken
+	}
+	audience, err := claims.GetAudience()
+	if err != nil || len(audience) > 1 || !audienceTyped(claims) {
+		return nil, ErrInvalidToken
+	}
+	notBefore, err := claims.GetNotBefore()
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	// Everything that is not a reserved claim is a custom one
+	var custom map[string]any
+	for name, value := range claims {
+		if reservedClaims[name] {
+			continue
+		}
+		if custom == nil {
+			custom = make(map[string]any)
+		}
+		custom[name] = value
+	}
+
+	return &Payload{
+		ID:        id,
+		UserID:    userID,
+		Username:  username,
+		IssuedAt:  issuedAt,
+		ExpiredAt: expiredAt,
+		Issuer:    issuer,
+		Audience:  strings.Join(audience, ""),
+		NotBefore: notBeforeTime(notBefore),
+		Claims:    custom,
+	}, nil
+}
+
+// uuidClaim reads a UUID encoded as a string claim.
+func uuidClaim(claims jwt.MapClaims, name string) (uuid.UUID, bool) {
+	value, ok := claims[name].(string)
+	if !ok {
+		return uuid.Nil, false
 	}
+	parsed, err := uuid.Parse(value)
+	if err != nil {
+		return uuid.Nil, false
+	}
+	return parsed, true
+}
 
-	return payload, nil
+// audienceTyped reports whether the aud claim, if any, is a string or a list, since GetAudience treats
+// a claim of any other type as no audience at all.
+func audienceTyped(claims jwt.MapClaims) bool {
+	switch claims["aud"].(type) {
+	case nil, string, []interface{}:
+		return true
+	default:
+		return false
+	}
+}
+
+// timeClaim reads a Unix timestamp claim, which encoding/json decodes as a float64.
+func timeClaim(claims jwt.MapClaims, name string) (time.Time, bool) {
+	value, ok := claims[name].(float64)
+	if !ok {
+		return time.Time{}, false
+	}
+	return time.Unix(int64(value), 0), true
+}
+
+// notBeforeTime returns the time of an nbf claim, or the zero time when the token has none.
+func notBeforeTime(notBefore *jwt.NumericDate) time.Time {
+	if notBefore == nil {
+		return time.Time{}
+	}
+	return notBefore.Time
 }
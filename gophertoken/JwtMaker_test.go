@@ -0,0 +1,299 @@
+package gophertoken
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+const testSecret = "a-secret-key-for-tests-only"
+
+// newTestJWTMaker returns an HS256 maker for testSecret.
+func newTestJWTMaker(t *testing.T) TokenManager {
+	t.Helper()
+	maker, err := NewJWTMaker(testSecret)
+	if err != nil {
+		t.Fatalf("NewJWTMaker failed: %v", err)
+	}
+	return maker
+}
+
+func TestJWTValidateTokenSentinelErrors(t *testing.T) {
+	maker := newTestJWTMaker(t)
+	otherMaker, err := NewJWTMaker("another-secret-key")
+	if err != nil {
+		t.Fatalf("NewJWTMaker failed: %v", err)
+	}
+	userID := uuid.New()
+
+	valid, err := maker.GenerateToken(userID, "alice", time.Hour)
+	if err != nil {
+		t.Fatalf("GenerateToken failed: %v", err)
+	}
+	expired, err := maker.GenerateToken(userID, "alice", -time.Minute)
+	if err != nil {
+		t.Fatalf("GenerateToken failed: %v", err)
+	}
+	forged, err := otherMaker.GenerateToken(userID, "alice", time.Hour)
+	if err != nil {
+		t.Fatalf("GenerateToken failed: %v", err)
+	}
+	expiredForged, err := otherMaker.GenerateToken(userID, "alice", -time.Minute)
+	if err != nil {
+		t.Fatalf("GenerateToken failed: %v", err)
+	}
+
+	// Change a character in the middle of the signature, whose bits all count, unlike the last one's
+	at := len(valid) - 5
+	replacement := "A"
+	if valid[at] == 'A' {
+		replacement = "B"
+	}
+	tampered := valid[:at] + replacement + valid[at+1:]
+
+	tests := []struct {
+		name        string
+		token       string
+		wantErr     error
+		wantPayload bool
+	}{
+		{"valid", valid, nil, true},
+		{"expired", expired, ErrExpiredToken, true},
+		{"signed with another key", forged, ErrInvalidToken, false},
+		{"expired and signed with another key", expiredForged, ErrInvalidToken, false},
+		{"tampered signature", tampered, ErrInvalidToken, false},
+		{"not a JWT", "not-a-token", ErrInvalidToken, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload, err := maker.ValidateToken(tt.token)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ValidateToken returned %v, want %v", err, tt.wantErr)
+			}
+			if (payload != nil) != tt.wantPayload {
+				t.Fatalf("ValidateToken returned payload %v, want one: %v", payload, tt.wantPayload)
+			}
+			if payload != nil && (payload.UserID != userID || payload.Username != "alice") {
+				t.Errorf("payload identifies %s %q, want %s \"alice\"", payload.UserID, payload.Username, userID)
+			}
+		})
+	}
+}
+
+func TestJWTValidateTokenRejectsTamperedPayload(t *testing.T) {
+	maker := newTestJWTMaker(t)
+	token, err := maker.GenerateToken(uuid.New(), "alice", time.Hour)
+	if err != nil {
+		t.Fatalf("GenerateToken failed: %v", err)
+	}
+
+	// Replace the claims with those of another token while keeping the original signature
+	other, err := maker.GenerateToken(uuid.New(), "mallory", time.Hour)
+	if err != nil {
+		t.Fatalf("GenerateToken failed: %v", err)
+	}
+	parts, otherParts := strings.Split(token, "."), strings.Split(other, ".")
+	spliced := parts[0] + "." + otherParts[1] + "." + parts[2]
+
+	if _, err := maker.ValidateToken(spliced); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("ValidateToken returned %v, want %v", err, ErrInvalidToken)
+	}
+}
+
+// signTestClaims signs arbitrary claims with testSecret, as an attacker holding a leaked key or a
+// buggy issuer could.
+func signTestClaims(t *testing.T, claims jwt.MapClaims) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(testSecret))
+	if err != nil {
+		t.Fatalf("SignedString failed: %v", err)
+	}
+	return token
+}
+
+func TestJWTValidateTokenMalformedClaims(t *testing.T) {
+	maker := newTestJWTMaker(t)
+	now := time.Now()
+
+	// validClaims returns the claims GenerateToken would produce, with the given changes applied
+	validClaims := func(changes map[string]any) jwt.MapClaims {
+		claims := jwt.MapClaims{
+			"id":         uuid.NewString(),
+			"user_id":    uuid.NewString(),
+			"username":   "alice",
+			"issued_at":  now.Unix(),
+			"expired_at": now.Add(time.Hour).Unix(),
+			"iat":        now.Unix(),
+			"exp":        now.Add(time.Hour).Unix(),
+		}
+		for name, value := range changes {
+			if value == nil {
+				delete(claims, name)
+				continue
+			}
+			claims[name] = value
+		}
+		return claims
+	}
+
+	if _, err := maker.ValidateToken(signTestClaims(t, validClaims(nil))); err != nil {
+		t.Fatalf("ValidateToken rejected well-formed claims: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		changes map[string]any
+	}{
+		{"missing id", map[string]any{"id": nil}},
+		{"renamed id", map[string]any{"id": nil, "jti": uuid.NewString()}},
+		{"numeric id", map[string]any{"id": 42}},
+		{"id not a UUID", map[string]any{"id": "not-a-uuid"}},
+		{"missing user_id", map[string]any{"user_id": nil}},
+		{"user_id as object", map[string]any{"user_id": map[string]any{"value": uuid.NewString()}}},
+		{"missing username", map[string]any{"username": nil}},
+		{"username as list", map[string]any{"username": []string{"alice"}}},
+		{"missing issued_at", map[string]any{"issued_at": nil}},
+		{"issued_at as string", map[string]any{"issued_at": now.Format(time.RFC3339)}},
+		{"expired_at as bool", map[string]any{"expired_at": true}},
+		{"exp as string", map[string]any{"exp": "tomorrow"}},
+		{"iss as number", map[string]any{"iss": 7}},
+		{"aud as number", map[string]any{"aud": 7}},
+		{"two audiences", map[string]any{"aud": []string{"api", "admin"}}},
+		{"nbf as string", map[string]any{"nbf": "now"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if r := recover(); r != nil {
+					t.Fatalf("ValidateToken panicked: %v", r)
+				}
+			}()
+
+			payload, err := maker.ValidateToken(signTestClaims(t, validClaims(tt.changes)))
+			if !errors.Is(err, ErrInvalidToken) {
+				t.Fatalf("ValidateToken returned %v, want %v", err, ErrInvalidToken)
+			}
+			if payload != nil {
+				t.Errorf("ValidateToken returned payload %+v along with the error", payload)
+			}
+		})
+	}
+}
+
+func TestJWTAsymmetricSignAndVerify(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate RSA key: %v", err)
+	}
+	otherRSAKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate RSA key: %v", err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate ECDSA key: %v", err)
+	}
+	otherECKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate ECDSA key: %v", err)
+	}
+
+	// makers returns the signer and the verifiers holding the matching and another public key
+	tests := []struct {
+		name    string
+		makers  func() (signer, verifier, otherVerifier TokenManager, err error)
+		wantAlg string
+	}{
+		{"RS256", func() (TokenManager, TokenManager, TokenManager, error) {
+			signer, err1 := NewRSAJWTMaker(rsaKey, nil)
+			verifier, err2 := NewRSAJWTMaker(nil, &rsaKey.PublicKey)
+			other, err3 := NewRSAJWTMaker(nil, &otherRSAKey.PublicKey)
+			return signer, verifier, other, errors.Join(err1, err2, err3)
+		}, "RS256"},
+		{"ES256", func() (TokenManager, TokenManager, TokenManager, error) {
+			signer, err1 := NewECDSAJWTMaker(ecKey, nil)
+			verifier, err2 := NewECDSAJWTMaker(nil, &ecKey.PublicKey)
+			other, err3 := NewECDSAJWTMaker(nil, &otherECKey.PublicKey)
+			return signer, verifier, other, errors.Join(err1, err2, err3)
+		}, "ES256"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signer, verifier, otherVerifier, err := tt.makers()
+			if err != nil {
+				t.Fatalf("failed to create makers: %v", err)
+			}
+			userID := uuid.New()
+			token, err := signer.GenerateToken(userID, "alice", time.Hour)
+			if err != nil {
+				t.Fatalf("GenerateToken failed: %v", err)
+			}
+			parsed, _, err := jwt.NewParser().ParseUnverified(token, jwt.MapClaims{})
+			if err != nil || parsed.Method.Alg() != tt.wantAlg {
+				t.Fatalf("token is signed with %v (%v), want %s", parsed.Header["alg"], err, tt.wantAlg)
+			}
+
+			payload, err := verifier.ValidateToken(token)
+			if err != nil {
+				t.Fatalf("ValidateToken with the public key failed: %v", err)
+			}
+			if payload.UserID != userID || payload.Username != "alice" {
+				t.Errorf("payload identifies %s %q, want %s \"alice\"", payload.UserID, payload.Username, userID)
+			}
+
+			if _, err := otherVerifier.ValidateToken(token); !errors.Is(err, ErrInvalidToken) {
+				t.Errorf("ValidateToken with another public key returned %v, want %v", err, ErrInvalidToken)
+			}
+			if _, err := verifier.GenerateToken(userID, "alice", time.Hour); !errors.Is(err, ErrSigningKeyMissing) {
+				t.Errorf("GenerateToken on a verifier returned %v, want %v", err, ErrSigningKeyMissing)
+			}
+		})
+	}
+}
+
+func TestJWTRS256RejectsHS256SignedWithPublicKey(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate RSA key: %v", err)
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	if err != nil {
+		t.Fatalf("failed to marshal public key: %v", err)
+	}
+	publicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})
+
+	verifier, err := NewTokenManager(TokenTypeJWTRS256, string(publicPEM))
+	if err != nil {
+		t.Fatalf("NewTokenManager failed: %v", err)
+	}
+
+	// An attacker who knows the public key signs with it as an HMAC secret, hoping it is used as one
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"id":         uuid.NewString(),
+		"user_id":    uuid.NewString(),
+		"username":   "mallory",
+		"issued_at":  now.Unix(),
+		"expired_at": now.Add(time.Hour).Unix(),
+		"exp":        now.Add(time.Hour).Unix(),
+	}
+	for name, secret := range map[string][]byte{"PEM": publicPEM, "DER": publicDER} {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(secret)
+		if err != nil {
+			t.Fatalf("SignedString failed: %v", err)
+		}
+		if payload, err := verifier.ValidateToken(token); !errors.Is(err, ErrInvalidToken) || payload != nil {
+			t.Errorf("HS256 token keyed with the %s public key returned %+v, %v, want %v", name, payload, err, ErrInvalidToken)
+		}
+	}
+}
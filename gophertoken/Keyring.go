@@ -0,0 +1,93 @@
+package gophertoken
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrUnknownKeyID is returned when a token names a signing key that is not in the keyring.
+var ErrUnknownKeyID = errors.New("token validation failed: unknown key id")
+
+// Keyring holds the HMAC secrets of a JWTMaker created with NewJWTKeyringMaker, identified by key ID.
+//
+// Tokens are signed with the current key and carry its ID in the "kid" header. Validation picks the
+// key named by the header, so tokens signed with a previous key stay valid after a rotation until
+// that key is removed. A Keyring is safe for concurrent use.
+//
+// Example usage:
+//
+//	keyring, err := NewKeyring("2024-09", os.Getenv("JWT_SECRET_2024_09"))
+//	if err != nil {
+//	  log.Fatal(err)
+//	}
+//	maker, err := NewJWTKeyringMaker(keyring)
+//
+//	// Later: new tokens use the new key, tokens signed with "2024-09" are still accepted
+//	err = keyring.Rotate("2024-10", os.Getenv("JWT_SECRET_2024_10"))
+//
+//	// Once every token signed with the old key has expired
+//	err = keyring.Remove("2024-09")
+type Keyring struct {
+	mu        sync.RWMutex
+	currentID string
+	keys      map[string][]byte
+}
+
+// NewKeyring creates a keyring whose current key is secret, identified by id.
+func NewKeyring(id, secret string) (*Keyring, error) {
+	keyring := &Keyring{keys: make(map[string][]byte)}
+	if err := keyring.Rotate(id, secret); err != nil {
+		return nil, err
+	}
+	return keyring, nil
+}
+
+// Rotate adds secret under id and makes it the current signing key. The previous keys remain
+// available for validation.
+func (k *Keyring) Rotate(id, secret string) error {
+	if id == "" {
+		return errors.New("key id must be set")
+	}
+	if len(secret) == 0 {
+		return errors.New("symmetric key must be set")
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if _, exists := k.keys[id]; exists {
+		return fmt.Errorf("key id %q is already in the keyring", id)
+	}
+	k.keys[id] = []byte(secret)
+	k.currentID = id
+	return nil
+}
+
+// Remove drops a previous key, so tokens signed with it are no longer accepted.
+// The current key cannot be removed.
+func (k *Keyring) Remove(id string) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if id == k.currentID {
+		return fmt.Errorf("key id %q is the current key", id)
+	}
+	delete(k.keys, id)
+	return nil
+}
+
+// current returns the ID and secret of the signing key.
+func (k *Keyring) current() (string, []byte) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.currentID, k.keys[k.currentID]
+}
+
+// lookup returns the secret with the given ID.
+func (k *Keyring) lookup(id string) ([]byte, bool) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	secret, ok := k.keys[id]
+	return secret, ok
+}
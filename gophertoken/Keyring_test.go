@@ -0,0 +1,56 @@
+package gophertoken
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+func TestKeyringRotation(t *testing.T) {
+	keyring, err := NewKeyring("v1", "first-secret")
+	if err != nil {
+		t.Fatalf("NewKeyring failed: %v", err)
+	}
+	maker, err := NewJWTKeyringMaker(keyring)
+	if err != nil {
+		t.Fatalf("NewJWTKeyringMaker failed: %v", err)
+	}
+
+	oldToken, err := maker.GenerateToken(uuid.New(), "alice", time.Hour)
+	if err != nil {
+		t.Fatalf("GenerateToken failed: %v", err)
+	}
+	if err := keyring.Rotate("v2", "second-secret"); err != nil {
+		t.Fatalf("Rotate failed: %v", err)
+	}
+	newToken, err := maker.GenerateToken(uuid.New(), "alice", time.Hour)
+	if err != nil {
+		t.Fatalf("GenerateToken failed: %v", err)
+	}
+
+	for token, wantKid := range map[string]string{oldToken: "v1", newToken: "v2"} {
+		parsed, _, err := jwt.NewParser().ParseUnverified(token, jwt.MapClaims{})
+		if err != nil || parsed.Header["kid"] != wantKid {
+			t.Fatalf("token has kid %v (%v), want %s", parsed.Header["kid"], err, wantKid)
+		}
+		if _, err := maker.ValidateToken(token); err != nil {
+			t.Fatalf("ValidateToken of the %s token after rotation failed: %v", wantKid, err)
+		}
+	}
+
+	if err := keyring.Remove("v2"); err == nil {
+		t.Error("Remove of the current key succeeded, want an error")
+	}
+	if err := keyring.Remove("v1"); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+	if _, err := maker.ValidateToken(oldToken); !errors.Is(err, ErrUnknownKeyID) {
+		t.Errorf("ValidateToken of the v1 token after removing its key returned %v, want %v", err, ErrUnknownKeyID)
+	}
+	if _, err := maker.ValidateToken(newToken); err != nil {
+		t.Errorf("ValidateToken of the v2 token after removing v1 failed: %v", err)
+	}
+}
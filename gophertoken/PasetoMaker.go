@@ -1,18 +1,34 @@
 package gophertoken
 
 import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/o1egl/paseto"
+	"golang.org/x/crypto/argon2"
 	"golang.org/x/crypto/chacha20poly1305"
 )
 
+// passphraseSalt is the salt NewPasetoMakerFromPassphrase derives keys with unless WithPassphraseSalt
+// is given. It is fixed so that every instance of a service derives the same key from the same passphrase.
+var passphraseSalt = []byte("gophertoken/paseto-passphrase/v1")
+
 // PasetoMaker is a struct for handling Paseto token creation and validation.
+//
+// It either encrypts tokens with a symmetric key (v2.local) or, when created with NewPublicPasetoMaker,
+// signs them with an Ed25519 private key (v2.public) so they can be verified with the public key alone.
+// It implements PasetoTokenManager.
 type PasetoMaker struct {
 	paseto       *paseto.V2
 	symmetricKey []byte
+	privateKey   ed25519.PrivateKey
+	publicKey    ed25519.PublicKey
+	config       makerConfig
 }
 
 // NewPasetoMaker creates a new PasetoMaker with the given symmetric key.
@@ -23,13 +39,88 @@ type PasetoMaker struct {
 //	if err != nil {
 //	  log.Fatal(err)
 //	}
-func NewPasetoMaker(secretKey string) (TokenManager, error) {
+func NewPasetoMaker(secretKey string, opts ...MakerOption) (PasetoTokenManager, error) {
 	if len(secretKey) != chacha20poly1305.KeySize {
 		return nil, fmt.Errorf("invalid key size: must be exactly %d bytes", chacha20poly1305.KeySize)
 	}
 	maker := &PasetoMaker{
 		paseto:       paseto.NewV2(),
 		symmetricKey: []byte(secretKey),
+		config:       newMakerConfig(opts),
+	}
+	return maker, nil
+}
+
+// NewPasetoMakerFromPassphrase creates a new PasetoMaker from a passphrase of any length.
+//
+// The 32-byte symmetric key is derived with DerivePasetoKey, so the same passphrase and salt always
+// yield the same key. The default salt is public and shared by every user of this package, so an
+// attacker holding a token can test guesses against it offline: the passphrase must be high-entropy,
+// e.g. a random string of 20 characters or more, not a memorable password. WithPassphraseSalt sets a
+// salt of your own. Use NewPasetoMaker for raw 32-byte keys.
+//
+// Example usage:
+//
+//	maker, err := NewPasetoMakerFromPassphrase(os.Getenv("TOKEN_PASSPHRASE"),
+//	  WithPassphraseSalt([]byte(os.Getenv("TOKEN_SALT"))))
+//	if err != nil {
+//	  log.Fatal(err)
+//	}
+func NewPasetoMakerFromPassphrase(passphrase string, opts ...MakerOption) (PasetoTokenManager, error) {
+	if len(passphrase) == 0 {
+		return nil, errors.New("passphrase must be set")
+	}
+	salt := newMakerConfig(opts).passphraseSalt
+	if len(salt) == 0 {
+		salt = passphraseSalt
+	}
+	return NewPasetoMaker(string(DerivePasetoKey(passphrase, salt)), opts...)
+}
+
+// DerivePasetoKey derives a 32-byte Paseto key from a passphrase with Argon2id, which makes guessing
+// the passphrase from a token expensive. Pass the result to NewPasetoMaker as a string to use a salt
+// of your own.
+//
+// Example usage:
+//
+//	key := DerivePasetoKey(passphrase, []byte("my-service-salt"))
+//	maker, err := NewPasetoMaker(string(key))
+func DerivePasetoKey(passphrase string, salt []byte) []byte {
+	return argon2.IDKey([]byte(passphrase), salt, 1, 64*1024, 4, chacha20poly1305.KeySize)
+}
+
+// NewPublicPasetoMaker creates a new PasetoMaker issuing v2.public tokens signed with an Ed25519 key.
+//
+// The private key may be nil for a maker that only validates tokens; GenerateToken then returns
+// ErrSigningKeyMissing. When the public key is nil, it is taken from the private key.
+//
+// Example usage:
+//
+//	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+//	if err != nil {
+//	  log.Fatal(err)
+//	}
+//	signer, err := NewPublicPasetoMaker(privateKey, nil)
+//	verifier, err := NewPublicPasetoMaker(nil, publicKey)
+func NewPublicPasetoMaker(privateKey ed25519.PrivateKey, publicKey ed25519.PublicKey, opts ...MakerOption) (PasetoTokenManager, error) {
+	if privateKey != nil && len(privateKey) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("invalid private key size: must be exactly %d bytes", ed25519.PrivateKeySize)
+	}
+	if publicKey == nil {
+		if privateKey == nil {
+			return nil, errors.New("Ed25519 public or private key must be set")
+		}
+		publicKey = privateKey.Public().(ed25519.PublicKey)
+	}
+	if len(publicKey) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid public key size: must be exactly %d bytes", ed25519.PublicKeySize)
+	}
+
+	maker := &PasetoMaker{
+		paseto:     paseto.NewV2(),
+		privateKey: privateKey,
+		publicKey:  publicKey,
+		config:     newMakerConfig(opts),
 	}
 	return maker, nil
 }
@@ -43,18 +134,86 @@ func NewPasetoMaker(secretKey string) (TokenManager, error) {
 //	  log.Fatal(err)
 //	}
 func (maker *PasetoMaker) GenerateToken(userID uuid.UUID, username string, duration time.Duration) (string, error) {
-	// Create the payload with userID and username
-	payload, err := NewPayload(userID, username, duration)
+	return maker.GenerateTokenWithClaims(userID, username, duration, nil)
+}
+
+// GenerateTokenWithClaims creates a new Paseto token like GenerateToken, carrying the custom claims
+// in the payload. Reserved claim names are rejected with ErrReservedClaim.
+//
+// Example usage:
+//
+//	token, err := maker.GenerateTokenWithClaims(userID, "username123", time.Hour, map[string]any{"tenant_id": "acme"})
+//	if err != nil {
+//	  log.Fatal(err)
+//	}
+func (maker *PasetoMaker) GenerateTokenWithClaims(userID uuid.UUID, username string, duration time.Duration, claims map[string]any) (string, error) {
+	return maker.GenerateTokenWithFooter(userID, username, duration, claims, "")
+}
+
+// GenerateTokenWithFooter creates a new Paseto token like GenerateTokenWithClaims, with the footer
+// appended. The footer is not encrypted, so anyone can read it with PasetoFooter, but it is
+// authenticated along with the payload and cannot be altered. It suits metadata needed before the
+// token can be validated, such as the id of the key it was issued with. An empty footer is left out.
+//
+// Example usage:
+//
+//	token, err := maker.GenerateTokenWithFooter(userID, "username123", time.Hour, nil, `{"kid":"v2"}`)
+//	if err != nil {
+//	  log.Fatal(err)
+//	}
+func (maker *PasetoMaker) GenerateTokenWithFooter(userID uuid.UUID, username string, duration time.Duration, claims map[string]any, footer string) (string, error) {
+	return maker.GenerateTokenWithAssertion(userID, username, duration, claims, footer, "")
+}
+
+// GenerateTokenWithAssertion creates a new Paseto token like GenerateTokenWithFooter, bound to an
+// implicit assertion: data the token is only valid with, such as the ID of the session or device it
+// was issued to, which is not sent with the token. It must be passed again to ValidateTokenWithAssertion.
+//
+// Paseto v2 has no implicit assertions of its own, unlike v3 and v4, so the token carries a SHA-256
+// digest of the assertion within its encrypted or signed payload. The assertion itself is not part of
+// the token, but in public mode its digest can be read, so a guessable assertion can be recovered.
+// An empty assertion binds nothing.
+//
+// Example usage:
+//
+//	token, err := maker.GenerateTokenWithAssertion(userID, "username123", time.Hour, nil, "", sessionID)
+//	if err != nil {
+//	  log.Fatal(err)
+//	}
+func (maker *PasetoMaker) GenerateTokenWithAssertion(userID uuid.UUID, username string, duration time.Duration, claims map[string]any, footer, implicitAssertion string) (string, error) {
+	// Create the payload with userID, username and the custom claims
+	payload, err := NewPayloadWithClaims(userID, username, duration, claims)
 	if err != nil {
 		return "", err
 	}
+	maker.config.stamp(payload)
+	asserted := assertedPayload{Payload: payload, AssertionDigest: assertionDigest(implicitAssertion)}
 
-	// Encrypt the payload and return the token string
-	return maker.paseto.Encrypt(maker.symmetricKey, payload, nil)
+	// Sign the payload in public mode, or encrypt it otherwise
+	var token string
+	if maker.publicKey != nil {
+		if maker.privateKey == nil {
+			return "", ErrSigningKeyMissing
+		}
+		token, err = maker.paseto.Sign(maker.privateKey, asserted, footer)
+	} else {
+		token, err = maker.paseto.Encrypt(maker.symmetricKey, asserted, footer)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	maker.config.observer.OnGenerate(payload)
+	return token, nil
 }
 
 // ValidateToken checks if the given Paseto token is valid.
 //
+// For a genuine token that has only expired, the payload is returned along with ErrExpiredToken.
+// Tokens carrying a footer are rejected with ErrFooterMismatch; use ValidateTokenWithFooter for them.
+// Tokens bound to an implicit assertion are rejected with ErrAssertionMismatch; use
+// ValidateTokenWithAssertion for them.
+//
 // Example usage:
 //
 //	payload, err := maker.ValidateToken(tokenString)
@@ -62,18 +221,92 @@ func (maker *PasetoMaker) GenerateToken(userID uuid.UUID, username string, durat
 //	  log.Fatal("Invalid token")
 //	}
 func (maker *PasetoMaker) ValidateToken(token string) (*Payload, error) {
-	// Decrypt the token to extract the payload
-	payload := &Payload{}
-	err := maker.paseto.Decrypt(token, maker.symmetricKey, payload, nil)
+	return maker.ValidateTokenWithFooter(token, "")
+}
+
+// ValidateTokenWithFooter checks if the given Paseto token is valid like ValidateToken and carries
+// exactly the expected footer, rejecting it with ErrFooterMismatch otherwise. A token whose footer
+// was tampered with fails authentication and is rejected with ErrInvalidToken.
+//
+// Example usage:
+//
+//	payload, err := maker.ValidateTokenWithFooter(tokenString, `{"kid":"v2"}`)
+//	if err != nil {
+//	  log.Fatal("Invalid token")
+//	}
+func (maker *PasetoMaker) ValidateTokenWithFooter(token, footer string) (*Payload, error) {
+	return maker.ValidateTokenWithAssertion(token, footer, "")
+}
+
+// ValidateTokenWithAssertion checks if the given Paseto token is valid like ValidateTokenWithFooter and
+// was bound to the same implicit assertion by GenerateTokenWithAssertion, rejecting it with
+// ErrAssertionMismatch otherwise.
+//
+// Example usage:
+//
+//	payload, err := maker.ValidateTokenWithAssertion(tokenString, "", sessionID)
+//	if err != nil {
+//	  log.Fatal("Invalid token")
+//	}
+func (maker *PasetoMaker) ValidateTokenWithAssertion(token, footer, implicitAssertion string) (*Payload, error) {
+	return maker.config.observeValidation(maker.validate(token, footer, implicitAssertion))
+}
+
+// validate decrypts or verifies the token and checks its footer and implicit assertion.
+func (maker *PasetoMaker) validate(token, expectedFooter, implicitAssertion string) (*Payload, error) {
+	// Decrypt the token, or verify its signature in public mode, to extract the payload and footer
+	asserted := assertedPayload{Payload: &Payload{}}
+	var footer string
+	var err error
+	if maker.publicKey != nil {
+		err = maker.paseto.Verify(token, maker.publicKey, &asserted, &footer)
+	} else {
+		err = maker.paseto.Decrypt(token, maker.symmetricKey, &asserted, &footer)
+	}
 	if err != nil {
 		return nil, ErrInvalidToken
 	}
+	if footer != expectedFooter {
+		return nil, ErrFooterMismatch
+	}
+	if subtle.ConstantTimeCompare(asserted.AssertionDigest, assertionDigest(implicitAssertion)) != 1 {
+		return nil, ErrAssertionMismatch
+	}
 
-	// Validate the payload (check expiration)
-	err = payload.Valid()
-	if err != nil {
-		return nil, err
+	// Validate the payload's issuer, audience and validity period
+	return maker.config.verifiedPayload(asserted.Payload)
+}
+
+// assertedPayload is the content of a Paseto token: the payload and, for a token bound to an implicit
+// assertion, the digest of the assertion.
+type assertedPayload struct {
+	*Payload
+	AssertionDigest []byte `json:"implicit_assertion,omitempty"`
+}
+
+// assertionDigest returns the SHA-256 digest of an implicit assertion, or nil for none.
+func assertionDigest(implicitAssertion string) []byte {
+	if implicitAssertion == "" {
+		return nil
 	}
+	digest := sha256.Sum256([]byte(implicitAssertion))
+	return digest[:]
+}
 
-	return payload, nil
+// PasetoFooter returns the footer of a Paseto token without validating the token, e.g. to pick the
+// maker for the key id it names. The footer must not be trusted until the token has been validated.
+//
+// Example usage:
+//
+//	footer, err := PasetoFooter(tokenString)
+//	if err != nil {
+//	  log.Fatal(err)
+//	}
+//	payload, err := makers[footer].ValidateTokenWithFooter(tokenString, footer)
+func PasetoFooter(token string) (string, error) {
+	var footer string
+	if err := paseto.ParseFooter(token, &footer); err != nil {
+		return "", ErrInvalidToken
+	}
+	return footer, nil
 }
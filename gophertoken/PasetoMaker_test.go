@@ -0,0 +1,268 @@
+package gophertoken
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const testPasetoKey = "0123456789abcdef0123456789abcdef"
+
+// newTestPasetoMaker returns a v2.local maker for testPasetoKey.
+func newTestPasetoMaker(t *testing.T) PasetoTokenManager {
+	t.Helper()
+	maker, err := NewPasetoMaker(testPasetoKey)
+	if err != nil {
+		t.Fatalf("NewPasetoMaker failed: %v", err)
+	}
+	return maker
+}
+
+func TestPasetoFooterRoundTrip(t *testing.T) {
+	maker := newTestPasetoMaker(t)
+	userID := uuid.New()
+	footer := `{"kid":"v2"}`
+
+	token, err := maker.GenerateTokenWithFooter(userID, "alice", time.Hour, nil, footer)
+	if err != nil {
+		t.Fatalf("GenerateTokenWithFooter failed: %v", err)
+	}
+	if got, err := PasetoFooter(token); err != nil || got != footer {
+		t.Fatalf("PasetoFooter returned %q, %v, want %q", got, err, footer)
+	}
+
+	payload, err := maker.ValidateTokenWithFooter(token, footer)
+	if err != nil {
+		t.Fatalf("ValidateTokenWithFooter failed: %v", err)
+	}
+	if payload.UserID != userID || payload.Username != "alice" {
+		t.Errorf("payload identifies %s %q, want %s \"alice\"", payload.UserID, payload.Username, userID)
+	}
+
+	if _, err := maker.ValidateTokenWithFooter(token, `{"kid":"v1"}`); !errors.Is(err, ErrFooterMismatch) {
+		t.Errorf("ValidateTokenWithFooter with another footer returned %v, want %v", err, ErrFooterMismatch)
+	}
+	if _, err := maker.ValidateToken(token); !errors.Is(err, ErrFooterMismatch) {
+		t.Errorf("ValidateToken of a token with a footer returned %v, want %v", err, ErrFooterMismatch)
+	}
+}
+
+func TestPasetoTamperedFooterRejected(t *testing.T) {
+	maker := newTestPasetoMaker(t)
+	token, err := maker.GenerateTokenWithFooter(uuid.New(), "alice", time.Hour, nil, `{"kid":"v2"}`)
+	if err != nil {
+		t.Fatalf("GenerateTokenWithFooter failed: %v", err)
+	}
+
+	// Swap the footer for another one, leaving the authentication tag computed over the original
+	tampered := token[:strings.LastIndex(token, ".")+1] + base64.RawURLEncoding.EncodeToString([]byte(`{"kid":"v1"}`))
+	payload, err := maker.ValidateTokenWithFooter(tampered, `{"kid":"v1"}`)
+	if !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("ValidateTokenWithFooter returned %v, want %v", err, ErrInvalidToken)
+	}
+	if payload != nil {
+		t.Errorf("ValidateTokenWithFooter returned payload %+v along with the error", payload)
+	}
+}
+
+func TestPasetoImplicitAssertion(t *testing.T) {
+	maker := newTestPasetoMaker(t)
+	token, err := maker.GenerateTokenWithAssertion(uuid.New(), "alice", time.Hour, nil, `{"kid":"v2"}`, "session-1")
+	if err != nil {
+		t.Fatalf("GenerateTokenWithAssertion failed: %v", err)
+	}
+	if strings.Contains(token, "session-1") {
+		t.Fatal("the implicit assertion was sent with the token")
+	}
+
+	if _, err := maker.ValidateTokenWithAssertion(token, `{"kid":"v2"}`, "session-1"); err != nil {
+		t.Fatalf("ValidateTokenWithAssertion failed: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		assertion string
+	}{
+		{"another assertion", "session-2"},
+		{"no assertion", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload, err := maker.ValidateTokenWithAssertion(token, `{"kid":"v2"}`, tt.assertion)
+			if !errors.Is(err, ErrAssertionMismatch) {
+				t.Fatalf("ValidateTokenWithAssertion returned %v, want %v", err, ErrAssertionMismatch)
+			}
+			if payload != nil {
+				t.Errorf("ValidateTokenWithAssertion returned payload %+v along with the error", payload)
+			}
+		})
+	}
+
+	// A token bound to nothing does not validate against an assertion
+	unbound, err := maker.GenerateToken(uuid.New(), "alice", time.Hour)
+	if err != nil {
+		t.Fatalf("GenerateToken failed: %v", err)
+	}
+	if _, err := maker.ValidateTokenWithAssertion(unbound, "", "session-1"); !errors.Is(err, ErrAssertionMismatch) {
+		t.Errorf("ValidateTokenWithAssertion of an unbound token returned %v, want %v", err, ErrAssertionMismatch)
+	}
+}
+
+func TestPublicPasetoRoundTripAndTamper(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	privateDER, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		t.Fatalf("failed to marshal private key: %v", err)
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		t.Fatalf("failed to marshal public key: %v", err)
+	}
+
+	signer, err := NewTokenManager(TokenTypePasetoPublic, string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER})))
+	if err != nil {
+		t.Fatalf("NewTokenManager with the private key failed: %v", err)
+	}
+	verifier, err := NewTokenManager(TokenTypePasetoPublic, string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})))
+	if err != nil {
+		t.Fatalf("NewTokenManager with the public key failed: %v", err)
+	}
+
+	userID := uuid.New()
+	token, err := signer.GenerateToken(userID, "alice", time.Hour)
+	if err != nil {
+		t.Fatalf("GenerateToken failed: %v", err)
+	}
+	if !strings.HasPrefix(token, "v2.public.") {
+		t.Fatalf("token %q is not a v2.public token", token)
+	}
+
+	payload, err := verifier.ValidateToken(token)
+	if err != nil {
+		t.Fatalf("ValidateToken with the public key failed: %v", err)
+	}
+	if payload.UserID != userID || payload.Username != "alice" {
+		t.Errorf("payload identifies %s %q, want %s \"alice\"", payload.UserID, payload.Username, userID)
+	}
+	if _, err := verifier.GenerateToken(userID, "alice", time.Hour); !errors.Is(err, ErrSigningKeyMissing) {
+		t.Errorf("GenerateToken on a verifier returned %v, want %v", err, ErrSigningKeyMissing)
+	}
+
+	// The signed payload is readable, so change the username in it and keep the signature
+	encoded := strings.TrimPrefix(token, "v2.public.")
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		t.Fatalf("failed to decode token: %v", err)
+	}
+	message, signature := raw[:len(raw)-ed25519.SignatureSize], raw[len(raw)-ed25519.SignatureSize:]
+	forged := []byte(strings.Replace(string(message), `"username":"alice"`, `"username":"admin"`, 1))
+	if string(forged) == string(message) {
+		t.Fatalf("username not found in the signed payload %s", message)
+	}
+	tampered := "v2.public." + base64.RawURLEncoding.EncodeToString(append(forged, signature...))
+
+	otherPublicKey, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	otherVerifier, err := NewPublicPasetoMaker(nil, otherPublicKey)
+	if err != nil {
+		t.Fatalf("NewPublicPasetoMaker failed: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		verifier TokenManager
+		token    string
+	}{
+		{"tampered payload", verifier, tampered},
+		{"another public key", otherVerifier, token},
+		{"local token", verifier, mustGenerate(t, newTestPasetoMaker(t))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload, err := tt.verifier.ValidateToken(tt.token)
+			if !errors.Is(err, ErrInvalidToken) {
+				t.Fatalf("ValidateToken returned %v, want %v", err, ErrInvalidToken)
+			}
+			if payload != nil {
+				t.Errorf("ValidateToken returned payload %+v along with the error", payload)
+			}
+		})
+	}
+}
+
+func TestPasetoMakerFromPassphraseRoundTrip(t *testing.T) {
+	const passphrase = "correct horse battery staple, but longer"
+	salt := []byte("orders-service")
+
+	maker, err := NewPasetoMakerFromPassphrase(passphrase, WithPassphraseSalt(salt))
+	if err != nil {
+		t.Fatalf("NewPasetoMakerFromPassphrase failed: %v", err)
+	}
+	userID := uuid.New()
+	token := mustGenerateFor(t, maker, userID, time.Hour)
+
+	// The key derived from the same passphrase and salt, as on another instance, validates it
+	derived, err := NewPasetoMaker(string(DerivePasetoKey(passphrase, salt)))
+	if err != nil {
+		t.Fatalf("NewPasetoMaker failed: %v", err)
+	}
+	payload, err := derived.ValidateToken(token)
+	if err != nil {
+		t.Fatalf("ValidateToken with the derived key failed: %v", err)
+	}
+	if payload.UserID != userID {
+		t.Errorf("payload identifies %s, want %s", payload.UserID, userID)
+	}
+
+	otherSalt, err := NewPasetoMakerFromPassphrase(passphrase, WithPassphraseSalt([]byte("billing-service")))
+	if err != nil {
+		t.Fatalf("NewPasetoMakerFromPassphrase failed: %v", err)
+	}
+	defaultSalt, err := NewPasetoMakerFromPassphrase(passphrase)
+	if err != nil {
+		t.Fatalf("NewPasetoMakerFromPassphrase failed: %v", err)
+	}
+	otherPassphrase, err := NewPasetoMakerFromPassphrase(passphrase+"!", WithPassphraseSalt(salt))
+	if err != nil {
+		t.Fatalf("NewPasetoMakerFromPassphrase failed: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		maker TokenManager
+	}{
+		{"another salt", otherSalt},
+		{"default salt", defaultSalt},
+		{"another passphrase", otherPassphrase},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.maker.ValidateToken(token); !errors.Is(err, ErrInvalidToken) {
+				t.Errorf("ValidateToken returned %v, want %v", err, ErrInvalidToken)
+			}
+		})
+	}
+
+	if _, err := NewPasetoMakerFromPassphrase(""); err == nil {
+		t.Error("NewPasetoMakerFromPassphrase accepted an empty passphrase")
+	}
+}
+
+// mustGenerate returns a token for a new user from maker.
+func mustGenerate(t *testing.T, maker TokenManager) string {
+	t.Helper()
+	return mustGenerateFor(t, maker, uuid.New(), time.Hour)
+}
@@ -2,6 +2,7 @@ package gophertoken
 
 import (
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
@@ -9,10 +10,33 @@ import (
 
 // Errors related to token validation.
 var (
-	ErrInvalidToken = errors.New("token validation failed: signature invalid or claims malformed")
-	ErrExpiredToken = errors.New("token validation failed: token has expired")
+	ErrInvalidToken     = errors.New("token validation failed: signature invalid or claims malformed")
+	ErrExpiredToken     = errors.New("token validation failed: token has expired")
+	ErrTokenNotYetValid = errors.New("token validation failed: token is not valid yet")
 )
 
+// Errors returned when a genuine token was issued by or for another party.
+var (
+	ErrIssuerMismatch    = errors.New("token validation failed: unexpected issuer")
+	ErrAudienceMismatch  = errors.New("token validation failed: token not meant for this audience")
+	ErrFooterMismatch    = errors.New("token validation failed: unexpected footer")
+	ErrAssertionMismatch = errors.New("token validation failed: implicit assertion does not match")
+)
+
+// ErrReservedClaim is returned when a custom claim uses the name of a built-in or registered JWT claim.
+var ErrReservedClaim = errors.New("token generation failed: custom claim uses a reserved name")
+
+// reservedClaims are the claim names used by Payload itself and the registered JWT claims.
+// Custom claims may not use them, so they cannot overwrite the expiry or identity of a token.
+var reservedClaims = map[string]bool{
+	"id": true, "user_id": true, "username": true, "issued_at": true, "expired_at": true, "claims": true,
+	"issuer": true, "audience": true, "not_before": true,
+	"iss": true, "sub": true, "aud": true, "exp": true, "nbf": true, "iat": true, "jti": true,
+}
+
+// ErrSigningKeyMissing is returned by GenerateToken on a maker that only holds a public key.
+var ErrSigningKeyMissing = errors.New("token generation failed: no private key to sign with")
+
 // Payload contains the data embedded within a token.
 type Payload struct {
 	ID        uuid.UUID `json:"id"`
@@ -20,6 +44,13 @@ type Payload struct {
 	Username  string    `json:"username"`
 	IssuedAt  time.Time `json:"issued_at"`
 	ExpiredAt time.Time `json:"expired_at"`
+	NotBefore time.Time `json:"not_before,omitempty"`
+	Issuer    string    `json:"issuer,omitempty"`
+	Audience  string    `json:"audience,omitempty"`
+
+	// Claims holds application specific claims such as roles, scopes or a tenant ID.
+	// Values come back from ValidateToken as decoded from JSON, so numbers are float64.
+	Claims map[string]any `json:"claims,omitempty"`
 }
 
 // NewPayload creates a new token payload with a specific username and token duration.
@@ -47,6 +78,37 @@ func NewPayload(userID uuid.UUID, username string, duration time.Duration) (*Pay
 	return payload, nil
 }
 
+// NewPayloadWithClaims creates a new token payload like NewPayload, carrying the given custom claims.
+//
+// Example usage:
+//
+//	payload, err := NewPayloadWithClaims(userID, "username123", time.Hour, map[string]any{
+//	  "roles":     []string{"admin"},
+//	  "tenant_id": "acme",
+//	})
+//	if err != nil {
+//	  log.Fatal(err)
+//	}
+func NewPayloadWithClaims(userID uuid.UUID, username string, duration time.Duration, claims map[string]any) (*Payload, error) {
+	for name := range claims {
+		if reservedClaims[name] {
+			return nil, fmt.Errorf("%w: %q", ErrReservedClaim, name)
+		}
+	}
+
+	payload, err := NewPayload(userID, username, duration)
+	if err != nil {
+		return nil, err
+	}
+	if len(claims) > 0 {
+		payload.Claims = make(map[string]any, len(claims))
+		for name, value := range claims {
+			payload.Claims[name] = value
+		}
+	}
+	return payload, nil
+}
+
 // Valid checks if the payload's expiration date has passed and returns an error if it has.
 //
 // Example usage:
@@ -56,8 +118,25 @@ func NewPayload(userID uuid.UUID, username string, duration time.Duration) (*Pay
 //	  log.Fatal("Token expired")
 //	}
 func (payload *Payload) Valid() error {
-	if time.Now().After(payload.ExpiredAt) {
+	return payload.ValidWithSkew(0)
+}
+
+// ValidWithSkew checks the payload's expiration and not-before times like Valid, tolerating clocks
+// that are up to skew apart: the token is accepted from skew before NotBefore until skew after ExpiredAt.
+//
+// Example usage:
+//
+//	err := payload.ValidWithSkew(30 * time.Second)
+//	if errors.Is(err, ErrTokenNotYetValid) {
+//	  log.Println("Token used before its not-before time")
+//	}
+func (payload *Payload) ValidWithSkew(skew time.Duration) error {
+	now := time.Now()
+	if now.After(payload.ExpiredAt.Add(skew)) {
 		return ErrExpiredToken
 	}
+	if !payload.NotBefore.IsZero() && now.Before(payload.NotBefore.Add(-skew)) {
+		return ErrTokenNotYetValid
+	}
 	return nil
 }
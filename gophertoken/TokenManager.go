@@ -1,18 +1,30 @@
 package gophertoken
 
 import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
 	"time"
 
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 )
 
 const (
-	TokenTypeJWT    = "jwt"
-	TokenTypePaseto = "paseto"
+	TokenTypeJWT          = "jwt"
+	TokenTypeJWTRS256     = "jwt-rs256"
+	TokenTypeJWTES256     = "jwt-es256"
+	TokenTypePaseto       = "paseto"
+	TokenTypePasetoPublic = "paseto-public"
 )
 
 // TokenManager is the interface for creating and verifying tokens.
 //
+// ValidateToken returns the payload along with ErrExpiredToken for a genuine token that has only
+// expired, so that its claims can still be read, e.g. to identify the user during a refresh. Any
+// other error comes with a nil payload.
+//
 // Example usage:
 //
 //	var manager TokenManager
@@ -22,24 +34,90 @@ const (
 //	}
 type TokenManager interface {
 	GenerateToken(userID uuid.UUID, username string, duration time.Duration) (string, error)
+	GenerateTokenWithClaims(userID uuid.UUID, username string, duration time.Duration, claims map[string]any) (string, error)
 	ValidateToken(token string) (*Payload, error)
 }
 
+// PasetoTokenManager is a TokenManager for Paseto tokens that can also carry a footer and be bound to
+// an implicit assertion. NewPasetoMaker, NewPasetoMakerFromPassphrase and NewPublicPasetoMaker return
+// one, as do NewTokenManager for TokenTypePaseto and TokenTypePasetoPublic behind a type assertion.
+//
+// Example usage:
+//
+//	manager, err := NewPasetoMaker("your-32-byte-secret-key-here....")
+//	if err != nil {
+//	  log.Fatal(err)
+//	}
+//	token, err := manager.GenerateTokenWithFooter(userID, "username123", time.Hour, nil, `{"kid":"v2"}`)
+type PasetoTokenManager interface {
+	TokenManager
+	GenerateTokenWithFooter(userID uuid.UUID, username string, duration time.Duration, claims map[string]any, footer string) (string, error)
+	GenerateTokenWithAssertion(userID uuid.UUID, username string, duration time.Duration, claims map[string]any, footer, implicitAssertion string) (string, error)
+	ValidateTokenWithFooter(token, footer string) (*Payload, error)
+	ValidateTokenWithAssertion(token, footer, implicitAssertion string) (*Payload, error)
+}
+
 // NewTokenManager creates a new token manager (JWT or Paseto) depending on the provided type.
 //
+// For TokenTypeJWTRS256, TokenTypeJWTES256 and TokenTypePasetoPublic the key is PEM encoded. A private key gives a manager
+// that signs and validates, a public key one that only validates.
+//
 // Example usage:
 //
 //	manager, err := NewTokenManager("jwt", "your-secret-key")
 //	if err != nil {
 //	  log.Fatal(err)
 //	}
-func NewTokenManager(tokenType, secretKey string) (TokenManager, error) {
+//
+//	verifier, err := NewTokenManager(TokenTypeJWTRS256, string(publicKeyPEM))
+func NewTokenManager(tokenType, secretKey string, opts ...MakerOption) (TokenManager, error) {
 	switch tokenType {
 	case TokenTypeJWT:
-		return NewJWTMaker(secretKey)
+		return NewJWTMaker(secretKey, opts...)
+	case TokenTypeJWTRS256:
+		if privateKey, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(secretKey)); err == nil {
+			return NewRSAJWTMaker(privateKey, nil, opts...)
+		}
+		publicKey, err := jwt.ParseRSAPublicKeyFromPEM([]byte(secretKey))
+		if err != nil {
+			return nil, errors.New("invalid key: expected a PEM encoded RSA private or public key")
+		}
+		return NewRSAJWTMaker(nil, publicKey, opts...)
+	case TokenTypeJWTES256:
+		if privateKey, err := jwt.ParseECPrivateKeyFromPEM([]byte(secretKey)); err == nil {
+			return NewECDSAJWTMaker(privateKey, nil, opts...)
+		}
+		publicKey, err := jwt.ParseECPublicKeyFromPEM([]byte(secretKey))
+		if err != nil {
+			return nil, errors.New("invalid key: expected a PEM encoded ECDSA private or public key")
+		}
+		return NewECDSAJWTMaker(nil, publicKey, opts...)
 	case TokenTypePaseto:
-		return NewPasetoMaker(secretKey)
+		return NewPasetoMaker(secretKey, opts...)
+	case TokenTypePasetoPublic:
+		return newPublicPasetoMakerFromPEM(secretKey, opts)
 	default:
 		return nil, ErrInvalidToken
 	}
 }
+
+// newPublicPasetoMakerFromPEM creates a public PasetoMaker from a PEM encoded Ed25519 key, which may
+// be a PKCS #8 private key or a PKIX public key.
+func newPublicPasetoMakerFromPEM(keyPEM string, opts []MakerOption) (TokenManager, error) {
+	block, _ := pem.Decode([]byte(keyPEM))
+	if block == nil {
+		return nil, errors.New("invalid key: expected a PEM encoded Ed25519 private or public key")
+	}
+
+	if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		if privateKey, ok := key.(ed25519.PrivateKey); ok {
+			return NewPublicPasetoMaker(privateKey, nil, opts...)
+		}
+	}
+	if key, err := x509.ParsePKIXPublicKey(block.Bytes); err == nil {
+		if publicKey, ok := key.(ed25519.PublicKey); ok {
+			return NewPublicPasetoMaker(nil, publicKey, opts...)
+		}
+	}
+	return nil, errors.New("invalid key: expected a PEM encoded Ed25519 private or public key")
+}
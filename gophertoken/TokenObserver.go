@@ -0,0 +1,77 @@
+package gophertoken
+
+import "errors"
+
+// Observer is notified of every token a maker issues and every validation it performs, e.g. to feed
+// issuance and failure counters to Prometheus or to log a burst of forged tokens.
+//
+// The methods are called synchronously from GenerateToken and ValidateToken, so they should return
+// quickly and must be safe for concurrent use.
+//
+// Example usage:
+//
+//	type metricsObserver struct{}
+//
+//	func (metricsObserver) OnGenerate(payload *Payload)        { tokensIssued.Inc() }
+//	func (metricsObserver) OnValidateSuccess(payload *Payload) { tokensValidated.Inc() }
+//	func (metricsObserver) OnValidateFailure(err error) {
+//	    validationFailures.WithLabelValues(ValidationFailureReason(err)).Inc()
+//	}
+//
+//	maker, err := NewJWTMaker("your-secret-key", WithObserver(metricsObserver{}))
+type Observer interface {
+	// OnGenerate is called with the payload of each token that was issued.
+	OnGenerate(payload *Payload)
+	// OnValidateSuccess is called with the payload of each token that passed validation.
+	OnValidateSuccess(payload *Payload)
+	// OnValidateFailure is called with the error ValidateToken returns for a rejected token, such as
+	// ErrExpiredToken or ErrInvalidToken.
+	OnValidateFailure(err error)
+}
+
+// WithObserver reports token issuance and validation to observer. Without it nothing is reported.
+func WithObserver(observer Observer) MakerOption {
+	return func(c *makerConfig) {
+		if observer != nil {
+			c.observer = observer
+		}
+	}
+}
+
+// ValidationFailureReason classifies an error returned by ValidateToken into a short label suitable
+// for a metric, e.g. "expired" or "invalid".
+func ValidationFailureReason(err error) string {
+	switch {
+	case errors.Is(err, ErrExpiredToken):
+		return "expired"
+	case errors.Is(err, ErrTokenNotYetValid):
+		return "not_yet_valid"
+	case errors.Is(err, ErrIssuerMismatch):
+		return "issuer_mismatch"
+	case errors.Is(err, ErrAudienceMismatch):
+		return "audience_mismatch"
+	case errors.Is(err, ErrFooterMismatch):
+		return "footer_mismatch"
+	case errors.Is(err, ErrUnknownKeyID):
+		return "unknown_key_id"
+	default:
+		return "invalid"
+	}
+}
+
+// noopObserver is the Observer of makers created without WithObserver.
+type noopObserver struct{}
+
+func (noopObserver) OnGenerate(*Payload)        {}
+func (noopObserver) OnValidateSuccess(*Payload) {}
+func (noopObserver) OnValidateFailure(error)    {}
+
+// observeValidation reports the result of a validation to the observer and passes it through.
+func (c makerConfig) observeValidation(payload *Payload, err error) (*Payload, error) {
+	if err != nil {
+		c.observer.OnValidateFailure(err)
+	} else {
+		c.observer.OnValidateSuccess(payload)
+	}
+	return payload, err
+}
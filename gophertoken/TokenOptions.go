@@ -0,0 +1,113 @@
+package gophertoken
+
+import (
+	"errors"
+	"time"
+)
+
+// makerConfig holds the optional settings shared by the token makers.
+type makerConfig struct {
+	issuer    string
+	audience  string
+	notBefore time.Duration
+	clockSkew time.Duration
+	observer  Observer
+
+	passphraseSalt []byte
+}
+
+// MakerOption configures optional behaviour of the token makers.
+//
+// Example usage:
+//
+//	maker, err := NewJWTMaker("your-secret-key", WithIssuer("auth.example.com"), WithAudience("orders"))
+type MakerOption func(*makerConfig)
+
+// WithIssuer stamps generated tokens with the issuer and rejects tokens from any other issuer
+// with ErrIssuerMismatch.
+func WithIssuer(issuer string) MakerOption {
+	return func(c *makerConfig) {
+		c.issuer = issuer
+	}
+}
+
+// WithAudience stamps generated tokens with the audience and rejects tokens meant for any other
+// audience, or for none, with ErrAudienceMismatch. Services sharing a signing key should each set
+// their own audience so that a token minted for one is refused by the others.
+func WithAudience(audience string) MakerOption {
+	return func(c *makerConfig) {
+		c.audience = audience
+	}
+}
+
+// WithNotBefore makes generated tokens valid only from offset after they are issued, e.g. for a
+// token handed out ahead of a scheduled job. Validation rejects them earlier with ErrTokenNotYetValid.
+func WithNotBefore(offset time.Duration) MakerOption {
+	return func(c *makerConfig) {
+		c.notBefore = offset
+	}
+}
+
+// WithClockSkew tolerates clocks that differ by up to skew between the issuing and validating servers,
+// for both the expiry and the not-before check. A skew of around 30 seconds covers most deployments.
+func WithClockSkew(skew time.Duration) MakerOption {
+	return func(c *makerConfig) {
+		if skew > 0 {
+			c.clockSkew = skew
+		}
+	}
+}
+
+// WithPassphraseSalt sets the salt NewPasetoMakerFromPassphrase derives the key with, in place of the
+// fixed default. Every instance of a service must use the same salt to derive the same key. A salt of
+// your own keeps precomputed guesses made against the default salt from applying to your passphrase.
+// Other constructors ignore it.
+func WithPassphraseSalt(salt []byte) MakerOption {
+	return func(c *makerConfig) {
+		c.passphraseSalt = salt
+	}
+}
+
+// newMakerConfig applies the given options over the defaults.
+func newMakerConfig(opts []MakerOption) makerConfig {
+	config := makerConfig{observer: noopObserver{}}
+	for _, opt := range opts {
+		opt(&config)
+	}
+	return config
+}
+
+// stamp sets the configured issuer, audience and not-before time on a payload about to be turned into a token.
+func (c makerConfig) stamp(payload *Payload) {
+	payload.Issuer = c.issuer
+	payload.Audience = c.audience
+	if c.notBefore > 0 {
+		payload.NotBefore = payload.IssuedAt.Add(c.notBefore)
+	}
+}
+
+// verify checks the issuer and audience of a decoded payload against the configured ones, then its
+// validity period within the configured clock skew. ErrExpiredToken is thus only returned for a
+// token that was meant for this maker.
+func (c makerConfig) verify(payload *Payload) error {
+	if c.issuer != "" && payload.Issuer != c.issuer {
+		return ErrIssuerMismatch
+	}
+	if c.audience != "" && payload.Audience != c.audience {
+		return ErrAudienceMismatch
+	}
+	return payload.ValidWithSkew(c.clockSkew)
+}
+
+// verifiedPayload runs verify on a payload whose signature has been checked and returns the result
+// of ValidateToken: the payload alone when it is valid, the payload along with ErrExpiredToken when
+// it has only expired, and nil with the error otherwise.
+func (c makerConfig) verifiedPayload(payload *Payload) (*Payload, error) {
+	if err := c.verify(payload); err != nil {
+		if errors.Is(err, ErrExpiredToken) {
+			return payload, err
+		}
+		return nil, err
+	}
+	return payload, nil
+}